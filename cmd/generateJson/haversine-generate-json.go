@@ -10,6 +10,8 @@ import (
 	"math"
 	"math/rand/v2"
 	"os"
+
+	"tmelot.jsonparser/internal/jsonParser"
 )
 
 
@@ -17,6 +19,11 @@ func createJsonItem(x0, y0, x1, y1 float64) string {
 	return fmt.Sprintf("{ \"x0\":%.16f, \"y0\":%.16f, \"x1\":%.16f, \"y1\":%.16f }", x0, y0, x1, y1)
 }
 
+// NDJSON items are written by jsonParser's NdjsonWriter, which keeps every float's full precision.
+func createNdjsonItem(x0, y0, x1, y1 float64) *jsonParser.JsonValue {
+	return jsonParser.NewJsonValue(map[string]any{"x0": x0, "y0": y0, "x1": x1, "y1": y1})
+}
+
 func radiansFromDegrees(degrees float64) float64 {
 	result := 0.01745329251994329577 * degrees
 	return result
@@ -86,6 +93,7 @@ func main() {
 	pairsArg  := flag.Int("pairs", 10000, "Number of pairs of points to generate")
 	methodArg := flag.String("method", "uniform", "Point distribution method: uniform or cluster")
 	fileName  := flag.String("fileName", "../../pairs.json", "Path to pairs JSON output file")
+	formatArg := flag.String("format", "json", "Output format: json (1 object holding a pairs array) or ndjson (1 pair per line)")
 	flag.Parse()
 	pairs := *pairsArg
 
@@ -103,6 +111,13 @@ func main() {
 		*methodArg = "uniform"
 	}
 
+	// Validate format
+	ndjson := *formatArg == "ndjson"
+	if !ndjson && *formatArg != "json" {
+		fmt.Println("Error: Unknown format", *formatArg)
+		return
+	}
+
 	// Open file
 	file, err := os.Create(*fileName)
 	if err != nil {
@@ -111,7 +126,10 @@ func main() {
 	}
 	defer file.Close()
 
-	fmt.Fprintf(file, "{\"pairs\":[\n")
+	ndjsonWriter := jsonParser.NewNdjsonWriter(file)
+	if !ndjson {
+		fmt.Fprintf(file, "{\"pairs\":[\n")
+	}
 
 	percent := rand.Float64()
 	for i := 0; i < pairs; i++ {
@@ -124,10 +142,20 @@ func main() {
 
 		x0,y0 := getRandomPoint(centerX, centerY, percent)
 		x1,y1 := getRandomPoint(centerX, centerY, percent)
-		newItem := createJsonItem(x0, y0, x1, y1)
 		haversineDistance := referenceHaversine(x0, y0, x1, y1, EARTH_RADIUS)
 		haversineSum += haversineDistance
 		caseyHaversineSum += (1.0/float64(pairs)) * haversineDistance
+
+		if ndjson {
+			err = ndjsonWriter.Write(createNdjsonItem(x0, y0, x1, y1))
+			if err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			continue
+		}
+
+		newItem := createJsonItem(x0, y0, x1, y1)
 		// Comma
 		if i < pairs-1 {
 			newItem = fmt.Sprintf("%s,", newItem)
@@ -136,7 +164,14 @@ func main() {
 	}
 
 	avg := haversineSum/float64(pairs)
-	fmt.Fprintf(file, "]}")
+	if ndjson {
+		if err := ndjsonWriter.Flush(); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	} else {
+		fmt.Fprintf(file, "]}")
+	}
 	fmt.Printf("Count: %d\n  Sum: %.16f\n  Avg: %.16f\n CSum: %.16f\n", pairs, haversineSum, avg, caseyHaversineSum)
 	fmt.Printf(" Diff: %.16f\n", math.Abs(avg-caseyHaversineSum))
 }
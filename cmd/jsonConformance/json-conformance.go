@@ -0,0 +1,186 @@
+/*
+	Runs the parser against a JSONTestSuite style corpus (see testdata/) & reports the result
+	for each file. File names say what a parser must do with them:
+	- y_ files are valid JSON & must be accepted.
+	- n_ files are invalid JSON & must be rejected.
+	- i_ files are up to the parser, so they're reported but can't fail.
+
+	Parser panics are caught & reported as crashes. With -diff, results are also compared with
+	Go's encoding/json, both whether the file is accepted & the value it parses to. Exits with
+	status 1 if any file fails or crashes.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tmelot.jsonparser/internal/jsonParser"
+)
+
+type Result string
+
+const (
+	RESULT_PASS     Result = "PASS"
+	RESULT_FAIL     Result = "FAIL"
+	RESULT_CRASH    Result = "CRASH"
+	RESULT_ACCEPTED Result = "ACCEPTED" // i_ files only
+	RESULT_REJECTED Result = "REJECTED" // i_ files only
+)
+
+// Max length of a parse error or value printed in a report line.
+const MAX_DETAIL_LEN = 100
+
+func main() {
+	dirArg := flag.String("dir", "testdata", "Directory of y_, n_ & i_ JSON files")
+	diffArg := flag.Bool("diff", false, "Also compare each result with encoding/json")
+	failuresArg := flag.Bool("failures", false, "Only print files that fail, crash or differ")
+	flag.Parse()
+
+	entries, err := os.ReadDir(*dirArg)
+	if err != nil {
+		fmt.Println("Error reading corpus:", err)
+		os.Exit(2)
+	}
+
+	counts := map[Result]int{}
+	numDiffs := 0
+	numFiles := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(*dirArg, name))
+		if err != nil {
+			fmt.Println("Error reading file:", err)
+			os.Exit(2)
+		}
+		numFiles++
+
+		value, parseErr := parseCatchingPanics(data)
+		result := checkResult(name, parseErr)
+		counts[result]++
+
+		diff := ""
+		if *diffArg && result != RESULT_CRASH {
+			diff = diffWithEncodingJson(data, value, parseErr)
+			if diff != "" {
+				numDiffs++
+			}
+		}
+
+		if *failuresArg && result != RESULT_FAIL && result != RESULT_CRASH && diff == "" {
+			continue
+		}
+		line := fmt.Sprintf("%-8s %s", result, name)
+		if parseErr != nil {
+			line += ": " + truncate(parseErr.Error())
+		}
+		fmt.Println(line)
+		if diff != "" {
+			fmt.Printf("%-8s %s: %s\n", "DIFF", name, diff)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%d files: %d passed, %d failed, %d crashed, %d accepted (i_), %d rejected (i_)\n",
+		numFiles, counts[RESULT_PASS], counts[RESULT_FAIL], counts[RESULT_CRASH],
+		counts[RESULT_ACCEPTED], counts[RESULT_REJECTED])
+	if *diffArg {
+		fmt.Printf("%d files differ from encoding/json\n", numDiffs)
+	}
+
+	if counts[RESULT_FAIL] > 0 || counts[RESULT_CRASH] > 0 {
+		os.Exit(1)
+	}
+}
+
+// Panicking parsers are reported with this error, so they can be told apart from rejections.
+var errPanic = errors.New("Parser panicked")
+
+func parseCatchingPanics(data []byte) (value *jsonParser.JsonValue, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			value = nil
+			err = fmt.Errorf("%w: %v", errPanic, r)
+		}
+	}()
+	return jsonParser.ParseJson(string(data))
+}
+
+// Returns the result for the file with the given name, which the parser returned parseErr for.
+func checkResult(name string, parseErr error) Result {
+	if errors.Is(parseErr, errPanic) {
+		return RESULT_CRASH
+	}
+
+	accepted := parseErr == nil
+	switch {
+	case strings.HasPrefix(name, "y_"):
+		if accepted {
+			return RESULT_PASS
+		}
+		return RESULT_FAIL
+	case strings.HasPrefix(name, "n_"):
+		if !accepted {
+			return RESULT_PASS
+		}
+		return RESULT_FAIL
+	}
+	if accepted {
+		return RESULT_ACCEPTED
+	}
+	return RESULT_REJECTED
+}
+
+// Returns how the parser's result differs from encoding/json's for data, or "" if they match.
+func diffWithEncodingJson(data []byte, value *jsonParser.JsonValue, parseErr error) string {
+	goAccepted := json.Valid(data)
+	if goAccepted != (parseErr == nil) {
+		if goAccepted {
+			return "encoding/json accepts it"
+		}
+		return "encoding/json rejects it"
+	}
+	if !goAccepted {
+		return ""
+	}
+
+	// Decode with encoding/json, then write that back out & parse it with this parser, so the 2
+	// values can be compared with jsonParser.Equal()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var goValue any
+	if err := decoder.Decode(&goValue); err != nil {
+		return "encoding/json can't decode it: " + truncate(err.Error())
+	}
+	goJson, err := json.Marshal(goValue)
+	if err != nil {
+		return "encoding/json can't encode it: " + truncate(err.Error())
+	}
+	goJsonValue, err := jsonParser.ParseJsonWithOptions(string(goJson), jsonParser.ParseOptions{UseNumber: true})
+	if err != nil {
+		return "Can't parse encoding/json's output: " + truncate(err.Error())
+	}
+
+	if !jsonParser.Equal(value, goJsonValue) {
+		valueJson, _ := value.Serialize()
+		return fmt.Sprintf("Parsed to %s, encoding/json parsed to %s", truncate(string(valueJson)), truncate(string(goJson)))
+	}
+	return ""
+}
+
+func truncate(s string) string {
+	if len(s) > MAX_DETAIL_LEN {
+		return s[:MAX_DETAIL_LEN] + "..."
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+/*
+	Compares 2 JSON files & prints every added, removed or changed path, like a diff. Exits
+	with status 1 if the files differ, like diff.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"tmelot.jsonparser/internal/jsonParser"
+)
+
+func main() {
+	toleranceArg := flag.Float64("tolerance", 0, "Max difference between 2 numbers for them to still count as equal")
+	patchArg := flag.Bool("patch", false, "Print a JSON Patch (RFC 6902) that turns the old file into the new 1, instead of a diff")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] oldFile newFile\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	from, err := jsonParser.ParseFile(flag.Arg(0))
+	if err != nil {
+		fmt.Printf("Error parsing %s: %s\n", flag.Arg(0), err)
+		os.Exit(2)
+	}
+	to, err := jsonParser.ParseFile(flag.Arg(1))
+	if err != nil {
+		fmt.Printf("Error parsing %s: %s\n", flag.Arg(1), err)
+		os.Exit(2)
+	}
+
+	changes := jsonParser.Diff(from, to, jsonParser.DiffOptions{FloatTolerance: *toleranceArg})
+	if *patchArg {
+		patchJson, err := jsonParser.DiffToPatch(changes).Serialize()
+		if err != nil {
+			fmt.Println("Error writing patch:", err)
+			os.Exit(2)
+		}
+		fmt.Println(string(patchJson))
+	} else {
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+	}
+
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
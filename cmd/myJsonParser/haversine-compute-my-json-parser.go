@@ -7,8 +7,10 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	// "strings"
+	"runtime"
+	"strings"
 	// "strconv"
 	// "time"
 	// "unsafe"
@@ -64,15 +66,56 @@ func readEntireFile(fileName string) ([]byte, error) {
 
 var EARTH_RADIUS = 6372.8
 
-func haversineSum(json *jsonParser.JsonValue) {
+// Schema for 1 item in the pairs array.
+var PAIR_SCHEMA = `{
+	"type": "object",
+	"required": ["x0", "y0", "x1", "y1"],
+	"additionalProperties": false,
+	"properties": {
+		"x0": {"$ref": "#/$defs/longitude"},
+		"y0": {"$ref": "#/$defs/latitude"},
+		"x1": {"$ref": "#/$defs/longitude"},
+		"y1": {"$ref": "#/$defs/latitude"}
+	},
+	"$defs": {
+		"longitude": {"type": "number", "minimum": -180, "maximum": 180},
+		"latitude": {"type": "number", "minimum": -90, "maximum": 90}
+	}
+}`
+
+// Checks every pair against PAIR_SCHEMA, printing all violations. Returns an error if there were
+// any.
+func validatePairs(pairs []*jsonParser.JsonValue) error {
+	schemaJson, err := jsonParser.ParseJson(PAIR_SCHEMA)
+	if err != nil {
+		return err
+	}
+	schema, err := jsonParser.CompileSchema(schemaJson)
+	if err != nil {
+		return err
+	}
+
+	profiler.GlobalProfiler.StartBlock("Validate")
+	errorCount := 0
+	for i, pair := range pairs {
+		for _, schemaErr := range schema.Validate(pair) {
+			fmt.Printf("Pair %d: %s\n", i, schemaErr)
+			errorCount += 1
+		}
+	}
+	profiler.GlobalProfiler.EndBlock("Validate")
+
+	if errorCount > 0 {
+		return fmt.Errorf("%d schema violations", errorCount)
+	}
+	return nil
+}
+
+func haversineSum(pairs []*jsonParser.JsonValue) {
 	p := GetPrinter()
 
 	fmt.Println("===============================")
 	haversineSum := 0.0
-	// Profile to get time for GetArray() call.
-	profiler.GlobalProfiler.StartBlock("SumHaversine")
-	pairs, _ := json.GetArray("pairs")
-	profiler.GlobalProfiler.EndBlock("SumHaversine")
 
 	// Profile rest of haversine sum. 32 is bytes per haversine set. 4 points,
 	// each a float64, so 8 bytes. 8*4 = 32.
@@ -92,6 +135,89 @@ func haversineSum(json *jsonParser.JsonValue) {
 	profiler.GlobalProfiler.EndBlock("MiscOutput")
 }
 
+// Same as haversineSum(), but with the pairs' coordinates in columns (see
+// jsonParser.ExtractColumns()), so there are no lookups or type assertions in the loop.
+func haversineSumColumns(x0 []float64, y0 []float64, x1 []float64, y1 []float64) {
+	p := GetPrinter()
+
+	fmt.Println("===============================")
+	haversineSum := 0.0
+
+	profiler.GlobalProfiler.StartBandwidth("SumHaversine", uint64(len(x0)*32))
+	for i := range x0 {
+		haversineSum += haversine.ReferenceHaversine(x0[i], y0[i], x1[i], y1[i], EARTH_RADIUS)
+	}
+	avg := haversineSum / float64(len(x0))
+	profiler.GlobalProfiler.EndBandwidth("SumHaversine")
+
+	profiler.GlobalProfiler.StartBlock("MiscOutput")
+	p.Printf("Count: %*d\nHaversine sum: %.16f\nHaversine avg: %.16f\n", 14, len(x0), haversineSum, avg)
+	profiler.GlobalProfiler.EndBlock("MiscOutput")
+}
+
+// Decodes the pairs' coordinates straight into columns, without parsing the rest of the JSON.
+func parsePairsColumns(strData string) ([][]float64, error) {
+	return jsonParser.ExtractColumns(strData, "pairs", []string{"x0", "y0", "x1", "y1"})
+}
+
+// Parse options for pairs files, with their keys seeded so every pair shares them.
+func pairsParseOptions() jsonParser.ParseOptions {
+	options := jsonParser.DefaultParseOptions()
+	options.ExpectedKeys = []string{"pairs", "x0", "y0", "x1", "y1"}
+	return options
+}
+
+// Parses a single JSON object & returns its "pairs" array. Uses parallel parsing for more than 1
+// thread.
+func parsePairsJson(strData string, threads int) ([]*jsonParser.JsonValue, error) {
+	var jsonResult *jsonParser.JsonValue
+	var err error
+	if threads > 1 {
+		jsonResult, err = jsonParser.ParseJsonParallel(strData, pairsParseOptions(), threads)
+	} else {
+		jsonResult, err = jsonParser.ParseJsonWithOptions(strData, pairsParseOptions())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Profile to get time for GetArray() call.
+	profiler.GlobalProfiler.StartBlock("GetPairs")
+	pairs, err := jsonResult.GetArray("pairs")
+	profiler.GlobalProfiler.EndBlock("GetPairs")
+	return pairs, err
+}
+
+// Parses a single JSON object file in place with a memory mapped file & returns its "pairs"
+// array.
+func parsePairsFile(fileName string) ([]*jsonParser.JsonValue, error) {
+	jsonResult, err := jsonParser.ParseFileWithOptions(fileName, pairsParseOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	profiler.GlobalProfiler.StartBlock("GetPairs")
+	pairs, err := jsonResult.GetArray("pairs")
+	profiler.GlobalProfiler.EndBlock("GetPairs")
+	return pairs, err
+}
+
+// Parses NDJSON with 1 pair per line.
+func parsePairsNdjson(strData string) ([]*jsonParser.JsonValue, error) {
+	var pairs []*jsonParser.JsonValue
+	reader := jsonParser.NewNdjsonReader(strings.NewReader(strData), pairsParseOptions())
+	for {
+		pair, err := reader.Next()
+		if err == io.EOF {
+			return pairs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+}
+
 // Main
 //
 func main() {
@@ -100,9 +226,42 @@ func main() {
 	// Get input args
 	profiler.GlobalProfiler.StartBlock("Startup")
 	fileNameArg := flag.String("fileName", "../../pairs.json", "Path to pairs JSON file")
+	formatArg := flag.String("format", "json", "Input format: json (1 object holding a pairs array) or ndjson (1 pair per line)")
+	threadsArg := flag.Int("threads", 1, "Number of goroutines to parse json format with. Use 0 for 1 per CPU")
+	validateArg := flag.Bool("validate", false, "Check every pair against the pairs schema before computing")
+	mmapArg := flag.Bool("mmap", true, "Parse json format in place with a memory mapped file, rather than reading it into memory first. Single thread only")
+	columnsArg := flag.Bool("columns", false, "Decode json format pairs straight into coordinate columns, rather than parsing into JsonValues. Can't be used with -validate")
 	flag.Parse()
 	profiler.GlobalProfiler.EndBlock("Startup")
 
+	if *columnsArg && (*formatArg != "json" || *validateArg) {
+		fmt.Println("Error: -columns only works with json format, without -validate")
+		return
+	}
+
+	threads := *threadsArg
+	if threads <= 0 {
+		threads = runtime.NumCPU()
+	}
+
+	// Map & parse JSON file, skipping the read & copy below
+	if *formatArg == "json" && threads == 1 && *mmapArg && !*columnsArg {
+		pairs, err := parsePairsFile(*fileNameArg)
+		if err != nil {
+			fmt.Println("Error parsing JSON:", err)
+			return
+		}
+		if *validateArg {
+			if err := validatePairs(pairs); err != nil {
+				fmt.Println("Error validating JSON:", err)
+				return
+			}
+		}
+		haversineSum(pairs)
+		profiler.GlobalProfiler.EndAndPrintProfile()
+		return
+	}
+
 	// Read JSON file
 	data, err := readEntireFile(*fileNameArg)
 	if err != nil {
@@ -116,15 +275,41 @@ func main() {
 	profiler.GlobalProfiler.EndBlock("ReadToStr")
 	DebugPrintln(strData)
 
+	// Decode coordinates into columns
+	if *columnsArg {
+		columns, err := parsePairsColumns(strData)
+		if err != nil {
+			fmt.Println("Error parsing JSON:", err)
+			return
+		}
+		haversineSumColumns(columns[0], columns[1], columns[2], columns[3])
+		profiler.GlobalProfiler.EndAndPrintProfile()
+		return
+	}
+
 	// Parse
-	jsonResult, err := jsonParser.ParseJson(strData)
+	var pairs []*jsonParser.JsonValue
+	switch *formatArg {
+	case "json":
+		pairs, err = parsePairsJson(strData, threads)
+	case "ndjson":
+		pairs, err = parsePairsNdjson(strData)
+	default:
+		err = fmt.Errorf("Unknown format %s", *formatArg)
+	}
 	if err != nil {
 		fmt.Println("Error parsing JSON:", err)
 		return
 	}
+	if *validateArg {
+		if err := validatePairs(pairs); err != nil {
+			fmt.Println("Error validating JSON:", err)
+			return
+		}
+	}
 
 	// Compute Haversine & print results
-	haversineSum(jsonResult)
+	haversineSum(pairs)
 
 	profiler.GlobalProfiler.EndAndPrintProfile()
 }
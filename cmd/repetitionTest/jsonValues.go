@@ -0,0 +1,181 @@
+// Tests parsing into JsonValues & reading pairs back out of them, against encoding/json decoding
+// into any. Also prints the heap allocations each takes for 1 run.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"tmelot.jsonparser/internal/jsonParser"
+	"tmelot.jsonparser/internal/profiler"
+	"tmelot.jsonparser/internal/repetitionTester"
+)
+
+type JsonValuesParams struct {
+	data string
+}
+
+func parseViaMyParseJson(rt *repetitionTester.RepetitionTester, params *JsonValuesParams) {
+	for rt.IsTesting() {
+		rt.BeginTime()
+		_, err := jsonParser.ParseJson(params.data)
+		rt.EndTime()
+		if err != nil {
+			HandleError(err)
+		}
+		rt.CountBytes(uint64(len(params.data)))
+	}
+}
+
+func sumViaMyParseJson(rt *repetitionTester.RepetitionTester, params *JsonValuesParams) {
+	for rt.IsTesting() {
+		rt.BeginTime()
+		sum, err := sumPairsMyParseJson(params.data)
+		rt.EndTime()
+		if err != nil {
+			HandleError(err)
+		}
+		if sum == 0 {
+			HandleError(fmt.Errorf("Pairs summed to 0"))
+		}
+		rt.CountBytes(uint64(len(params.data)))
+	}
+}
+
+func sumViaEncodingJson(rt *repetitionTester.RepetitionTester, params *JsonValuesParams) {
+	for rt.IsTesting() {
+		rt.BeginTime()
+		sum, err := sumPairsEncodingJson(params.data)
+		rt.EndTime()
+		if err != nil {
+			HandleError(err)
+		}
+		if sum == 0 {
+			HandleError(fmt.Errorf("Pairs summed to 0"))
+		}
+		rt.CountBytes(uint64(len(params.data)))
+	}
+}
+
+// Parses the pairs file & sums every coordinate, reading them back through the JsonValue API.
+func sumPairsMyParseJson(data string) (float64, error) {
+	jsonResult, err := jsonParser.ParseJson(data)
+	if err != nil {
+		return 0, err
+	}
+	pairs, err := jsonResult.GetArray("pairs")
+	if err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+	for _, pair := range pairs {
+		for _, key := range []string{"x0", "y0", "x1", "y1"} {
+			coordinate, err := pair.GetFloat(key)
+			if err != nil {
+				return 0, err
+			}
+			sum += coordinate
+		}
+	}
+	return sum, nil
+}
+
+func sumPairsEncodingJson(data string) (float64, error) {
+	var jsonResult map[string]any
+	if err := json.Unmarshal([]byte(data), &jsonResult); err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+	for _, pair := range jsonResult["pairs"].([]any) {
+		pairMap := pair.(map[string]any)
+		for _, key := range []string{"x0", "y0", "x1", "y1"} {
+			sum += pairMap[key].(float64)
+		}
+	}
+	return sum, nil
+}
+
+type JsonValuesTestFunc func(*repetitionTester.RepetitionTester, *JsonValuesParams)
+type TestFunction struct {
+	name string
+	fun  JsonValuesTestFunc
+	once func(data string) error // 1 run, to count its allocations
+}
+
+func HandleError(err error) {
+	msg := fmt.Sprintln("Error:", err)
+	panic(msg)
+}
+
+// Returns the number of heap allocations & bytes allocated by 1 call of fn.
+func countAllocations(fn func() error) (uint64, uint64) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	err := fn()
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		HandleError(err)
+	}
+	return after.Mallocs - before.Mallocs, after.TotalAlloc - before.TotalAlloc
+}
+
+func main() {
+	// Input args
+	fileNameArg := flag.String("fileName", "../../pairs.json", "Path to pairs JSON file")
+	flag.Parse()
+
+	data, err := os.ReadFile(*fileNameArg)
+	if err != nil {
+		HandleError(err)
+	}
+	byteCount := uint64(len(data))
+	params := JsonValuesParams{data: string(data)}
+
+	// Table of test functions to test.
+	testFunctions := [3]TestFunction{
+		{
+			name: "jsonParser.ParseJson",
+			fun:  parseViaMyParseJson,
+			once: func(data string) error { _, err := jsonParser.ParseJson(data); return err },
+		},
+		{
+			name: "jsonParser.ParseJson + GetArray & GetFloat",
+			fun:  sumViaMyParseJson,
+			once: func(data string) error { _, err := sumPairsMyParseJson(data); return err },
+		},
+		{
+			name: "encoding/json.Unmarshal into any",
+			fun:  sumViaEncodingJson,
+			once: func(data string) error { _, err := sumPairsEncodingJson(data); return err },
+		},
+	}
+
+	// Create multiple testers, one for each test function.
+	var testers [len(testFunctions)]*repetitionTester.RepetitionTester
+	for i := range testers {
+		testers[i] = repetitionTester.NewRepetitionTester()
+	}
+
+	cpuFreq := profiler.EstimateCPUTimerFreq(false)
+
+	// Run tests!
+	for i, testFunc := range testFunctions {
+		fmt.Println("---", testFunc.name, "---")
+		allocs, allocBytes := countAllocations(func() error { return testFunc.once(params.data) })
+		fmt.Printf("Allocations: %d (%.3fmb)\n", allocs, float64(allocBytes)/(1024*1024))
+		secondsToTry := uint32(2)
+		testers[i].NewTestWave(byteCount, cpuFreq, secondsToTry)
+
+		testFunc.fun(testers[i], &params)
+		fmt.Println("")
+	}
+
+	fmt.Println("\nDone!")
+}
@@ -0,0 +1,105 @@
+// Tests JSON validation speed: my validator vs encoding/json, with a full parse for comparison.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"tmelot.jsonparser/internal/jsonParser"
+	"tmelot.jsonparser/internal/profiler"
+	"tmelot.jsonparser/internal/repetitionTester"
+)
+
+type ValidParams struct {
+	data []byte
+}
+
+func validViaMyValid(rt *repetitionTester.RepetitionTester, params *ValidParams) {
+	for rt.IsTesting() {
+		rt.BeginTime()
+		valid := jsonParser.Valid(params.data)
+		rt.EndTime()
+		if !valid {
+			HandleError(jsonParser.Validate(params.data))
+		}
+		rt.CountBytes(uint64(len(params.data)))
+	}
+}
+
+func validViaEncodingJson(rt *repetitionTester.RepetitionTester, params *ValidParams) {
+	for rt.IsTesting() {
+		rt.BeginTime()
+		valid := json.Valid(params.data)
+		rt.EndTime()
+		if !valid {
+			HandleError(fmt.Errorf("encoding/json says the file isn't valid"))
+		}
+		rt.CountBytes(uint64(len(params.data)))
+	}
+}
+
+func validViaMyParseJson(rt *repetitionTester.RepetitionTester, params *ValidParams) {
+	for rt.IsTesting() {
+		rt.BeginTime()
+		_, err := jsonParser.ParseJson(string(params.data))
+		rt.EndTime()
+		if err != nil {
+			HandleError(err)
+		}
+		rt.CountBytes(uint64(len(params.data)))
+	}
+}
+
+type ValidTestFunc func(*repetitionTester.RepetitionTester, *ValidParams)
+type TestFunction struct {
+	name string
+	fun  ValidTestFunc
+}
+
+func HandleError(err error) {
+	msg := fmt.Sprintln("Error:", err)
+	panic(msg)
+}
+
+func main() {
+	// Input args
+	fileNameArg := flag.String("fileName", "../../pairs.json", "Path to pairs JSON file")
+	flag.Parse()
+
+	data, err := os.ReadFile(*fileNameArg)
+	if err != nil {
+		HandleError(err)
+	}
+	byteCount := uint64(len(data))
+	params := ValidParams{data: data}
+
+	// Table of test functions to test.
+	testFunctions := [3]TestFunction{
+		{name: "jsonParser.Valid", fun: validViaMyValid},
+		{name: "encoding/json.Valid", fun: validViaEncodingJson},
+		{name: "jsonParser.ParseJson", fun: validViaMyParseJson},
+	}
+
+	// Create multiple testers, one for each test function.
+	var testers [len(testFunctions)]*repetitionTester.RepetitionTester
+	for i := range testers {
+		testers[i] = repetitionTester.NewRepetitionTester()
+	}
+
+	cpuFreq := profiler.EstimateCPUTimerFreq(false)
+
+	// Run tests!
+	for i, testFunc := range testFunctions {
+		fmt.Println("---", testFunc.name, "---")
+		secondsToTry := uint32(2)
+		testers[i].NewTestWave(byteCount, cpuFreq, secondsToTry)
+
+		testFunc.fun(testers[i], &params)
+		fmt.Println("")
+	}
+
+	fmt.Println("\nDone!")
+}
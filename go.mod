@@ -3,6 +3,6 @@ module tmelot.jsonparser
 go 1.22.1
 
 require (
-	golang.org/x/sys v0.20.0 // indirect
-	golang.org/x/text v0.15.0 // indirect
+	golang.org/x/sys v0.20.0
+	golang.org/x/text v0.15.0
 )
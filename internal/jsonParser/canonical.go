@@ -0,0 +1,263 @@
+package jsonParser
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+	Canonical JSON (RFC 8785, JSON Canonicalization Scheme or JCS) writes a value the same way
+	every time, byte for byte, so it can be hashed or signed. Documents with the same data give
+	the same output no matter how they were formatted.
+
+	```
+	canonical, err := jsonResult.Canonicalize()
+	digest, err := jsonResult.CanonicalSha256()
+	```
+
+	- No whitespace.
+	- Object keys are sorted by their UTF-16 code units, which differs from byte order for some
+	  characters outside the Basic Multilingual Plane.
+	- Numbers are written like ECMAScript's Number.toString(): all numbers are float64, whole
+	  numbers have no fraction (so 1.0 is "1"), & exponents are used below 1e-6 & from 1e21 up.
+	  Ints & Numbers past 2^53 lose precision, as they would in JavaScript.
+	- Strings only escape quotes, backslashes & control characters, the same as Serialize().
+	- Invalid UTF-8, NaN & Infinity are errors, since the output must be valid I-JSON.
+*/
+
+// Returns the value as canonical JSON.
+func (j *JsonValue) Canonicalize() ([]byte, error) {
+	return j.AppendCanonicalJson(nil)
+}
+
+// Appends the value as canonical JSON to dst & returns the extended buffer.
+func (j *JsonValue) AppendCanonicalJson(dst []byte) ([]byte, error) {
+	return appendCanonicalTapeValue(dst, j.tape, j.index)
+}
+
+// Returns the SHA-256 digest of the value's canonical JSON.
+func (j *JsonValue) CanonicalSha256() ([sha256.Size]byte, error) {
+	canonical, err := j.Canonicalize()
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(canonical), nil
+}
+
+// Appends the value at index on the tape, the same way appendCanonicalValue() does for Go data.
+func appendCanonicalTapeValue(dst []byte, t *tape, index int) ([]byte, error) {
+	var err error
+
+	n := t.nodes[index]
+	switch n.kind {
+	case tapeNull, tapeFalse, tapeTrue:
+		return appendTapeValue(dst, t, index)
+	case tapeInt, tapeFloat:
+		floatVal, _ := t.numberValue(n)
+		dst, err = appendCanonicalNumber(dst, floatVal)
+	case tapeNumber:
+		return appendCanonicalValue(dst, Number(t.str(n)))
+	case tapeString:
+		dst, err = appendCanonicalString(dst, t.str(n))
+	case tapeArray:
+		dst = append(dst, '[')
+		for item := index + 1; item < t.next(index); item = t.next(item) {
+			if item > index+1 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendCanonicalTapeValue(dst, t, item); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, ']')
+	case tapeObject:
+		var keysBuf [SERIALIZER_STACK_KEYS]int
+		keys := t.sortedKeys(index, keysBuf[:0], compareUtf16)
+
+		dst = append(dst, '{')
+		for i, key := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendCanonicalString(dst, t.str(t.nodes[key])); err != nil {
+				return dst, err
+			}
+			dst = append(dst, ':')
+			if dst, err = appendCanonicalTapeValue(dst, t, key+1); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, '}')
+	case tapeInvalid:
+		return appendCanonicalValue(dst, t.invalid[n.payload])
+	}
+
+	return dst, err
+}
+
+func appendCanonicalValue(dst []byte, val any) ([]byte, error) {
+	var err error
+
+	switch typedVal := val.(type) {
+	case nil, bool:
+		return appendJsonValue(dst, typedVal)
+	case int:
+		dst, err = appendCanonicalNumber(dst, float64(typedVal))
+	case float64:
+		dst, err = appendCanonicalNumber(dst, typedVal)
+	case Number:
+		floatVal, floatErr := typedVal.Float64()
+		if floatErr != nil {
+			msg := fmt.Sprintf(`Cannot canonicalize number "%s": %s`, typedVal, floatErr)
+			return dst, errors.New(msg)
+		}
+		dst, err = appendCanonicalNumber(dst, floatVal)
+	case string:
+		dst, err = appendCanonicalString(dst, typedVal)
+	case []any:
+		dst = append(dst, '[')
+		for i, item := range typedVal {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendCanonicalValue(dst, item); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, ']')
+	case map[string]any:
+		keys := make([]string, 0, len(typedVal))
+		for key := range typedVal {
+			keys = append(keys, key)
+		}
+		slices.SortFunc(keys, compareUtf16)
+
+		dst = append(dst, '{')
+		for i, key := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendCanonicalString(dst, key); err != nil {
+				return dst, err
+			}
+			dst = append(dst, ':')
+			if dst, err = appendCanonicalValue(dst, typedVal[key]); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, '}')
+	default:
+		msg := fmt.Sprintf("Cannot canonicalize value of type %T", val)
+		err = errors.New(msg)
+	}
+
+	return dst, err
+}
+
+// Appends s as a quoted JSON string, which must be valid UTF-8.
+func appendCanonicalString(dst []byte, s string) ([]byte, error) {
+	if !utf8.ValidString(s) {
+		msg := fmt.Sprintf(`Cannot canonicalize string "%s", it's not valid UTF-8`, strings.ToValidUTF8(s, "�"))
+		return dst, errors.New(msg)
+	}
+	return appendJsonString(dst, s), nil
+}
+
+// Appends f formatted like ECMAScript's Number.prototype.toString().
+func appendCanonicalNumber(dst []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		msg := fmt.Sprintf("Cannot canonicalize float %v, JSON has no literal for it", f)
+		return dst, errors.New(msg)
+	}
+	if f == 0 {
+		// Including -0
+		return append(dst, '0'), nil
+	}
+	if f < 0 {
+		dst = append(dst, '-')
+		f = -f
+	}
+
+	// Get the shortest round trip digits & decimal exponent from "d.ddde±xx". The value is
+	// 0.digits * 10^pointPos, in ECMAScript's terms.
+	var buf [32]byte
+	formatted := strconv.AppendFloat(buf[:0], f, 'e', -1, 64)
+	expIndex := slices.Index(formatted, 'e')
+	exp, _ := strconv.Atoi(string(formatted[expIndex+1:]))
+	digits := make([]byte, 0, expIndex)
+	for _, c := range formatted[:expIndex] {
+		if c != '.' {
+			digits = append(digits, c)
+		}
+	}
+	numDigits := len(digits)
+	pointPos := exp + 1
+
+	switch {
+	case numDigits <= pointPos && pointPos <= 21:
+		// Whole number, pad with zeros
+		dst = append(dst, digits...)
+		for i := numDigits; i < pointPos; i++ {
+			dst = append(dst, '0')
+		}
+	case 0 < pointPos && pointPos <= 21:
+		// Point inside the digits
+		dst = append(dst, digits[:pointPos]...)
+		dst = append(dst, '.')
+		dst = append(dst, digits[pointPos:]...)
+	case -6 < pointPos && pointPos <= 0:
+		// Small, lead with zeros
+		dst = append(dst, '0', '.')
+		for i := pointPos; i < 0; i++ {
+			dst = append(dst, '0')
+		}
+		dst = append(dst, digits...)
+	default:
+		// Exponent
+		dst = append(dst, digits[0])
+		if numDigits > 1 {
+			dst = append(dst, '.')
+			dst = append(dst, digits[1:]...)
+		}
+		dst = append(dst, 'e')
+		if pointPos-1 >= 0 {
+			dst = append(dst, '+')
+		}
+		dst = strconv.AppendInt(dst, int64(pointPos-1), 10)
+	}
+	return dst, nil
+}
+
+// Compares 2 valid UTF-8 strings by their UTF-16 code units.
+func compareUtf16(a string, b string) int {
+	for a != "" && b != "" {
+		aRune, aSize := utf8.DecodeRuneInString(a)
+		bRune, bSize := utf8.DecodeRuneInString(b)
+		if aRune != bRune {
+			// Runes past the BMP are encoded as a surrogate pair, which sorts before U+E000 to
+			// U+FFFF. Comparing the first code unit handles that. If those match, both are
+			// surrogate pairs, which sort the same as their runes.
+			aUnit, bUnit := utf16FirstUnit(aRune), utf16FirstUnit(bRune)
+			if aUnit != bUnit {
+				return int(aUnit) - int(bUnit)
+			}
+			return int(aRune) - int(bRune)
+		}
+		a, b = a[aSize:], b[bSize:]
+	}
+	return len(a) - len(b)
+}
+
+// Returns the first UTF-16 code unit for r.
+func utf16FirstUnit(r rune) rune {
+	if r >= 0x10000 {
+		return 0xD800 + ((r - 0x10000) >> 10)
+	}
+	return r
+}
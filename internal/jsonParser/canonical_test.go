@@ -0,0 +1,105 @@
+package jsonParser
+
+/*
+	Tests canonical JSON (RFC 8785) output.
+*/
+
+import (
+	"crypto/sha256"
+	"math"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func canonicalizeWithStr(t *testing.T, s string) string {
+	value, err := ParseJson(s)
+	assert.Nil(t, err, "Expected JSON to parse")
+	canonical, err := value.Canonicalize()
+	assert.Nil(t, err, "Expected value to canonicalize")
+	return string(canonical)
+}
+
+func TestCanonicalRfcExample(t *testing.T) {
+	// Example from RFC 8785 section 3.2.3
+	input := `{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`
+	expected := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+	assert.Equal(t, canonicalizeWithStr(t, input), expected, "Unexpected canonical JSON")
+}
+
+func TestCanonicalNumbers(t *testing.T) {
+	// IEEE 754 bit patterns & expected output from RFC 8785 appendix B
+	tests := []struct {
+		bits     uint64
+		expected string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+
+	for _, test := range tests {
+		canonical, err := appendCanonicalNumber(nil, math.Float64frombits(test.bits))
+		assert.Nil(t, err, "Expected number to canonicalize")
+		assert.Equal(t, string(canonical), test.expected, "Unexpected canonical number")
+	}
+
+	// Ints & Numbers are written the same as the equivalent float
+	assert.Equal(t, canonicalizeWithStr(t, `[1, 1.0, 100, 1e2, -0.0]`), `[1,1,100,100,0]`, "Unexpected canonical numbers")
+	number, _ := ParseJsonWithOptions(`[1.50, 1e400]`, ParseOptions{UseNumber: true})
+	_, err := number.Canonicalize()
+	assert.NotNil(t, err, "Expected error for number out of float64 range")
+	number, _ = ParseJsonWithOptions(`1.50`, ParseOptions{UseNumber: true})
+	canonical, _ := number.Canonicalize()
+	assert.Equal(t, string(canonical), "1.5", "Unexpected canonical Number")
+
+	_, err = NewJsonValue(math.NaN()).Canonicalize()
+	assert.NotNil(t, err, "Expected error for NaN")
+}
+
+func TestCanonicalKeyOrder(t *testing.T) {
+	// Example from RFC 8785 section 3.2.3. U+1F600 sorts before U+FB33 by UTF-16 code units,
+	// though not by bytes or code points.
+	input := "{\"\u20ac\": 1, \"\\r\": 2, \"\ufb33\": 3, \"1\": 4, \"\U0001F600\": 5, \"\u0080\": 6, \"\u00f6\": 7}"
+	expected := "{\"\\r\":2,\"1\":4,\"\u0080\":6,\"\u00f6\":7,\"\u20ac\":1,\"\U0001F600\":5,\"\ufb33\":3}"
+	assert.Equal(t, canonicalizeWithStr(t, input), expected, "Unexpected key order")
+}
+
+func TestCanonicalSha256(t *testing.T) {
+	a, _ := ParseJson(`{"b": [1.0, "x"], "a": true}`)
+	b, _ := ParseJson("{\n  \"a\": true,\n  \"b\": [1, \"x\"]\n}")
+	aDigest, err := a.CanonicalSha256()
+	assert.Nil(t, err, "Expected digest")
+	bDigest, _ := b.CanonicalSha256()
+	assert.Equal(t, aDigest, bDigest, "Expected equal documents to have the same digest")
+	assert.Equal(t, aDigest, sha256.Sum256([]byte(`{"a":true,"b":[1,"x"]}`)), "Expected digest of canonical JSON")
+
+	invalid := NewJsonValue(map[string]any{"a": "\xff"})
+	_, err = invalid.CanonicalSha256()
+	assert.NotNil(t, err, "Expected error for invalid UTF-8")
+}
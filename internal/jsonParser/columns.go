@@ -0,0 +1,234 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"tmelot.jsonparser/internal/profiler"
+)
+
+/*
+	ExtractColumns decodes number fields from an array of objects straight into 1 []float64 per
+	field (struct of arrays), without building a JsonValue, boxing numbers in interfaces or doing
+	map lookups. Loops over the columns are simple enough for the compiler (or hand written SIMD)
+	to vectorize.
+
+	```
+	columns, err := jsonParser.ExtractColumns(fileData, "/pairs", []string{"x0", "y0", "x1", "y1"})
+	x0, y0, x1, y1 := columns[0], columns[1], columns[2], columns[3]
+	for i := range x0 {
+		sum += haversine.ReferenceHaversine(x0[i], y0[i], x1[i], y1[i], EARTH_RADIUS)
+	}
+	```
+
+	The array is found with ExtractPaths() (see extract.go), so the path is a JSON pointer or
+	dotted path & everything outside the array is skipped. Each item must be an object with a
+	number for every field, so the columns line up. Other keys are skipped without being
+	validated. Numbers are converted to the nearest float64, ints included.
+*/
+
+// Max fields ExtractColumns() can decode, so each item can track the fields it's seen in 1 uint64.
+const MAX_COLUMNS = 64
+
+// Returns 1 column per field, holding that field from each object in the array at arrayPath.
+func ExtractColumns(data string, arrayPath string, fields []string) ([][]float64, error) {
+	if len(fields) > MAX_COLUMNS {
+		msg := fmt.Sprintf("Can't extract %d columns, max is %d", len(fields), MAX_COLUMNS)
+		return nil, errors.New(msg)
+	}
+	// Each key fills 1 column, so a field given twice would never fill its second column
+	for i, field := range fields {
+		if slices.Contains(fields[:i], field) {
+			msg := fmt.Sprintf(`Can't extract field "%s" into more than 1 column`, field)
+			return nil, errors.New(msg)
+		}
+	}
+
+	profiler.GlobalProfiler.StartBandwidth("ExtractColumns", uint64(len(data)))
+	defer profiler.GlobalProfiler.EndBandwidth("ExtractColumns")
+
+	d := &columnDecoder{
+		fields:  fields,
+		columns: make([][]float64, len(fields)),
+	}
+	e := &extractor{
+		data:    data,
+		options: DefaultParseOptions(),
+		results: map[string]*JsonValue{},
+		root:    &extractNode{},
+	}
+	e.matchValue = func(node *extractNode, start int) (int, error) {
+		e.markFound(node)
+		return d.decodeArray(e, start)
+	}
+	if err := e.addPath(arrayPath); err != nil {
+		return nil, err
+	}
+
+	start, err := e.findStart()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := e.extractValue(e.root, start); err != nil {
+		return nil, err
+	}
+	if e.remaining > 0 {
+		return nil, errors.New(fmt.Sprintf(`Array "%s" not found`, arrayPath))
+	}
+	return d.columns, nil
+}
+
+type columnDecoder struct {
+	fields  []string
+	columns [][]float64
+}
+
+// Decodes the array of objects starting at start into the columns.
+func (d *columnDecoder) decodeArray(e *extractor, start int) (int, error) {
+	// A duplicate key's array replaces the earlier 1, like ParseJson()
+	for f := range d.columns {
+		d.columns[f] = d.columns[f][:0]
+	}
+	if e.data[start] != '[' {
+		return start, e.syntaxError(start, fmt.Sprintf("Expected array \"%s\"", JSON_SYNTAX_LEFT_BRACKET))
+	}
+
+	// Guess the item count from the first item's size, so the columns rarely grow
+	i := e.skipWhitespace(start + 1)
+	if i < len(e.data) && e.data[i] == '{' {
+		if firstEnd, complete := scanContainerEnd(e.data, i, false); complete {
+			capacity := (len(e.data) - start) / (firstEnd - i + 1)
+			for f := range d.columns {
+				d.columns[f] = make([]float64, 0, capacity)
+			}
+		}
+	}
+
+	if i < len(e.data) && e.data[i] == ']' {
+		return i + 1, nil
+	}
+	for item := 0; ; item++ {
+		var err error
+		if i, err = d.decodeItem(e, i, item); err != nil {
+			return i, err
+		}
+
+		// "," or "]"
+		i = e.skipWhitespace(i)
+		if i < len(e.data) && e.data[i] == ']' {
+			return i + 1, nil
+		}
+		if i >= len(e.data) || e.data[i] != ',' {
+			msg := fmt.Sprintf("Expected item separator \"%s\" or close array \"%s\"", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACKET)
+			return i, e.syntaxError(i, msg)
+		}
+		i = e.skipWhitespace(i + 1)
+	}
+}
+
+// Decodes the fields of the object starting at start, which is the given item in the array.
+func (d *columnDecoder) decodeItem(e *extractor, start int, item int) (int, error) {
+	if start >= len(e.data) || e.data[start] != '{' {
+		return start, e.syntaxError(start, fmt.Sprintf("Expected object \"%s\" in column array", JSON_SYNTAX_LEFT_BRACE))
+	}
+
+	var seen uint64 // Bit per field
+	i := e.skipWhitespace(start + 1)
+	if i < len(e.data) && e.data[i] == '}' {
+		i += 1
+	} else {
+		for {
+			// Key & ":"
+			if i >= len(e.data) || e.data[i] != '"' {
+				return i, e.syntaxError(i, "Expected key string")
+			}
+			keyEnd, complete := scanStringEnd(e.data, i)
+			if !complete {
+				return i, newParseError(e.data, i, errors.New("End quote for string not found"))
+			}
+			key, err := e.decodeKey(i, keyEnd)
+			if err != nil {
+				return i, err
+			}
+			i = e.skipWhitespace(keyEnd)
+			if i >= len(e.data) || e.data[i] != ':' {
+				return i, e.syntaxError(i, fmt.Sprintf("Expected field assignment \"%s\"", JSON_SYNTAX_COLON))
+			}
+			i = e.skipWhitespace(i + 1)
+			if i >= len(e.data) {
+				return i, e.syntaxError(i, "Expected value")
+			}
+
+			// Value, decoded if it's a field
+			field := d.fieldIndex(key)
+			if field < 0 {
+				i, err = e.skipValue(i)
+			} else {
+				var value float64
+				value, i, err = d.decodeNumber(e, i, key)
+				if err == nil {
+					// A duplicate key replaces the earlier value, like ParseJson()
+					if seen&(1<<field) != 0 {
+						d.columns[field][item] = value
+					} else {
+						d.columns[field] = append(d.columns[field], value)
+						seen |= 1 << field
+					}
+				}
+			}
+			if err != nil {
+				return i, err
+			}
+
+			// "," or "}"
+			i = e.skipWhitespace(i)
+			if i < len(e.data) && e.data[i] == '}' {
+				i += 1
+				break
+			}
+			if i >= len(e.data) || e.data[i] != ',' {
+				msg := fmt.Sprintf("Expected field separator \"%s\" or close object \"%s\"", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACE)
+				return i, e.syntaxError(i, msg)
+			}
+			i = e.skipWhitespace(i + 1)
+		}
+	}
+
+	// Every field must be there, or the columns won't line up
+	if seen != (uint64(1)<<len(d.fields))-1 {
+		for field, name := range d.fields {
+			if seen&(1<<field) == 0 {
+				msg := fmt.Sprintf(`Item %d has no "%s" field`, item, name)
+				return start, newParseError(e.data, start, errors.New(msg))
+			}
+		}
+	}
+	return i, nil
+}
+
+// Returns the index of the field named key, or -1 if it isn't 1. There are only a few fields, so
+// a linear search beats a map.
+func (d *columnDecoder) fieldIndex(key string) int {
+	for field, name := range d.fields {
+		if name == key {
+			return field
+		}
+	}
+	return -1
+}
+
+// Decodes the number starting at start, for the field named key, & returns it with the index past
+// its end.
+func (d *columnDecoder) decodeNumber(e *extractor, start int, key string) (float64, int, error) {
+	end, _ := scanValueEnd(e.data, start, false)
+	literal := e.data[start:end]
+	if !isValidJsonNumber(literal) {
+		return 0, start, e.syntaxError(start, fmt.Sprintf(`Expected number for "%s"`, key))
+	}
+	value, err := parseFloat64(literal)
+	if err != nil {
+		return 0, start, newParseError(e.data, start, err)
+	}
+	return value, end, nil
+}
@@ -0,0 +1,117 @@
+package jsonParser
+
+/*
+	Tests ExtractColumns().
+*/
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func TestExtractColumns(t *testing.T) {
+	data := `{
+		"meta": {"pairs": "not these"},
+		"pairs": [
+			{"x0": 1.5, "y0": -2, "x1": 3e2, "y1": 0, "note": ["}", {"a": 1}]},
+			{"y1": 4, "x1": 3, "y0": 2, "x0": 1},
+			{"x0": 0.1, "y0": 0.2, "x1": 0.3, "y1": 0.4, "x0": 9}
+		]
+	}`
+	columns, err := ExtractColumns(data, "pairs", []string{"x0", "y0", "x1", "y1"})
+	assert.Nil(t, err, "Expected columns to extract")
+	assert.Equal(t, len(columns), 4, "Expected a column per field")
+
+	expected := [][]float64{
+		{1.5, 1, 9},
+		{-2, 2, 0.2},
+		{300, 3, 0.3},
+		{0, 4, 0.4},
+	}
+	for f := range expected {
+		assert.Equal(t, len(columns[f]), 3, "Expected a value per item")
+		for item := range expected[f] {
+			assert.Equal(t, columns[f][item], expected[f][item], fmt.Sprintf("Expected column %d item %d", f, item))
+		}
+	}
+}
+
+func TestExtractColumnsMatchesParse(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString(`{"pairs": [`)
+	for i := 0; i < 50; i++ {
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+		fmt.Fprintf(&sb, `{"x0": %v, "y0": %v, "x1": %d, "y1": %v}`, float64(i)*1.3371, -45.123456789, i, 1e-7*float64(i))
+	}
+	sb.WriteString(`]}`)
+	data := sb.String()
+
+	columns, err := ExtractColumns(data, "/pairs", []string{"x0", "y0", "x1", "y1"})
+	assert.Nil(t, err, "Expected columns to extract")
+	whole, err := ParseJson(data)
+	assert.Nil(t, err, "Expected document to parse")
+	pairs, _ := whole.GetArray("pairs")
+	for i, pair := range pairs {
+		for f, field := range []string{"x0", "y0", "x1", "y1"} {
+			expected, _ := Get[float64](pair, "/"+field)
+			assert.Equal(t, columns[f][i], expected, fmt.Sprintf("Expected pair %d %s to match parse", i, field))
+		}
+	}
+}
+
+func TestExtractColumnsEmpty(t *testing.T) {
+	columns, err := ExtractColumns(`{"pairs": []}`, "pairs", []string{"x0"})
+	assert.Nil(t, err, "Expected empty array to extract")
+	assert.Equal(t, len(columns[0]), 0, "Expected empty column")
+}
+
+func TestExtractColumnsDuplicateKeys(t *testing.T) {
+	// The last array is used, like ParseJson()
+	columns, err := ExtractColumns(`{"pairs": [{"x0": 1}, {"x0": 2}], "pairs": [{"x0": 3}]}`, "pairs", []string{"x0"})
+	assert.Nil(t, err, "Expected duplicate arrays to extract")
+	assert.Equal(t, fmt.Sprint(columns[0]), "[3]", "Expected the last array's column")
+
+	_, err = ExtractColumns(`{"d": {"pairs": [{"x0": 1}]}, "d": {}}`, "/d/pairs", []string{"x0"})
+	assert.NotNil(t, err, "Expected error for array replaced by a duplicate key")
+}
+
+func TestExtractColumnsErrors(t *testing.T) {
+	_, err := ExtractColumns(`{"pairs": [{"x0": 1}, {"y0": 2}]}`, "pairs", []string{"x0"})
+	assert.NotNil(t, err, "Expected error for missing field")
+	assert.Equal(t, strings.Contains(err.Error(), `Item 1 has no "x0" field`), true, "Expected missing field message")
+
+	_, err = ExtractColumns(`{"pairs": [{"x0": "1"}]}`, "pairs", []string{"x0"})
+	var parseErr *ParseError
+	assert.Equal(t, errors.As(err, &parseErr), true, "Expected a ParseError for a non-number")
+	assert.Equal(t, parseErr.Offset, 18, "Expected error at the value")
+
+	_, err = ExtractColumns(`{"pairs": [{"x0": 01}]}`, "pairs", []string{"x0"})
+	assert.NotNil(t, err, "Expected error for invalid number")
+	_, err = ExtractColumns(`{"pairs": [1, 2]}`, "pairs", []string{"x0"})
+	assert.NotNil(t, err, "Expected error for non-object items")
+	_, err = ExtractColumns(`{"pairs": {"x0": 1}}`, "pairs", []string{"x0"})
+	assert.NotNil(t, err, "Expected error for non-array")
+	_, err = ExtractColumns(`{"other": []}`, "pairs", []string{"x0"})
+	assert.NotNil(t, err, "Expected error for missing array")
+	_, err = ExtractColumns(`{"pairs": [{"x0": 1]}`, "pairs", []string{"x0"})
+	assert.NotNil(t, err, "Expected error for bad object")
+
+	_, err = ExtractColumns(`{"pairs": [{"x0": 1}]}`, "pairs", []string{"x0", "y0", "x0"})
+	assert.NotNil(t, err, "Expected error for a field given twice")
+	assert.Equal(t, strings.Contains(err.Error(), `"x0" into more than 1 column`), true, "Expected duplicate field message")
+}
+
+func TestExtractColumnsAllocations(t *testing.T) {
+	// Allocations don't grow with the number of items
+	data := `{"pairs": [` + strings.Repeat(`{"x0": 1.5, "y0": 2, "name": "skipped"},`, 1000) + `{"x0": 1, "y0": 2}]}`
+	allocs := testing.AllocsPerRun(10, func() {
+		ExtractColumns(data, "pairs", []string{"x0", "y0"})
+	})
+	assert.Equal(t, allocs < 20, true, fmt.Sprintf("Expected few allocations, got %v", allocs))
+}
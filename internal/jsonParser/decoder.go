@@ -0,0 +1,178 @@
+package jsonParser
+
+import (
+	"io"
+	"strings"
+)
+
+/*
+	Decoder reads a stream of JSON values that follow each other with no separator, like
+	`{...}{...}\n[...] "abc" 1`, as log sinks often write them. Any whitespace between values is
+	skipped. Each value is parsed on its own with the given options, so limits apply per value.
+
+	```
+	decoder := jsonParser.NewDecoder(file, jsonParser.DefaultParseOptions())
+	for {
+		value, offset, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err // A *ParseError, positioned in the whole stream
+		}
+		...
+	}
+	```
+
+	Use NewDecoderFromString() to decode a buffer already in memory, which skips copying it.
+
+	Value bounds are found with the value scanner (see valueScanner.go) before parsing, so a
+	broken value can't swallow the ones after it, unless it has an unclosed string or bracket.
+*/
+
+// Min number of bytes to read from the reader at a time. Reads grow with the buffered data, so a
+// big value takes a few reads rather than 1 per DECODER_READ_SIZE.
+const DECODER_READ_SIZE = 64 * 1024
+
+type Decoder struct {
+	options ParseOptions
+
+	// Input is either data (decoding a string) or read into buf from reader
+	data   string
+	reader io.Reader
+	buf    []byte
+	eof    bool
+
+	pos        int // Position in data or buf
+	bufOffset  int // Offset of buf[0] in the whole stream, since decoded data is dropped
+	line       int // Line number of pos, starting at 1
+	lineOffset int // Offset of the start of that line in the whole stream
+}
+
+// Creates a decoder that reads values from r.
+func NewDecoder(r io.Reader, options ParseOptions) *Decoder {
+	return &Decoder{
+		options: options,
+		reader:  r,
+		line:    1,
+	}
+}
+
+// Creates a decoder that reads values from data.
+func NewDecoderFromString(data string, options ParseOptions) *Decoder {
+	return &Decoder{
+		options: options,
+		data:    data,
+		eof:     true,
+		line:    1,
+	}
+}
+
+// Returns the next value with the byte offset it starts at in the stream, or io.EOF when there
+// are no more values. Parse errors are *ParseError, positioned in the whole stream. The broken
+// value is skipped, so calling Next() again returns the value after it.
+func (d *Decoder) Next() (*JsonValue, int, error) {
+	for {
+		var start, end int
+		var complete bool
+		if d.reader == nil {
+			start, end, complete = findNextValue(d.data, d.pos, d.options.Relaxed, d.eof)
+		} else {
+			start, end, complete = findNextValue(d.buf, d.pos, d.options.Relaxed, d.eof)
+		}
+
+		// Not sure where the value ends yet, so get more data & scan again
+		if !complete && !d.eof {
+			if err := d.fill(); err != nil {
+				return nil, d.InputOffset(), err
+			}
+			continue
+		}
+
+		// Copy out of the buffer, since it's reused & the parsed value keeps slices of its input
+		var valueData string
+		if d.reader == nil {
+			valueData = d.data[start:end]
+		} else {
+			valueData = string(d.buf[start:end])
+		}
+		if valueData == "" {
+			d.advance(start)
+			return nil, d.InputOffset(), io.EOF
+		}
+
+		d.advance(start)
+		offset := d.InputOffset()
+		value, err := ParseJsonWithOptions(valueData, d.options)
+		if err != nil {
+			// Skip the broken value, so the next call carries on with the value after it. A stray
+			// close bracket scans as an empty value, so skip at least 1 byte.
+			err = relocateParseError(err, offset, d.line, d.lineOffset)
+			d.advance(max(end, start+1))
+			return nil, offset, err
+		}
+		d.advance(end)
+		return value, offset, nil
+	}
+}
+
+// Returns the offset in the stream just past the last value returned by Next().
+func (d *Decoder) InputOffset() int {
+	return d.bufOffset + d.pos
+}
+
+// Finds the next value at or after pos. Returns an empty value at the end of the input.
+func findNextValue[T ~string | ~[]byte](data T, pos int, relaxed, atEOF bool) (start, end int, complete bool) {
+	start, complete = skipValueWhitespace(data, pos, relaxed, atEOF)
+	if !complete {
+		// Unclosed comment, pass the rest to the parser to report it
+		return start, len(data), false
+	}
+	if start == len(data) {
+		return start, start, atEOF
+	}
+
+	end, complete = scanValueEnd(data, start, relaxed)
+	return start, end, complete
+}
+
+// Moves pos forward to newPos, keeping count of lines for error positions.
+func (d *Decoder) advance(newPos int) {
+	var skipped string
+	if d.reader == nil {
+		skipped = d.data[d.pos:newPos]
+	} else {
+		skipped = string(d.buf[d.pos:newPos])
+	}
+
+	if newlines := strings.Count(skipped, "\n"); newlines > 0 {
+		d.line += newlines
+		d.lineOffset = d.InputOffset() + strings.LastIndexByte(skipped, '\n') + 1
+	}
+	d.pos = newPos
+}
+
+// Reads more data into buf, dropping data that's already been decoded.
+func (d *Decoder) fill() error {
+	// Drop decoded data once it's at least half the buffer, so the copy is paid for
+	if d.pos > 0 && d.pos >= len(d.buf)/2 {
+		d.bufOffset += d.pos
+		d.buf = append(d.buf[:0], d.buf[d.pos:]...)
+		d.pos = 0
+	}
+
+	readSize := max(DECODER_READ_SIZE, len(d.buf)-d.pos)
+	if cap(d.buf)-len(d.buf) < readSize {
+		newBuf := make([]byte, len(d.buf), len(d.buf)+readSize)
+		copy(newBuf, d.buf)
+		d.buf = newBuf
+	}
+
+	n, err := d.reader.Read(d.buf[len(d.buf):cap(d.buf)])
+	d.buf = d.buf[:len(d.buf)+n]
+	if err == io.EOF {
+		d.eof = true
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package jsonParser
+
+/*
+	Tests decoding streams of concatenated JSON values.
+*/
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+// Decodes all values in the stream, returning their serialized JSON & offsets.
+func decodeAll(t *testing.T, decoder *Decoder) ([]string, []int, error) {
+	var values []string
+	var offsets []int
+	for {
+		value, offset, err := decoder.Next()
+		if err == io.EOF {
+			return values, offsets, nil
+		}
+		if err != nil {
+			return values, offsets, err
+		}
+		values = append(values, serializeToStr(t, value))
+		offsets = append(offsets, offset)
+	}
+}
+
+func TestDecoderValues(t *testing.T) {
+	input := `{"a":1}{"b":[1,"}"]}` + "\n" + `[true,{}]  "x\"y" -1.5e3 false{}`
+	expected := []string{`{"a":1}`, `{"b":[1,"}"]}`, `[true,{}]`, `"x\"y"`, `-1500.0`, `false`, `{}`}
+	expectedOffsets := []int{0, 7, 21, 32, 39, 46, 51}
+
+	// The same values no matter how the input is split up by reads
+	readers := map[string]func() *Decoder{
+		"string": func() *Decoder { return NewDecoderFromString(input, DefaultParseOptions()) },
+		"reader": func() *Decoder { return NewDecoder(strings.NewReader(input), DefaultParseOptions()) },
+		"1 byte reads": func() *Decoder {
+			return NewDecoder(iotest.OneByteReader(strings.NewReader(input)), DefaultParseOptions())
+		},
+	}
+	for name, newDecoder := range readers {
+		values, offsets, err := decodeAll(t, newDecoder())
+		assert.Nil(t, err, "Expected stream to decode with "+name)
+		assert.Equal(t, len(values), len(expected), "Unexpected value count with "+name)
+		for i := range expected {
+			assert.Equal(t, values[i], expected[i], "Unexpected value with "+name)
+			assert.Equal(t, offsets[i], expectedOffsets[i], "Unexpected offset with "+name)
+		}
+	}
+
+	// Empty & whitespace only streams have no values
+	values, _, err := decodeAll(t, NewDecoderFromString(" \n\t", DefaultParseOptions()))
+	assert.Nil(t, err, "Expected whitespace stream to decode")
+	assert.Equal(t, len(values), 0, "Expected no values")
+}
+
+func TestDecoderBigValues(t *testing.T) {
+	// Values bigger than a read, split across reads
+	bigString := strings.Repeat("ab{[", DECODER_READ_SIZE)
+	input := `"` + bigString + `"{"k":"` + bigString + `"}`
+	values, offsets, err := decodeAll(t, NewDecoder(strings.NewReader(input), DefaultParseOptions()))
+	assert.Nil(t, err, "Expected big values to decode")
+	assert.Equal(t, len(values), 2, "Expected 2 values")
+	assert.Equal(t, offsets[1], len(bigString)+2, "Expected offset after first big value")
+}
+
+func TestDecoderRelaxed(t *testing.T) {
+	options := DefaultParseOptions()
+	options.Relaxed = true
+	input := "// header\n{a: 1, /* } */}\n'b' /* between */ [2,] // trailing"
+	values, _, err := decodeAll(t, NewDecoder(iotest.OneByteReader(strings.NewReader(input)), options))
+	assert.Nil(t, err, "Expected relaxed stream to decode")
+	assert.Equal(t, len(values), 3, "Expected 3 values")
+	assert.Equal(t, values[0], `{"a":1}`, "Expected comment inside object to be skipped")
+
+	_, _, err = decodeAll(t, NewDecoderFromString("{} /* unclosed", options))
+	assert.NotNil(t, err, "Expected error on unclosed comment, did not error")
+}
+
+func TestDecoderErrors(t *testing.T) {
+	// Errors are positioned in the whole stream, & the values before them still decode
+	input := "{\"a\": 1}\n[1, 2]\n  {\"a\": 1 \"b\": 2}"
+	decoder := NewDecoderFromString(input, DefaultParseOptions())
+	values, _, err := decodeAll(t, decoder)
+	assert.Equal(t, len(values), 2, "Expected values before the error to decode")
+	parseErr := getParseError(err)
+	assert.NotNil(t, parseErr, "Expected a ParseError")
+	assert.Equal(t, parseErr.Offset, 26, "Expected error at the key missing a comma")
+	assert.Equal(t, parseErr.Line, 3, "Expected error on line 3")
+	assert.Equal(t, parseErr.Column, 11, "Expected error column within the line")
+
+	// Positioned errors keep their position within the value
+	decoder = NewDecoderFromString("[1]\n [\n\"\x01\"]", DefaultParseOptions())
+	_, _, err = decodeAll(t, decoder)
+	parseErr = getParseError(err)
+	assert.Equal(t, parseErr.Line, 3, "Expected control character error on line 3")
+	assert.Equal(t, parseErr.Column, 2, "Expected control character error column")
+
+	// Broken values are skipped, so the values after them still decode
+	for _, input := range []string{`{"a":1}{"a" 2}{"b":3}`, `{"a":1} } {"b":3}`, `{"a":1} [1,,] {"b":3}`} {
+		decoder = NewDecoder(strings.NewReader(input), DefaultParseOptions())
+		var decoded []string
+		numErrors := 0
+		for {
+			value, _, err := decoder.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				numErrors += 1
+				assert.Equal(t, numErrors < 10, true, "Expected the broken value to be skipped in "+input)
+				if numErrors >= 10 {
+					break
+				}
+				continue
+			}
+			decoded = append(decoded, serializeToStr(t, value))
+		}
+		assert.Equal(t, numErrors, 1, "Expected 1 error in "+input)
+		assert.Equal(t, strings.Join(decoded, " "), `{"a":1} {"b":3}`, "Expected values around the broken 1 in "+input)
+	}
+
+	// Stray closing brackets & unclosed values
+	for _, bad := range []string{`{}}`, `{} ,`, `{"a": [1}`, `[1, 2`, `"abc`} {
+		_, _, err = decodeAll(t, NewDecoder(strings.NewReader(bad), DefaultParseOptions()))
+		assert.NotNil(t, err, "Expected error for "+bad+", did not error")
+	}
+}
+
+func TestParserRootValues(t *testing.T) {
+	// Any value can be the root
+	result, err := runParserWithStr(`[1, 2]`)
+	assert.Nil(t, err, "Expected root array to parse")
+	items, _ := result.GetArray("")
+	assert.Equal(t, len(items), 2, "Expected root array items")
+
+	result, err = runParserWithStr(` "abc" `)
+	assert.Nil(t, err, "Expected root string to parse")
+	s, _ := result.GetString("")
+	assert.Equal(t, s, "abc", "Expected root string")
+
+	// Only 1 root value
+	_, err = runParserWithStr(`{"a": 1}{"b": 2}`)
+	assert.NotNil(t, err, "Expected error on second root value, did not error")
+	assert.Equal(t, getParseError(err).Offset, 8, "Expected error at second root value")
+	_, err = runParserWithStr(`{"a": 1}}`)
+	assert.NotNil(t, err, "Expected error on extra close brace, did not error")
+}
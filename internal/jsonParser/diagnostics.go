@@ -0,0 +1,202 @@
+package jsonParser
+
+import (
+	"errors"
+	"slices"
+	"sort"
+)
+
+/*
+	Diagnostic mode parses as much as it can of broken input & reports every syntax error it
+	finds, instead of stopping at the first one. Use it to fix a broken file in 1 pass:
+
+	```
+	jsonResult, diagnostics := jsonParser.ParseJsonDiagnostics(fileData, jsonParser.DefaultParseOptions())
+	for _, diagnostic := range diagnostics {
+		fmt.Println(diagnostic) // "Expected key string, found "," instead at line 12, column 5 (offset 301)"
+	}
+	```
+
+	Recovery
+	- Lexer: text that can't be lexed (like `nul` or `#`) is reported & becomes a placeholder
+	  token, which the parser skips without reporting again. A bad string is reported & kept as
+	  its raw text. An unterminated string ends at the end of its line, since strings can't hold
+	  raw newlines.
+	- Parser: after an error in an object member or array item, skips to the next comma or
+	  closing bracket at the same depth & carries on with the next member or item. A missing
+	  comma between 2 members or items is reported without losing either.
+
+	The returned value is the best effort partial result: bad members & items are left out. It's
+	nil if not even the root value could be parsed.
+
+	Resource limits (see ParseOptions) still stop parsing, since carrying on would defeat them.
+*/
+
+// Parses the given string using the given options, recovering from syntax errors. Returns the
+// partial result along with every error found, in input order. Diagnostics are empty when the
+// input is valid.
+func ParseJsonDiagnostics(fileData string, options ParseOptions) (*JsonValue, []*ParseError) {
+	if options.MaxDocumentSize > 0 && len(fileData) > options.MaxDocumentSize {
+		return nil, []*ParseError{newParseError(fileData, options.MaxDocumentSize, ErrMaxDocumentSizeExceeded)}
+	}
+
+	// Lex into tokens
+	lexer := newLexer(fileData)
+	lexer.options = options
+	lexer.recover = true
+	tokens, err := lexer.lex()
+	diagnostics := lexer.diagnostics
+	if err != nil {
+		return nil, append(diagnostics, asParseError(fileData, len(fileData), err))
+	}
+
+	// Parse
+	parser := newParser(tokens)
+	parser.data = fileData
+	parser.options = options
+	parser.recover = true
+	err = parser.parse()
+	diagnostics = append(diagnostics, parser.diagnostics...)
+	if err != nil {
+		// Parsing stopped here, so drop lexer errors past it
+		limitErr := asParseError(fileData, len(fileData), err)
+		diagnostics = slices.DeleteFunc(diagnostics, func(diagnostic *ParseError) bool {
+			return diagnostic.Offset > limitErr.Offset
+		})
+		diagnostics = append(diagnostics, limitErr)
+	}
+
+	// Lexer & parser errors are found in separate passes, so merge them into input order
+	slices.SortStableFunc(diagnostics, func(a, b *ParseError) int {
+		return a.Offset - b.Offset
+	})
+
+	// A root that was skipped or failed before it started leaves nothing on the tape
+	if len(parser.tape.nodes) == 0 {
+		if !parser.rootParsed {
+			return nil, diagnostics
+		}
+		parser.tape.appendNode(tapeNull, 0)
+	}
+	return &JsonValue{tape: parser.tape}, diagnostics
+}
+
+// Returns true if err is from going over a resource limit, which diagnostic mode doesn't
+// recover from.
+func isLimitError(err error) bool {
+	return errors.Is(err, ErrMaxDepthExceeded) ||
+		errors.Is(err, ErrMaxDocumentSizeExceeded) ||
+		errors.Is(err, ErrMaxTokensExceeded) ||
+		errors.Is(err, ErrMaxStringLengthExceeded) ||
+		errors.Is(err, ErrMaxContainerSizeExceeded)
+}
+
+// Returns err as a ParseError, positioning it at offset if it isn't one already.
+func asParseError(data string, offset int, err error) *ParseError {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr
+	}
+	return newParseError(data, offset, err)
+}
+
+// Returns the length of the text at the start of s that can't be lexed, up to the next
+// delimiter. It's at least 1 byte.
+func invalidTextLength(s string, relaxed bool) int {
+	i := 1
+	for i < len(s) && !isValueDelimiter(s[i], relaxed) {
+		i += 1
+	}
+	return i
+}
+
+// Records an error the lexer is recovering from.
+func (l *Lexer) recordDiagnostic(err error) {
+	l.diagnostics = append(l.diagnostics, asParseError(l.data, l.pos, err))
+}
+
+// Returns a token with the raw text of the string at the current position, which failed to lex,
+// along with the number of characters consumed. The string ends at the next unescaped quote, or
+// at the end of the line if it's unterminated.
+func (l *Lexer) recoverString() (*Token, int) {
+	s := l.getUnlexedData()
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i += 1
+		case quote:
+			return &Token{Type: JsonString, Value: s[1:i]}, i + 1
+		case '\n':
+			return &Token{Type: JsonString, Value: s[1:i]}, i
+		}
+	}
+	return &Token{Type: JsonString, Value: s[1:]}, len(s)
+}
+
+// Records an error the parser is recovering from.
+func (p *Parser) recordDiagnostic(err error) {
+	p.diagnostics = append(p.diagnostics, asParseError(p.data, p.tokenOffset(p.peekToken(p.pos-1)), err))
+}
+
+// In diagnostic mode, records err & moves back to the token it's positioned at, ready for
+// skipToNextItem(). Returns false if parsing can't recover, because it's not in diagnostic mode
+// or err is from a resource limit.
+func (p *Parser) recoverFrom(err error) bool {
+	if !p.recover || isLimitError(err) {
+		return false
+	}
+	p.recordDiagnostic(err)
+
+	// The bad token is the last 1 consumed (or the end), so this never moves back past the
+	// start of the current item
+	offset := p.diagnostics[len(p.diagnostics)-1].Offset
+	p.pos = sort.Search(len(p.Tokens), func(i int) bool {
+		return p.Tokens[i].Pos >= offset
+	})
+	return true
+}
+
+// Skips tokens until the end of the current item in the container closed by closeType. Returns
+// true if it stopped after a separator, so there's another item to parse, or false if the
+// container's closed or the tokens ran out.
+//
+// A close for a different container type is left for the outer container to consume, since it
+// most likely closes that 1 & this container is just missing its close.
+func (p *Parser) skipToNextItem(closeType TokenType) bool {
+	depth := 0
+	for {
+		token := p.peekToken(p.pos)
+		if token == nil {
+			return false
+		}
+
+		switch token.Type {
+		case JsonObjectStart, JsonArrayStart:
+			depth += 1
+		case JsonObjectEnd, JsonArrayEnd:
+			if depth == 0 {
+				if token.Type == closeType {
+					p.getNextToken()
+				}
+				return false
+			}
+			depth -= 1
+		case JsonFieldSeparator:
+			if depth == 0 {
+				p.getNextToken()
+				return true
+			}
+		}
+		p.getNextToken()
+	}
+}
+
+// Returns true if the token can start a value.
+func isValueStartToken(token *Token) bool {
+	switch token.Type {
+	case JsonObjectStart, JsonArrayStart, JsonString, JsonNumber, JsonBool, JsonNull, JsonIdentifier, JsonInvalid:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package jsonParser
+
+/*
+	Tests diagnostic mode, which recovers from errors & reports all of them.
+*/
+
+import (
+	"errors"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func runParserDiagnostics(s string) (*JsonValue, []*ParseError) {
+	return ParseJsonDiagnostics(s, DefaultParseOptions())
+}
+
+func TestDiagnosticsValidJson(t *testing.T) {
+	result, diagnostics := runParserDiagnostics(`{"a": [1, 2], "b": {"c": true}}`)
+	assert.Equal(t, len(diagnostics), 0, "Expected no diagnostics for valid JSON")
+	c, _ := result.GetObject("b")
+	cVal, _ := c.GetBool("c")
+	assert.Equal(t, cVal, true, "Expected full result for valid JSON")
+}
+
+func TestDiagnosticsAllErrors(t *testing.T) {
+	input := `{
+	"a": 1,
+	"b": nul,
+	"c": [1, 2 3, #, 4],
+	"d" 5,
+	"e": {"x": 1,, "y": 2},
+	"f": "ok"
+}`
+	result, diagnostics := runParserDiagnostics(input)
+
+	// Each error is reported once, in input order, with its position
+	expectedLines := []int{3, 4, 4, 5, 6}
+	expectedColumns := []int{7, 13, 16, 6, 15}
+	assert.Equal(t, len(diagnostics), len(expectedLines), "Unexpected number of diagnostics")
+	for i := range expectedLines {
+		assert.Equal(t, diagnostics[i].Line, expectedLines[i], "Unexpected diagnostic line")
+		assert.Equal(t, diagnostics[i].Column, expectedColumns[i], "Unexpected diagnostic column")
+	}
+
+	// Everything that could be parsed is kept
+	a, _ := result.GetInt("a")
+	assert.Equal(t, a, 1, "Expected member before errors")
+	_, err := result.GetInt("b")
+	assert.NotNil(t, err, "Expected bad member to be left out")
+	c, _ := result.GetArray("c")
+	assert.Equal(t, len(c), 4, "Expected good items around bad ones, including after a missing comma")
+	_, err = result.GetInt("d")
+	assert.NotNil(t, err, "Expected member missing a colon to be left out")
+	e, _ := result.GetObject("e")
+	y, _ := e.GetInt("y")
+	assert.Equal(t, y, 2, "Expected member after a double comma")
+	f, _ := result.GetString("f")
+	assert.Equal(t, f, "ok", "Expected member after errors")
+}
+
+func TestDiagnosticsRecovery(t *testing.T) {
+	// Missing comma between members keeps both
+	result, diagnostics := runParserDiagnostics(`{"a": 1 "b": 2}`)
+	assert.Equal(t, len(diagnostics), 1, "Expected 1 diagnostic for missing comma")
+	b, _ := result.GetInt("b")
+	assert.Equal(t, b, 2, "Expected member after missing comma")
+
+	// Missing close bracket keeps the inner container
+	result, diagnostics = runParserDiagnostics(`{"a": [1, 2}`)
+	assert.Equal(t, len(diagnostics), 1, "Expected 1 diagnostic for missing close bracket")
+	a1, _ := result.GetArray("a")
+	assert.Equal(t, len(a1), 2, "Expected array missing its close bracket")
+
+	// Bad strings are kept as raw text, & unterminated ones end at the end of the line
+	result, diagnostics = runParserDiagnostics("{\"a\": \"bad \\q escape\", \"b\": \"unterminated\n, \"c\": 3}")
+	assert.Equal(t, len(diagnostics), 2, "Expected 2 diagnostics for bad strings")
+	a, _ := result.GetString("a")
+	assert.Equal(t, a, `bad \q escape`, "Expected raw text of bad string")
+	c, _ := result.GetInt("c")
+	assert.Equal(t, c, 3, "Expected member after unterminated string")
+
+	// Unclosed containers are each reported
+	result, diagnostics = runParserDiagnostics(`{"a": [1, {"b": 2`)
+	assert.Equal(t, len(diagnostics), 3, "Expected 1 diagnostic per unclosed container")
+	a2, _ := result.GetArray("a")
+	assert.Equal(t, len(a2), 2, "Expected partial array")
+
+	// Content after the root
+	_, diagnostics = runParserDiagnostics(`{"a": 1}}`)
+	assert.Equal(t, len(diagnostics), 1, "Expected 1 diagnostic for content after the root")
+
+	// Nothing to parse
+	result, diagnostics = runParserDiagnostics(`}`)
+	assert.Equal(t, len(diagnostics), 1, "Expected 1 diagnostic for bad root")
+	assert.Equal(t, result == nil, true, "Expected no result for bad root")
+}
+
+func TestDiagnosticsLimits(t *testing.T) {
+	// Limits stop parsing
+	options := DefaultParseOptions()
+	options.MaxDepth = 2
+	_, diagnostics := ParseJsonDiagnostics(`{"a": #, "b": [[1]], "c": #}`, options)
+	assert.Equal(t, len(diagnostics), 2, "Expected to stop at the limit")
+	assert.Equal(t, errors.Is(diagnostics[1], ErrMaxDepthExceeded), true, "Expected limit error last")
+}
+
+func TestParserRootBools(t *testing.T) {
+	// Bools at the very end of the input
+	result, err := runParserWithStr(`true`)
+	assert.Nil(t, err, "Expected root true to parse")
+	b, _ := result.GetBool("")
+	assert.Equal(t, b, true, "Expected root true")
+	result, err = runParserWithStr(`[false]`)
+	assert.Nil(t, err, "Expected array of false to parse")
+}
+
+func TestParserDiagnosticsNull(t *testing.T) {
+	// A null root is a result, unlike a root that failed to parse
+	result, diagnostics := ParseJsonDiagnostics(`null`, DefaultParseOptions())
+	assert.Equal(t, len(diagnostics), 0, "Expected no diagnostics")
+	assert.NotNil(t, result, "Expected null root result")
+
+	result, diagnostics = ParseJsonDiagnostics(`[null, nul, 1]`, DefaultParseOptions())
+	assert.Equal(t, len(diagnostics), 1, "Expected 1 diagnostic")
+	items, _ := result.GetArray("")
+	assert.Equal(t, len(items), 2, "Expected null kept & bad item left out")
+}
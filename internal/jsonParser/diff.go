@@ -0,0 +1,179 @@
+package jsonParser
+
+import (
+	"fmt"
+	"slices"
+)
+
+/*
+	Diff compares 2 parsed documents & reports every path that was added, removed or changed.
+
+	```
+	changes := jsonParser.Diff(oldPairs, newPairs, jsonParser.DiffOptions{FloatTolerance: 1e-9})
+	for _, change := range changes {
+		fmt.Println(change) // "~ /pairs/3/x0: 1.5 -> 1.25"
+	}
+	patch := jsonParser.DiffToPatch(changes) // RFC 6902 patch from oldPairs to newPairs
+	```
+
+	Objects are compared key by key. Arrays are compared item by item at the same index, so an
+	item inserted near the start shows up as a change to every item after it, plus an add at the
+	end. Values of different types (like a number & a string) are a single change.
+
+	Changes come out in key & index order, except removed array items, which come last item
+	first. That's the order they can be applied in as a patch without indexes shifting.
+*/
+
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+type DiffOptions struct {
+	// Max absolute difference between 2 numbers for them to still count as equal. 0 means
+	// numbers must have the same value (1 & 1.0 are still equal).
+	FloatTolerance float64
+}
+
+type DiffChange struct {
+	Kind DiffKind
+	Path string     // JSON pointer to the value, see jsonPointer.go
+	Old  *JsonValue // nil for added values
+	New  *JsonValue // nil for removed values
+}
+
+// Returns the change in diff format: "+" for added, "-" for removed & "~" for changed.
+func (c *DiffChange) String() string {
+	switch c.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("+ %s: %s", c.Path, diffValueString(c.New))
+	case DiffRemoved:
+		return fmt.Sprintf("- %s: %s", c.Path, diffValueString(c.Old))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", c.Path, diffValueString(c.Old), diffValueString(c.New))
+}
+
+// Returns every difference between from & to, or none if they're equal.
+func Diff(from *JsonValue, to *JsonValue, options DiffOptions) []*DiffChange {
+	differ := &differ{
+		options:     options,
+		fromMapping: from.mapping,
+		toMapping:   to.mapping,
+	}
+	differ.diffValues(from.tree(), to.tree(), "")
+	return differ.changes
+}
+
+// Returns a JSON Patch that turns the diffed from value into to, see patch.go.
+func DiffToPatch(changes []*DiffChange) *JsonValue {
+	operations := make([]any, len(changes))
+	for i, change := range changes {
+		operation := map[string]any{
+			"path": change.Path,
+		}
+		switch change.Kind {
+		case DiffAdded:
+			operation["op"] = "add"
+			operation["value"] = cloneJsonValue(change.New.tree(), change.New.mapping != nil)
+		case DiffRemoved:
+			operation["op"] = "remove"
+		case DiffChanged:
+			operation["op"] = "replace"
+			operation["value"] = cloneJsonValue(change.New.tree(), change.New.mapping != nil)
+		}
+		operations[i] = operation
+	}
+	return NewJsonValue(operations)
+}
+
+type differ struct {
+	options     DiffOptions
+	fromMapping *fileMapping // Passed on to changed values, so they keep the mapping alive
+	toMapping   *fileMapping
+	changes     []*DiffChange
+}
+
+func (d *differ) addChange(kind DiffKind, path string, oldVal any, newVal any) {
+	change := &DiffChange{Kind: kind, Path: path}
+	if kind != DiffAdded {
+		change.Old = newJsonValue(oldVal, d.fromMapping)
+	}
+	if kind != DiffRemoved {
+		change.New = newJsonValue(newVal, d.toMapping)
+	}
+	d.changes = append(d.changes, change)
+}
+
+// Compares from & to, found at path in both documents.
+func (d *differ) diffValues(from any, to any, path string) {
+	switch typedFrom := from.(type) {
+	case map[string]any:
+		if typedTo, ok := to.(map[string]any); ok {
+			d.diffObjects(typedFrom, typedTo, path)
+			return
+		}
+	case []any:
+		if typedTo, ok := to.([]any); ok {
+			d.diffArrays(typedFrom, typedTo, path)
+			return
+		}
+	}
+
+	equalOptions := EqualOptions{NumericEquivalence: true, FloatTolerance: d.options.FloatTolerance}
+	if !valuesEqual(from, to, equalOptions) {
+		d.addChange(DiffChanged, path, from, to)
+	}
+}
+
+func (d *differ) diffObjects(from map[string]any, to map[string]any, path string) {
+	// Sorted so changes come out in the same order every time
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		keyPath := appendPointerToken(path, key)
+		fromVal, inFrom := from[key]
+		toVal, inTo := to[key]
+		switch {
+		case !inTo:
+			d.addChange(DiffRemoved, keyPath, fromVal, nil)
+		case !inFrom:
+			d.addChange(DiffAdded, keyPath, nil, toVal)
+		default:
+			d.diffValues(fromVal, toVal, keyPath)
+		}
+	}
+}
+
+func (d *differ) diffArrays(from []any, to []any, path string) {
+	for i := 0; i < min(len(from), len(to)); i++ {
+		d.diffValues(from[i], to[i], appendPointerIndex(path, i))
+	}
+	for i := len(from); i < len(to); i++ {
+		d.addChange(DiffAdded, appendPointerIndex(path, i), nil, to[i])
+	}
+	// Last first, so applying the removes in order doesn't shift the indexes still to remove
+	for i := len(from) - 1; i >= len(to); i-- {
+		d.addChange(DiffRemoved, appendPointerIndex(path, i), from[i], nil)
+	}
+}
+
+// Returns the value as compact JSON for printing a change.
+func diffValueString(value *JsonValue) string {
+	valueJson, err := value.Serialize()
+	if err != nil {
+		return fmt.Sprintf("%v", value.tree())
+	}
+	return string(valueJson)
+}
@@ -0,0 +1,92 @@
+package jsonParser
+
+/*
+	Tests diffing parsed documents.
+*/
+
+import (
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func diffWithStr(t *testing.T, fromStr string, toStr string, options DiffOptions) []*DiffChange {
+	from, err := ParseJson(fromStr)
+	assert.Nil(t, err, "Expected from document to parse")
+	to, err := ParseJson(toStr)
+	assert.Nil(t, err, "Expected to document to parse")
+	return Diff(from, to, options)
+}
+
+func diffStrings(changes []*DiffChange) []string {
+	result := make([]string, len(changes))
+	for i, change := range changes {
+		result[i] = change.String()
+	}
+	return result
+}
+
+func TestDiffChanges(t *testing.T) {
+	changes := diffWithStr(t,
+		`{"a": 1, "b": {"c": "x", "d": [1, 2, 3]}, "e/f": true, "g": [1]}`,
+		`{"a": 1.0, "b": {"c": "y", "d": [1, 5]}, "e/f": true, "g": {"h": null}, "i": [2]}`,
+		DiffOptions{})
+
+	expected := []string{
+		`~ /b/c: "x" -> "y"`,
+		`~ /b/d/1: 2 -> 5`,
+		`- /b/d/2: 3`,
+		`~ /g: [1] -> {"h":null}`,
+		`+ /i: [2]`,
+	}
+	assert.Equal(t, len(changes), len(expected), "Unexpected number of changes")
+	for i, change := range diffStrings(changes) {
+		assert.Equal(t, change, expected[i], "Unexpected change")
+	}
+	assert.Equal(t, changes[0].Kind, DiffChanged, "Unexpected change kind")
+	assert.Equal(t, changes[2].New == nil, true, "Expected no new value for removed change")
+
+	changes = diffWithStr(t, `{"a/b": [1]}`, `{"a/b": [1, 2, 3]}`, DiffOptions{})
+	assert.Equal(t, diffStrings(changes)[0], "+ /a~1b/1: 2", "Expected escaped path")
+	assert.Equal(t, diffStrings(changes)[1], "+ /a~1b/2: 3", "Expected adds in index order")
+
+	changes = diffWithStr(t, `{"a": [1, 2]}`, `{"a": [1, 2]}`, DiffOptions{})
+	assert.Equal(t, len(changes), 0, "Expected no changes for equal documents")
+}
+
+func TestDiffFloatTolerance(t *testing.T) {
+	fromStr := `{"x0": 1.0000001, "x1": 2.5, "n": "1"}`
+	toStr := `{"x0": 1.0000002, "x1": 2.6, "n": 1}`
+
+	changes := diffWithStr(t, fromStr, toStr, DiffOptions{})
+	assert.Equal(t, len(changes), 3, "Expected every value to differ")
+
+	changes = diffWithStr(t, fromStr, toStr, DiffOptions{FloatTolerance: 1e-6})
+	assert.Equal(t, len(changes), 2, "Expected close floats to be equal")
+	assert.Equal(t, changes[0].Path, "/n", "Expected string & number to differ")
+	assert.Equal(t, changes[1].Path, "/x1", "Expected far floats to differ")
+}
+
+func TestDiffToPatch(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+	}{
+		{`{"a": 1, "b": [1, 2, 3, 4], "c": {"d": 1}}`, `{"a": 2, "b": [1], "c": {"e": [1]}}`},
+		{`[1, 2]`, `[3, 2, 1, 0]`},
+		{`{"a": [{"b": 1}, {"b": 2}]}`, `{"a": [{"b": 1, "c": 3}]}`},
+		{`1`, `{"a": 1}`},
+	}
+
+	for _, test := range tests {
+		from, _ := ParseJson(test.from)
+		to, _ := ParseJson(test.to)
+		patch := DiffToPatch(Diff(from, to, DiffOptions{}))
+
+		patched, err := from.ApplyPatch(patch)
+		assert.Nil(t, err, "Expected diff patch to apply")
+		if err == nil {
+			assert.Equal(t, serializeToStr(t, patched), serializeToStr(t, to), "Expected patch to turn from into to for "+test.from)
+		}
+	}
+}
@@ -0,0 +1,450 @@
+package jsonParser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+/*
+	Equal compares 2 values deeply. Objects are equal if they have the same keys with equal
+	values, in any order. Set EqualOptions.KeyOrderSensitive to also require the same key order.
+	Arrays are equal if they have equal items in the same order.
+
+	```
+	jsonParser.Equal(a, b) // 1 & 1.0 are equal
+
+	options := jsonParser.DefaultEqualOptions()
+	options.FloatTolerance = 1e-9
+	jsonParser.EqualWithOptions(a, b, options) // 0.1+0.2 & 0.3 are equal
+	```
+
+	Hash() returns a hash that's the same for values that are Equal() with the default options,
+	& stays the same between runs, so it can be used to dedupe values or store them in sets.
+	Values that are only equal because of a float tolerance can hash differently.
+
+	Also has helpers for comparing & copying parsed values, shared by schema validation,
+	patching & diffing.
+*/
+
+type EqualOptions struct {
+	// Numbers are equal if their values are, whatever type they were parsed as: 1 (int), 1.0
+	// (float64) & Number("1e0") are all equal. Otherwise they must have the same type too, &
+	// Numbers must have the same literal.
+	//
+	// Values are compared exactly, so ints & Numbers aren't rounded to float64s: 2^53 & 2^53+1
+	// differ, as do Numbers that only differ in their 30th digit. A float64 counts as the
+	// shortest decimal that parses back to it (what Serialize() writes), so 0.1 equals
+	// Number("0.1").
+	NumericEquivalence bool
+
+	// Max absolute difference between 2 numbers for them to still count as equal.
+	FloatTolerance float64
+
+	// Objects must have their keys in the same order. Parsed objects keep their document order,
+	// except that a duplicate key is where its last occurrence was. Objects from NewJsonValue()
+	// are in map order, which is random, so don't use this with them.
+	KeyOrderSensitive bool
+}
+
+// Returns the options Equal() uses, with numeric equivalence on.
+func DefaultEqualOptions() EqualOptions {
+	return EqualOptions{
+		NumericEquivalence: true,
+	}
+}
+
+// Returns true if a & b hold the same JSON value, using DefaultEqualOptions().
+func Equal(a *JsonValue, b *JsonValue) bool {
+	return tapeValuesEqual(a.tape, a.index, b.tape, b.index, DefaultEqualOptions())
+}
+
+// Returns true if a & b hold the same JSON value, comparing numbers as options say.
+func EqualWithOptions(a *JsonValue, b *JsonValue, options EqualOptions) bool {
+	return tapeValuesEqual(a.tape, a.index, b.tape, b.index, options)
+}
+
+// Returns a hash of the value that's stable between runs. Values that are Equal() have the same
+// hash.
+func (j *JsonValue) Hash() uint64 {
+	h := fnv.New64a()
+	var buf []byte
+	hashTapeValue(h, &buf, j.tape, j.index)
+	return h.Sum64()
+}
+
+// Returns true if the value at a on tape ta is the same JSON value as the 1 at b on tb, like
+// valuesEqual() for Go values. Walks the tapes, so nothing is boxed or copied.
+func tapeValuesEqual(ta *tape, a int, tb *tape, b int, options EqualOptions) bool {
+	nodeA, nodeB := ta.nodes[a], tb.nodes[b]
+	switch nodeA.kind {
+	case tapeObject:
+		// Duplicate keys are removed when parsing, so same size & every key found means equal
+		if nodeB.kind != tapeObject || nodeA.payload != nodeB.payload {
+			return false
+		}
+		keyB := b + 1
+		for keyA := a + 1; keyA < ta.next(a); keyA = ta.next(keyA + 1) {
+			// Keys are usually in the same order, so check the same position before searching
+			key := ta.str(ta.nodes[keyA])
+			valueB := keyB + 1
+			if tb.str(tb.nodes[keyB]) != key {
+				if options.KeyOrderSensitive {
+					return false
+				}
+				if valueB = tb.objectLookup(b, key); valueB < 0 {
+					return false
+				}
+			}
+			if !tapeValuesEqual(ta, keyA+1, tb, valueB, options) {
+				return false
+			}
+			keyB = tb.next(keyB + 1)
+		}
+		return true
+	case tapeArray:
+		if nodeB.kind != tapeArray || nodeA.payload != nodeB.payload {
+			return false
+		}
+		itemB := b + 1
+		for itemA := a + 1; itemA < ta.next(a); itemA = ta.next(itemA) {
+			if !tapeValuesEqual(ta, itemA, tb, itemB, options) {
+				return false
+			}
+			itemB = tb.next(itemB)
+		}
+		return true
+	case tapeInt, tapeFloat, tapeNumber:
+		return tapeNumbersEqual(ta, nodeA, tb, nodeB, options)
+	case tapeString:
+		return nodeB.kind == tapeString && ta.str(nodeA) == tb.str(nodeB)
+	case tapeInvalid:
+		return nodeB.kind == tapeInvalid && otherValuesEqual(ta.invalid[nodeA.payload], tb.invalid[nodeB.payload])
+	}
+	return nodeA.kind == nodeB.kind
+}
+
+// Returns true if number node a equals node b, which may not be a number, like numbersEqual().
+func tapeNumbersEqual(ta *tape, a tapeNode, tb *tape, b tapeNode, options EqualOptions) bool {
+	if b.kind != tapeInt && b.kind != tapeFloat && b.kind != tapeNumber {
+		return false
+	}
+	return numberRefsEqual(ta.numberRef(a), tb.numberRef(b), options)
+}
+
+// A number from a tape node or Go value, in the type it was parsed as.
+type numberRef struct {
+	kind tapeKind // tapeInt, tapeFloat or tapeNumber
+	i    int
+	f    float64
+	s    string // Literal of a Number
+}
+
+// Returns the number ref for a number node.
+func (t *tape) numberRef(n tapeNode) numberRef {
+	switch n.kind {
+	case tapeInt:
+		return numberRef{kind: tapeInt, i: n.int()}
+	case tapeFloat:
+		return numberRef{kind: tapeFloat, f: n.float()}
+	}
+	return numberRef{kind: tapeNumber, s: t.str(n)}
+}
+
+// Returns the number ref for val, or false if val isn't a number.
+func goNumberRef(val any) (numberRef, bool) {
+	switch typedVal := val.(type) {
+	case int:
+		return numberRef{kind: tapeInt, i: typedVal}, true
+	case float64:
+		return numberRef{kind: tapeFloat, f: typedVal}, true
+	case Number:
+		return numberRef{kind: tapeNumber, s: string(typedVal)}, true
+	}
+	return numberRef{}, false
+}
+
+// Returns the number as decimal text, formatting ints & floats into buf. Floats are the shortest
+// decimal that parses back to them.
+func (r numberRef) text(buf *[32]byte) string {
+	var b []byte
+	switch r.kind {
+	case tapeInt:
+		b = strconv.AppendInt(buf[:0], int64(r.i), 10)
+	case tapeFloat:
+		b = strconv.AppendFloat(buf[:0], r.f, 'g', -1, 64)
+	default:
+		return r.s
+	}
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// Returns the number as a float64, or false if it's a Number too big for 1.
+func (r numberRef) float64() (float64, bool) {
+	switch r.kind {
+	case tapeInt:
+		return float64(r.i), true
+	case tapeFloat:
+		return r.f, true
+	}
+	floatVal, err := Number(r.s).Float64()
+	return floatVal, err == nil
+}
+
+// Returns true if number a equals b, comparing as options say.
+func numberRefsEqual(a numberRef, b numberRef, options EqualOptions) bool {
+	if !options.NumericEquivalence && a.kind != b.kind {
+		return false
+	}
+	// Without numeric equivalence, Numbers are the same if their literals are
+	if !options.NumericEquivalence && options.FloatTolerance == 0 && a.kind == tapeNumber {
+		return a.s == b.s
+	}
+	if numbersExactlyEqual(a, b) {
+		return true
+	}
+	if options.FloatTolerance == 0 {
+		return false
+	}
+
+	aFloat, aOk := a.float64()
+	bFloat, bOk := b.float64()
+	return aOk && bOk && math.Abs(aFloat-bFloat) <= options.FloatTolerance
+}
+
+// Ints up to this size either way are stored exactly in a float64.
+const MAX_EXACT_FLOAT_INT = 1 << 53
+
+// Returns true if a & b are exactly the same value, with floats as their shortest decimals.
+func numbersExactlyEqual(a numberRef, b numberRef) bool {
+	switch {
+	case a.kind == tapeInt && b.kind == tapeInt:
+		return a.i == b.i
+	case a.kind == tapeFloat && b.kind == tapeFloat:
+		return a.f == b.f
+	case a.kind == tapeNumber && b.kind == tapeNumber && a.s == b.s:
+		return true
+	case a.kind == tapeInt && b.kind == tapeFloat && a.i >= -MAX_EXACT_FLOAT_INT && a.i <= MAX_EXACT_FLOAT_INT:
+		// A float64 holding 1 of these ints has the int as its shortest decimal
+		return float64(a.i) == b.f
+	case a.kind == tapeFloat && b.kind == tapeInt:
+		return numbersExactlyEqual(b, a)
+	}
+
+	var bufA, bufB [32]byte
+	aDecimal, aOk := parseDecimalParts(a.text(&bufA))
+	bDecimal, bOk := parseDecimalParts(b.text(&bufB))
+	return aOk && bOk && aDecimal.equal(bDecimal)
+}
+
+// Returns the value of a number as a float64, or false if val isn't a number.
+func jsonNumberValue(val any) (float64, bool) {
+	switch typedVal := val.(type) {
+	case int:
+		return float64(typedVal), true
+	case float64:
+		return typedVal, true
+	case Number:
+		floatVal, err := typedVal.Float64()
+		return floatVal, err == nil
+	}
+	return 0, false
+}
+
+// Returns the JSON Schema type name for val, like "object". Numbers are always "number".
+func jsonTypeName(val any) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case int, float64, Number:
+		return "number"
+	}
+	return fmt.Sprintf("%T", val)
+}
+
+// Returns true if a & b are the same JSON value, using DefaultEqualOptions().
+func jsonValuesEqual(a any, b any) bool {
+	return valuesEqual(a, b, DefaultEqualOptions())
+}
+
+// Returns true if a & b are the same JSON value, comparing numbers as options say.
+func valuesEqual(a any, b any, options EqualOptions) bool {
+	switch typedA := a.(type) {
+	case map[string]any:
+		typedB, ok := b.(map[string]any)
+		if !ok || len(typedA) != len(typedB) {
+			return false
+		}
+		for key, aVal := range typedA {
+			bVal, ok := typedB[key]
+			if !ok || !valuesEqual(aVal, bVal, options) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		typedB, ok := b.([]any)
+		if !ok || len(typedA) != len(typedB) {
+			return false
+		}
+		for i := range typedA {
+			if !valuesEqual(typedA[i], typedB[i], options) {
+				return false
+			}
+		}
+		return true
+	case int, float64, Number:
+		return numbersEqual(a, b, options)
+	}
+	return otherValuesEqual(a, b)
+}
+
+// Returns true if a & b are equal, for nil, bools, strings & Go values that aren't JSON (like a
+// []int given to NewJsonValue()). Values that can't be compared with == are compared with
+// reflect.DeepEqual() instead.
+func otherValuesEqual(a any, b any) bool {
+	if reflect.ValueOf(a).Comparable() {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Returns true if number a equals b, which may not be a number.
+func numbersEqual(a any, b any, options EqualOptions) bool {
+	aRef, aOk := goNumberRef(a)
+	bRef, bOk := goNumberRef(b)
+	return aOk && bOk && numberRefsEqual(aRef, bRef, options)
+}
+
+// Returns a deep copy of val, so changing the copy leaves val untouched. With ownStrings, strings
+// & keys are copied too, so the copy doesn't point into a memory mapped file (see
+// fileMapping.go).
+func cloneJsonValue(val any, ownStrings bool) any {
+	switch typedVal := val.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(typedVal))
+		for key, childVal := range typedVal {
+			if ownStrings {
+				key = strings.Clone(key)
+			}
+			result[key] = cloneJsonValue(childVal, ownStrings)
+		}
+		return result
+	case []any:
+		result := make([]any, len(typedVal))
+		for i, childVal := range typedVal {
+			result[i] = cloneJsonValue(childVal, ownStrings)
+		}
+		return result
+	case string:
+		if ownStrings {
+			return strings.Clone(typedVal)
+		}
+	case Number:
+		if ownStrings {
+			return Number(strings.Clone(string(typedVal)))
+		}
+	}
+	return val
+}
+
+// Type tags written before each value when hashing, so different types with the same bytes
+// (like "1" & 1) hash differently.
+const (
+	HASH_TAG_NULL byte = iota
+	HASH_TAG_FALSE
+	HASH_TAG_TRUE
+	HASH_TAG_NUMBER
+	HASH_TAG_BIG_NUMBER
+	HASH_TAG_STRING
+	HASH_TAG_ARRAY
+	HASH_TAG_OBJECT
+)
+
+// Writes the value at index to h in a form that's the same for equal values. buf is scratch
+// space reused between writes.
+func hashTapeValue(h hash.Hash64, buf *[]byte, t *tape, index int) {
+	b := (*buf)[:0]
+
+	n := t.nodes[index]
+	switch n.kind {
+	case tapeNull:
+		b = append(b, HASH_TAG_NULL)
+	case tapeFalse:
+		b = append(b, HASH_TAG_FALSE)
+	case tapeTrue:
+		b = append(b, HASH_TAG_TRUE)
+	case tapeInt, tapeFloat, tapeNumber:
+		// Equal numbers have the same decimal parts, whatever type they are, so hash those
+		var textBuf [32]byte
+		text := t.numberRef(n).text(&textBuf)
+		if decimal, ok := parseDecimalParts(text); ok {
+			b = append(b, HASH_TAG_NUMBER)
+			sign := byte('+')
+			if decimal.neg {
+				sign = '-'
+			}
+			b = append(b, sign)
+			b = binary.LittleEndian.AppendUint64(b, uint64(decimal.point))
+			b = decimal.appendDigits(b)
+		} else {
+			// Only equal to the same literal, like a NaN or a Number with a huge exponent
+			b = append(b, HASH_TAG_BIG_NUMBER)
+			b = appendHashString(b, text)
+		}
+	case tapeString:
+		b = append(b, HASH_TAG_STRING)
+		b = appendHashString(b, t.str(n))
+	case tapeArray:
+		b = append(b, HASH_TAG_ARRAY)
+		b = binary.LittleEndian.AppendUint64(b, n.payload)
+		h.Write(b)
+		*buf = b
+		for item := index + 1; item < t.next(index); item = t.next(item) {
+			hashTapeValue(h, buf, t, item)
+		}
+		return
+	case tapeObject:
+		b = append(b, HASH_TAG_OBJECT)
+		b = binary.LittleEndian.AppendUint64(b, n.payload)
+		h.Write(b)
+		*buf = b
+
+		// Sorted, since equal objects can have their keys in any order
+		var keysBuf [SERIALIZER_STACK_KEYS]int
+		keys := t.sortedKeys(index, keysBuf[:0], strings.Compare)
+		for _, key := range keys {
+			*buf = appendHashString((*buf)[:0], t.str(t.nodes[key]))
+			h.Write(*buf)
+			hashTapeValue(h, buf, t, key+1)
+		}
+		return
+	default:
+		// Only the type, since values that are equal can print differently (like pointers to
+		// equal values)
+		b = fmt.Appendf(b, "%T", t.invalid[n.payload])
+	}
+
+	h.Write(b)
+	*buf = b
+}
+
+// Appends s with its length first, so strings next to each other can't run together.
+func appendHashString(b []byte, s string) []byte {
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(s)))
+	return append(b, s...)
+}
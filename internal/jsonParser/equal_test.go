@@ -0,0 +1,173 @@
+package jsonParser
+
+/*
+	Tests Equal(), EqualWithOptions() & Hash().
+*/
+
+import (
+	"fmt"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func parseEqualTestJson(t *testing.T, s string, useNumber bool) *JsonValue {
+	value, err := ParseJsonWithOptions(s, ParseOptions{UseNumber: useNumber})
+	assert.Nil(t, err, "Expected JSON to parse")
+	return value
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{`{"a": 1, "b": [true, null, "x"]}`, `{"b": [true, null, "x"], "a": 1}`, true},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, false},
+		{`{"a": 1}`, `{"b": 1}`, false},
+		{`[1, 2]`, `[2, 1]`, false},
+		{`[1, 2]`, `[1, 2, 3]`, false},
+		{`1`, `1.0`, true},
+		{`0`, `-0.0`, true},
+		{`1`, `"1"`, false},
+		{`null`, `false`, false},
+		{`"a"`, `"a"`, true},
+		{`{}`, `[]`, false},
+	}
+
+	for _, test := range tests {
+		a := parseEqualTestJson(t, test.a, false)
+		b := parseEqualTestJson(t, test.b, false)
+		assert.Equal(t, Equal(a, b), test.expected, fmt.Sprintf("Expected Equal(%s, %s) to be %v", test.a, test.b, test.expected))
+		assert.Equal(t, Equal(b, a), test.expected, fmt.Sprintf("Expected Equal(%s, %s) to be %v", test.b, test.a, test.expected))
+	}
+}
+
+func TestEqualNumbers(t *testing.T) {
+	// Numbers are equal by value whatever mode they were parsed in
+	assert.Equal(t, Equal(parseEqualTestJson(t, `[1e0, 10]`, true), parseEqualTestJson(t, `[1, 1e1]`, false)), true, "Expected Number & int to be equal")
+
+	// Numbers too big for a float64 are still compared by value
+	big := `1e400`
+	assert.Equal(t, Equal(parseEqualTestJson(t, big, true), parseEqualTestJson(t, big, true)), true, "Expected same big Numbers to be equal")
+	assert.Equal(t, Equal(parseEqualTestJson(t, big, true), parseEqualTestJson(t, `10.0e399`, true)), true, "Expected big Numbers with the same value to be equal")
+	assert.Equal(t, Equal(parseEqualTestJson(t, big, true), parseEqualTestJson(t, `1e401`, true)), false, "Expected different big Numbers to not be equal")
+
+	// Ints & Numbers aren't rounded to float64s
+	tests := []struct {
+		a         string
+		aIsNumber bool
+		b         string
+		bIsNumber bool
+		expected  bool
+	}{
+		{`9007199254740992`, false, `9007199254740993`, false, false},
+		{`9007199254740993`, false, `9007199254740993`, true, true},
+		{`9007199254740993`, false, `9007199254740992.0`, false, false},
+		{`9007199254740992`, false, `9007199254740992.0`, false, true},
+		{`123456789012345678901234567890`, true, `123456789012345678901234567891`, true, false},
+		{`123456789012345678901234567890`, true, `1.2345678901234567890123456789e29`, true, true},
+		{`-0`, true, `0.0e5`, true, true},
+		{`0.1`, false, `0.1`, true, true},
+		{`0.1`, false, `0.10000000000000001`, true, false},
+		{`1e18`, false, `1000000000000000000`, false, true},
+	}
+	for _, test := range tests {
+		a := parseEqualTestJson(t, test.a, test.aIsNumber)
+		b := parseEqualTestJson(t, test.b, test.bIsNumber)
+		assert.Equal(t, Equal(a, b), test.expected, fmt.Sprintf("Expected Equal(%s, %s) to be %v", test.a, test.b, test.expected))
+		assert.Equal(t, Equal(b, a), test.expected, fmt.Sprintf("Expected Equal(%s, %s) to be %v", test.b, test.a, test.expected))
+		assert.Equal(t, a.Hash() == b.Hash(), test.expected, fmt.Sprintf("Expected %s & %s to hash the same only if they're equal", test.a, test.b))
+
+		// The Go value path used by diff & patch agrees
+		assert.Equal(t, jsonValuesEqual(a.tree(), b.tree()), test.expected, fmt.Sprintf("Expected jsonValuesEqual(%s, %s) to be %v", test.a, test.b, test.expected))
+	}
+}
+
+func TestEqualOtherGoValues(t *testing.T) {
+	// Values that aren't JSON types, some of which can't be compared with ==
+	a := NewJsonValue([]any{[]int{1}, map[string]int{"a": 1}, struct{ x any }{[]int{2}}})
+	b := NewJsonValue([]any{[]int{1}, map[string]int{"a": 1}, struct{ x any }{[]int{2}}})
+	c := NewJsonValue([]any{[]int{2}, map[string]int{"a": 1}, struct{ x any }{[]int{2}}})
+	assert.Equal(t, Equal(a, b), true, "Expected equal Go values to be equal")
+	assert.Equal(t, Equal(a, c), false, "Expected different Go values to differ")
+	assert.Equal(t, a.Hash(), b.Hash(), "Expected equal Go values to hash the same")
+	assert.Equal(t, jsonValuesEqual(a.tree(), b.tree()), true, "Expected equal Go values to be equal as Go data")
+	assert.Equal(t, jsonValuesEqual(a.tree(), c.tree()), false, "Expected different Go values to differ as Go data")
+
+	// Comparable values are still compared with ==
+	assert.Equal(t, Equal(NewJsonValue(uint8(1)), NewJsonValue(uint8(1))), true, "Expected equal uint8s to be equal")
+	assert.Equal(t, Equal(NewJsonValue(uint8(1)), NewJsonValue(uint16(1))), false, "Expected different types to differ")
+}
+
+func TestEqualWithOptions(t *testing.T) {
+	strict := EqualOptions{}
+	assert.Equal(t, EqualWithOptions(parseEqualTestJson(t, `1`, false), parseEqualTestJson(t, `1.0`, false), strict), false, "Expected int & float to differ without numeric equivalence")
+	assert.Equal(t, EqualWithOptions(parseEqualTestJson(t, `1.5`, false), parseEqualTestJson(t, `1.50`, false), strict), true, "Expected floats with the same value to be equal")
+	assert.Equal(t, EqualWithOptions(parseEqualTestJson(t, `1.5`, true), parseEqualTestJson(t, `1.50`, true), strict), false, "Expected Numbers with different literals to differ")
+
+	tolerant := DefaultEqualOptions()
+	tolerant.FloatTolerance = 1e-9
+	a := parseEqualTestJson(t, `{"x": [0.30000000000000004]}`, false)
+	b := parseEqualTestJson(t, `{"x": [0.3]}`, false)
+	assert.Equal(t, Equal(a, b), false, "Expected floats to differ without a tolerance")
+	assert.Equal(t, EqualWithOptions(a, b, tolerant), true, "Expected floats to be equal within the tolerance")
+	assert.Equal(t, EqualWithOptions(a, parseEqualTestJson(t, `{"x": [0.31]}`, false), tolerant), false, "Expected floats past the tolerance to differ")
+}
+
+func TestEqualKeyOrder(t *testing.T) {
+	ordered := DefaultEqualOptions()
+	ordered.KeyOrderSensitive = true
+	tests := []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{`{"a": 1, "b": 2}`, `{"a": 1.0, "b": 2}`, true},
+		{`{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, false},
+		{`[{"x": {"c": 1, "d": 2}}]`, `[{"x": {"d": 2, "c": 1}}]`, false},
+		{`{"a": 1, "b": 2}`, `{"a": 1, "b": 2, "c": 3}`, false},
+		// A duplicate key is where its last occurrence was
+		{`{"a": 1, "b": 2, "a": 3}`, `{"b": 2, "a": 3}`, true},
+	}
+
+	for _, test := range tests {
+		a := parseEqualTestJson(t, test.a, false)
+		b := parseEqualTestJson(t, test.b, false)
+		assert.Equal(t, EqualWithOptions(a, b, ordered), test.expected, fmt.Sprintf("Expected ordered EqualWithOptions(%s, %s) to be %v", test.a, test.b, test.expected))
+		assert.Equal(t, EqualWithOptions(b, a, ordered), test.expected, fmt.Sprintf("Expected ordered EqualWithOptions(%s, %s) to be %v", test.b, test.a, test.expected))
+		// Key order is ignored by default
+		if !test.expected && len(test.a) == len(test.b) {
+			assert.Equal(t, Equal(a, b), true, fmt.Sprintf("Expected Equal(%s, %s) to ignore key order", test.a, test.b))
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	// Equal values hash the same
+	equalPairs := [][2]string{
+		{`{"a": 1, "b": [true, null, "x"]}`, `{"b": [true, null, "x"], "a": 1.0}`},
+		{`0`, `-0`},
+		{`[]`, `[ ]`},
+	}
+	for _, pair := range equalPairs {
+		a := parseEqualTestJson(t, pair[0], false)
+		b := parseEqualTestJson(t, pair[1], true)
+		assert.Equal(t, a.Hash(), b.Hash(), fmt.Sprintf("Expected %s & %s to hash the same", pair[0], pair[1]))
+	}
+
+	// Different values almost always hash differently
+	values := []string{`null`, `false`, `true`, `0`, `1`, `"1"`, `""`, `[]`, `{}`, `[[]]`, `[null]`,
+		`{"a": "b"}`, `{"ab": ""}`, `["a", "b"]`, `["ab"]`, `["a", ["b"]]`, `[["a"], "b"]`}
+	seen := map[uint64]string{}
+	for _, s := range values {
+		hash := parseEqualTestJson(t, s, false).Hash()
+		other, ok := seen[hash]
+		assert.Equal(t, ok, false, fmt.Sprintf("Expected %s to hash differently from %s", s, other))
+		seen[hash] = s
+	}
+
+	// The same every run
+	assert.Equal(t, parseEqualTestJson(t, `{"a": [1, "b"]}`, false).Hash(), parseEqualTestJson(t, `{"a": [1, "b"]}`, false).Hash(), "Expected hash to be stable")
+}
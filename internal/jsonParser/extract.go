@@ -0,0 +1,363 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"tmelot.jsonparser/internal/profiler"
+)
+
+/*
+	ExtractPaths gets a few values out of a big document without parsing all of it. It takes the
+	paths up front & scans the input once, only parsing the values at those paths. Everything
+	else is skipped with the value scanner (see valueScanner.go), which just balances brackets
+	& steps over strings, so it's much cheaper than ParseJson() followed by navigation.
+
+	```
+	values, err := jsonParser.ExtractPaths(fileData, []string{"/pairs/0/x0", "meta.count"})
+	x0, err := values["/pairs/0/x0"].GetFloat("")
+	```
+
+	Paths are JSON pointers (see jsonPointer.go) or dotted paths like "pairs.0.x0". Pointers can
+	name keys with "." or "/" in them, dotted paths can't. Results are keyed by the path as given.
+	Paths that aren't in the document are missing from the results.
+
+	With duplicate keys, the last is used, like ParseJson(). So finding every path only stops the
+	scan once no object is open around them, since a later key in 1 could replace a value.
+
+	Skipped values aren't validated, beyond their brackets balancing. So unlike ParseJson(),
+	invalid JSON outside the requested values may not be an error, & invalid JSON after the scan
+	stops never is. Requested values are parsed with ParseJsonWithOptions(), so they're fully
+	checked.
+*/
+
+// Returns the values at the given JSON pointer or dotted paths, parsed with the default options.
+func ExtractPaths(data string, paths []string) (map[string]*JsonValue, error) {
+	return ExtractPathsWithOptions(data, paths, DefaultParseOptions())
+}
+
+// Returns the values at the given JSON pointer or dotted paths, parsed with the given options.
+// Relaxed mode isn't supported, since skipping doesn't handle comments.
+func ExtractPathsWithOptions(data string, paths []string, options ParseOptions) (map[string]*JsonValue, error) {
+	if options.Relaxed {
+		return nil, errors.New("ExtractPaths doesn't support relaxed mode")
+	}
+
+	if options.MaxDocumentSize > 0 && len(data) > options.MaxDocumentSize {
+		return nil, newParseError(data, options.MaxDocumentSize, ErrMaxDocumentSizeExceeded)
+	}
+
+	profiler.GlobalProfiler.StartBlock("ExtractPaths")
+	defer profiler.GlobalProfiler.EndBlock("ExtractPaths")
+
+	e := &extractor{
+		data:    data,
+		options: options,
+		results: make(map[string]*JsonValue, len(paths)),
+		root:    &extractNode{},
+	}
+	e.matchValue = e.parseMatch
+	for _, path := range paths {
+		if err := e.addPath(path); err != nil {
+			return nil, err
+		}
+	}
+
+	start, err := e.findStart()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := e.extractValue(e.root, start); err != nil {
+		return nil, err
+	}
+	return e.results, nil
+}
+
+// Returns the index of the first value in data, which must have 1.
+func (e *extractor) findStart() (int, error) {
+	start := e.skipWhitespace(0)
+	if start >= len(e.data) {
+		return start, newParseError(e.data, start, errors.New("Expected value, found end of string instead"))
+	}
+	return start, nil
+}
+
+// A node in the tree of requested paths. Each level is an object key or array index.
+type extractNode struct {
+	tokens   []string // Pointer tokens from the root to here
+	paths    []string // Requested paths ending here, as given
+	children map[string]*extractNode
+	indexes  map[int]*extractNode // Children that can be array indexes, so items don't need an Itoa()
+	found    bool                 // The value here has been found, & is in results if it has paths
+}
+
+type extractor struct {
+	data      string
+	options   ParseOptions
+	results   map[string]*JsonValue
+	root      *extractNode
+	remaining int // Nodes with paths that haven't been found yet
+	objects   int // Objects open around the current value
+
+	// Handles a requested value starting at start, returning the index past its end. Parses it
+	// into results by default, see parseMatch().
+	matchValue func(node *extractNode, start int) (int, error)
+}
+
+// Adds a JSON pointer or dotted path to the tree of paths to extract.
+func (e *extractor) addPath(path string) error {
+	var tokens []string
+	if path == "" || path[0] == '/' {
+		var err error
+		if tokens, err = parsePointer(path); err != nil {
+			return err
+		}
+	} else {
+		tokens = strings.Split(path, ".")
+	}
+
+	node := e.root
+	for i, token := range tokens {
+		child, ok := node.children[token]
+		if !ok {
+			if node.children == nil {
+				node.children = map[string]*extractNode{}
+			}
+			child = &extractNode{tokens: tokens[:i+1]}
+			node.children[token] = child
+			if index, ok := parsePointerIndex(token); ok {
+				if node.indexes == nil {
+					node.indexes = map[int]*extractNode{}
+				}
+				node.indexes[index] = child
+			}
+		}
+		node = child
+	}
+
+	if len(node.paths) == 0 {
+		e.remaining += 1
+	}
+	node.paths = append(node.paths, path)
+	return nil
+}
+
+// Extracts any requested values from the value starting at start, which isn't whitespace, &
+// returns the index past its end. Returns early once every path is found, unless it's inside an
+// object.
+func (e *extractor) extractValue(node *extractNode, start int) (int, error) {
+	if len(node.paths) > 0 {
+		return e.matchValue(node, start)
+	}
+
+	switch e.data[start] {
+	case '{':
+		e.objects += 1
+		end, err := e.extractObject(node, start)
+		e.objects -= 1
+		return end, err
+	case '[':
+		return e.extractArray(node, start)
+	}
+	// A scalar can't hold anything requested
+	return e.skipValue(start)
+}
+
+// Parses the requested value starting at start, along with any requested values inside it.
+func (e *extractor) parseMatch(node *extractNode, start int) (int, error) {
+	end, err := e.skipValue(start)
+	if err != nil {
+		return end, err
+	}
+	value, err := ParseJsonWithOptions(e.data[start:end], e.options)
+	if err != nil {
+		return end, e.relocateError(err, start)
+	}
+
+	e.addResult(node, value)
+	// Values inside this 1 are looked up in it, rather than scanned again
+	var addDescendants func(parent *extractNode)
+	addDescendants = func(parent *extractNode) {
+		for _, child := range parent.children {
+			if len(child.paths) > 0 {
+				if childIndex, ok := value.tape.lookupPointer(value.index, child.tokens[len(node.tokens):]); ok {
+					e.addResult(child, value.at(childIndex))
+				}
+			}
+			addDescendants(child)
+		}
+	}
+	addDescendants(node)
+	return end, nil
+}
+
+func (e *extractor) addResult(node *extractNode, val *JsonValue) {
+	for _, path := range node.paths {
+		e.results[path] = val
+	}
+	e.markFound(node)
+}
+
+// Counts node as found. A duplicate key finds it again, so it's only counted the first time.
+func (e *extractor) markFound(node *extractNode) {
+	if !node.found {
+		node.found = true
+		e.remaining -= 1
+	}
+}
+
+// Forgets the values found at & under node, since a duplicate key replaces them.
+func (e *extractor) resetFound(node *extractNode) {
+	if node.found {
+		for _, path := range node.paths {
+			delete(e.results, path)
+		}
+		node.found = false
+		e.remaining += 1
+	}
+	for _, child := range node.children {
+		e.resetFound(child)
+	}
+}
+
+func (e *extractor) extractObject(node *extractNode, start int) (int, error) {
+	i := e.skipWhitespace(start + 1)
+	if i < len(e.data) && e.data[i] == '}' {
+		return i + 1, nil
+	}
+
+	for {
+		// Key
+		if i >= len(e.data) || e.data[i] != '"' {
+			return i, e.syntaxError(i, "Expected key string")
+		}
+		keyEnd, complete := scanStringEnd(e.data, i)
+		if !complete {
+			return i, newParseError(e.data, i, errors.New("End quote for string not found"))
+		}
+		key, err := e.decodeKey(i, keyEnd)
+		if err != nil {
+			return i, err
+		}
+
+		// ":"
+		i = e.skipWhitespace(keyEnd)
+		if i >= len(e.data) || e.data[i] != ':' {
+			return i, e.syntaxError(i, fmt.Sprintf("Expected field assignment \"%s\"", JSON_SYNTAX_COLON))
+		}
+		i = e.skipWhitespace(i + 1)
+		if i >= len(e.data) {
+			return i, e.syntaxError(i, "Expected value")
+		}
+
+		// Value
+		if child, ok := node.children[key]; ok {
+			e.resetFound(child)
+			i, err = e.extractValue(child, i)
+		} else {
+			i, err = e.skipValue(i)
+		}
+		if err != nil {
+			return i, err
+		}
+
+		// "," or "}"
+		i = e.skipWhitespace(i)
+		if i < len(e.data) && e.data[i] == '}' {
+			return i + 1, nil
+		}
+		if i >= len(e.data) || e.data[i] != ',' {
+			msg := fmt.Sprintf("Expected field separator \"%s\" or close object \"%s\"", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACE)
+			return i, e.syntaxError(i, msg)
+		}
+		i = e.skipWhitespace(i + 1)
+	}
+}
+
+func (e *extractor) extractArray(node *extractNode, start int) (int, error) {
+	i := e.skipWhitespace(start + 1)
+	if i < len(e.data) && e.data[i] == ']' {
+		return i + 1, nil
+	}
+
+	for index := 0; ; index++ {
+		if i >= len(e.data) {
+			return i, e.syntaxError(i, "Expected value")
+		}
+
+		var err error
+		if child, ok := node.indexes[index]; ok {
+			i, err = e.extractValue(child, i)
+		} else {
+			i, err = e.skipValue(i)
+		}
+		if err != nil || (e.remaining == 0 && e.objects == 0) {
+			return i, err
+		}
+
+		// "," or "]"
+		i = e.skipWhitespace(i)
+		if i < len(e.data) && e.data[i] == ']' {
+			return i + 1, nil
+		}
+		if i >= len(e.data) || e.data[i] != ',' {
+			msg := fmt.Sprintf("Expected item separator \"%s\" or close array \"%s\"", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACKET)
+			return i, e.syntaxError(i, msg)
+		}
+		i = e.skipWhitespace(i + 1)
+	}
+}
+
+func (e *extractor) skipWhitespace(i int) int {
+	i, _ = skipValueWhitespace(e.data, i, false, true)
+	return i
+}
+
+// Returns the index past the end of the value starting at start, without parsing it.
+func (e *extractor) skipValue(start int) (int, error) {
+	end, complete := scanValueEnd(e.data, start, false)
+	if !complete {
+		// A number or literal can run to the end of the input, but nothing else can
+		c := e.data[start]
+		if c == '{' || c == '[' || c == '"' {
+			return end, newParseError(e.data, start, errors.New("Value isn't closed before end of string"))
+		}
+	}
+	if c := e.data[start]; c == '}' || c == ']' || c == ',' || c == ':' {
+		return start, e.syntaxError(start, "Expected value")
+	}
+	return end, nil
+}
+
+// Returns the key in the quoted string from start to end, decoding any escapes.
+func (e *extractor) decodeKey(start int, end int) (string, error) {
+	key := e.data[start+1 : end-1]
+	if !strings.Contains(key, "\\") {
+		return key, nil
+	}
+	// Rare, so let the lexer handle it
+	value, err := ParseJsonWithOptions(e.data[start:end], e.options)
+	if err != nil {
+		return "", e.relocateError(err, start)
+	}
+	return value.GetString("")
+}
+
+func (e *extractor) syntaxError(pos int, expected string) error {
+	found := "end of string"
+	if pos < len(e.data) {
+		found = fmt.Sprintf("\"%c\"", e.data[pos])
+	}
+	msg := fmt.Sprintf("%s, found %s instead", expected, found)
+	return newParseError(e.data, pos, errors.New(msg))
+}
+
+// Converts an error from parsing the slice of data starting at start into 1 for the whole data.
+func (e *extractor) relocateError(err error, start int) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return newParseError(e.data, start+parseErr.Offset, parseErr.Err)
+	}
+	return newParseError(e.data, start, err)
+}
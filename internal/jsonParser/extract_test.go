@@ -0,0 +1,147 @@
+package jsonParser
+
+/*
+	Tests ExtractPaths().
+*/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+const EXTRACT_TEST_JSON = `{
+	"skipped": {"a": [1, "]}\"", {"b": "{"}], "c": null},
+	"pairs": [
+		{"x0": 1.5, "y0": -2, "tags": ["a", "b"]},
+		{"x0": 2.5, "y0": 3e2, "name": "second"}
+	],
+	"meta": {"count": 2, "a/b": true, "a.b": "dotted", "k\u00e9y": "escaped"},
+	"last": "value"
+}`
+
+func TestExtractPaths(t *testing.T) {
+	paths := []string{"/pairs/0/x0", "pairs.1.name", "/meta/a~1b", "/meta/a.b", "/meta/kéy", "/last", "/missing", "/pairs/5"}
+	values, err := ExtractPaths(EXTRACT_TEST_JSON, paths)
+	assert.Nil(t, err, "Expected extract to succeed")
+
+	assert.Equal(t, MustGet[float64](values["/pairs/0/x0"], ""), 1.5, "Expected pointer path value")
+	assert.Equal(t, MustGet[string](values["pairs.1.name"], ""), "second", "Expected dotted path value")
+	assert.Equal(t, MustGet[bool](values["/meta/a~1b"], ""), true, "Expected escaped pointer value")
+	assert.Equal(t, MustGet[string](values["/meta/a.b"], ""), "dotted", "Expected pointer to key with a dot")
+	assert.Equal(t, MustGet[string](values["/meta/kéy"], ""), "escaped", "Expected key with escapes to match")
+	assert.Equal(t, MustGet[string](values["/last"], ""), "value", "Expected last value")
+
+	_, ok := values["/missing"]
+	assert.Equal(t, ok, false, "Expected missing key to be left out")
+	_, ok = values["/pairs/5"]
+	assert.Equal(t, ok, false, "Expected missing index to be left out")
+}
+
+func TestExtractPathsNested(t *testing.T) {
+	// A requested value inside another is looked up in it
+	values, err := ExtractPaths(EXTRACT_TEST_JSON, []string{"/pairs/0", "/pairs/0/tags/1", "", "/pairs/1/y0"})
+	assert.Nil(t, err, "Expected extract to succeed")
+
+	assert.Equal(t, values["/pairs/0"].Len(), 3, "Expected whole object")
+	assert.Equal(t, MustGet[string](values["/pairs/0/tags/1"], ""), "b", "Expected value inside requested value")
+	assert.Equal(t, MustGet[float64](values["/pairs/1/y0"], ""), 300.0, "Expected value inside root")
+
+	whole, _ := ParseJson(EXTRACT_TEST_JSON)
+	assert.Equal(t, Equal(values[""], whole), true, "Expected root to match the full parse")
+}
+
+func TestExtractPathsScalarRoot(t *testing.T) {
+	values, err := ExtractPaths(" 42 ", []string{""})
+	assert.Nil(t, err, "Expected extract to succeed")
+	assert.Equal(t, MustGet[int](values[""], ""), 42, "Expected root scalar")
+}
+
+func TestExtractPathsErrors(t *testing.T) {
+	// Requested values are fully parsed
+	_, err := ExtractPaths(`{"a": [1, 2,]}`, []string{"/a"})
+	var parseErr *ParseError
+	assert.Equal(t, errors.As(err, &parseErr), true, "Expected a ParseError for a bad requested value")
+	assert.Equal(t, parseErr.Offset, 12, "Expected error positioned in the whole input")
+
+	// Structure on the way to a value is checked
+	_, err = ExtractPaths(`{"a" 1, "b": 2}`, []string{"/b"})
+	assert.NotNil(t, err, "Expected missing colon error")
+	_, err = ExtractPaths(`{"a": {"b": 1`, []string{"/c"})
+	assert.NotNil(t, err, "Expected unclosed container error")
+	_, err = ExtractPaths(`[1 2]`, []string{"/1"})
+	assert.NotNil(t, err, "Expected missing comma error")
+	_, err = ExtractPaths(`{"a": 1}`, []string{"~bad"})
+	assert.Nil(t, err, "Expected dotted path with a tilde to be allowed")
+	_, err = ExtractPaths(`{"a": 1}`, []string{"/~2"})
+	assert.NotNil(t, err, "Expected invalid pointer error")
+	_, err = ExtractPaths(``, []string{"/a"})
+	assert.NotNil(t, err, "Expected empty input error")
+
+	// Skipped values aren't validated, & scanning stops once everything's found outside objects
+	values, err := ExtractPaths(`{"a": 1, "b": [nonsense]}`, []string{"/a"})
+	assert.Nil(t, err, "Expected skipped content not to be validated")
+	assert.Equal(t, MustGet[int](values["/a"], ""), 1, "Expected value before the bad content")
+	values, err = ExtractPaths(`[{"a": 1}, [nonsense], "`, []string{"/0/a"})
+	assert.Nil(t, err, "Expected content after the last match to be skipped")
+	assert.Equal(t, MustGet[int](values["/0/a"], ""), 1, "Expected value before the bad content")
+
+	// The rest of an object is scanned, since a duplicate key could come later
+	_, err = ExtractPaths(`{"a": 1, "b": 2, "c"`, []string{"/a"})
+	assert.NotNil(t, err, "Expected unfinished object error")
+}
+
+func TestExtractPathsDuplicateKeys(t *testing.T) {
+	cases := []struct {
+		data  string
+		paths []string
+	}{
+		{`{"a": 1, "a": 2}`, []string{"/a"}},
+		{`{"a": {"b": 1}, "c": 0, "a": {"b": 2}}`, []string{"/a/b", "/c"}},
+		{`[{"x": [1, 2], "x": [3]}]`, []string{"/0/x/0", "/0/x/1"}},
+		{`{"a": {"b": 1, "c": 2}, "a": {"c": 3}}`, []string{"/a", "/a/b", "/a/c"}},
+		{`{"a": {"b": {"c": 1}}, "a": {"b": 2}}`, []string{"/a/b/c", "/a/b"}},
+	}
+	for _, c := range cases {
+		whole, err := ParseJson(c.data)
+		assert.Nil(t, err, "Expected document to parse")
+		values, err := ExtractPaths(c.data, c.paths)
+		assert.Nil(t, err, "Expected extract to succeed")
+
+		// The last duplicate is used, like ParseJson()
+		for _, path := range c.paths {
+			expected, expectedErr := Get[*JsonValue](whole, path)
+			actual, ok := values[path]
+			assert.Equal(t, ok, expectedErr == nil, "Expected "+path+" to be found only if it's in the full parse of "+c.data)
+			if ok && expectedErr == nil {
+				assert.Equal(t, Equal(actual, expected), true, "Expected extracted value to match parse at "+path+" in "+c.data)
+			}
+		}
+	}
+}
+
+func TestExtractPathsMatchesParse(t *testing.T) {
+	// Every path in a generated document extracts the same value as navigating a full parse
+	var sb strings.Builder
+	sb.WriteString(`{"pairs": [`)
+	for i := 0; i < 20; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(`{"x0": 1.25, "y0": -3, "s": "\"[{", "n": [[], {}]}`)
+	}
+	sb.WriteString(`]}`)
+	data := sb.String()
+	whole, err := ParseJson(data)
+	assert.Nil(t, err, "Expected document to parse")
+
+	paths := []string{"/pairs/0/x0", "/pairs/7/s", "/pairs/19/n/1", "/pairs/12/y0"}
+	values, err := ExtractPaths(data, paths)
+	assert.Nil(t, err, "Expected extract to succeed")
+	for _, path := range paths {
+		expected, _ := Get[*JsonValue](whole, path)
+		assert.Equal(t, Equal(values[path], expected), true, "Expected extracted value to match parse at "+path)
+	}
+}
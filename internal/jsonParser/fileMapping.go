@@ -0,0 +1,66 @@
+package jsonParser
+
+import (
+	"os"
+	"runtime"
+
+	"tmelot.jsonparser/internal/profiler"
+)
+
+/*
+	ParseFile() parses a file without first reading it into a buffer. On Linux the file is
+	memory mapped (mmap) & parsed in place, so the OS pages it in as the lexer reaches it & there's
+	no copy of the whole file. Other platforms fall back to reading the file into memory.
+
+	```
+	jsonResult, err := jsonParser.ParseFile("pairs.json")
+	```
+
+	Lifetime
+	- Parsed strings point straight into the mapping, so it stays mapped for as long as any value
+	  parsed from it is reachable. Each JsonValue holds the mapping, & a finalizer unmaps it once
+	  the last 1 is garbage collected.
+	- Strings returned by accessors (GetString() etc.) are copied out of the mapping, so they stay
+	  valid after it's unmapped.
+	- The file must not be truncated while values are in use. Reading a page past the new end of
+	  the file crashes with SIGBUS.
+*/
+
+type fileMapping struct {
+	data []byte
+}
+
+// Parses the file at path & returns result.
+func ParseFile(path string) (*JsonValue, error) {
+	return ParseFileWithOptions(path, DefaultParseOptions())
+}
+
+// Parses the file at path using the given options & returns result.
+func ParseFileWithOptions(path string, options ParseOptions) (*JsonValue, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	profiler.GlobalProfiler.StartBandwidth("Parser.MapFile", uint64(fileInfo.Size()))
+	fileData, mapping, err := mapFile(path, int(fileInfo.Size()))
+	profiler.GlobalProfiler.EndBandwidth("Parser.MapFile")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ParseJsonWithOptions(fileData, options)
+	if err != nil {
+		// Error messages are formatted copies, so nothing points into the mapping now
+		if mapping != nil {
+			mapping.unmap()
+		}
+		return nil, err
+	}
+
+	if mapping != nil {
+		result.mapping = mapping
+		runtime.SetFinalizer(mapping, (*fileMapping).unmap)
+	}
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package jsonParser
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Maps the file at path, which is size bytes long, read only. Returns a string view of the
+// mapping along with the mapping itself, which must be unmapped once nothing points into it.
+func mapFile(path string, size int) (string, *fileMapping, error) {
+	// Can't map 0 bytes
+	if size == 0 {
+		return "", nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	// The mapping holds its own reference to the file, so it can be closed straight away
+	defer file.Close()
+
+	data, err := unix.Mmap(int(file.Fd()), 0, size, unix.PROT_READ, unix.MAP_PRIVATE)
+	if err != nil {
+		return "", nil, &os.PathError{Op: "mmap", Path: path, Err: err}
+	}
+
+	// The lexer reads front to back, so ask the kernel to read ahead aggressively. Only a hint,
+	// so a failure doesn't matter.
+	_ = unix.Madvise(data, unix.MADV_SEQUENTIAL)
+
+	return unsafe.String(&data[0], len(data)), &fileMapping{data: data}, nil
+}
+
+// Unmaps the file. Anything still pointing into it is invalid afterwards.
+func (m *fileMapping) unmap() {
+	if m.data == nil {
+		return
+	}
+	unix.Munmap(m.data)
+	m.data = nil
+}
@@ -0,0 +1,19 @@
+// +build !linux
+
+package jsonParser
+
+import (
+	"os"
+)
+
+// Memory mapping isn't supported on this platform, so reads the whole file instead. There's no
+// mapping to keep alive, so it's always nil.
+func mapFile(path string, size int) (string, *fileMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(data), nil, nil
+}
+
+func (m *fileMapping) unmap() {}
@@ -0,0 +1,75 @@
+package jsonParser
+
+/*
+	Tests parsing files with ParseFile().
+*/
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func writeTempFile(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "test.json")
+	err := os.WriteFile(path, []byte(data), 0644)
+	assert.Nil(t, err, "Expected temp file to be written")
+	return path
+}
+
+func TestParseFile(t *testing.T) {
+	path := writeTempFile(t, `{"name": "abc", "pairs": [{"x0": 1.5}, {"x0": 2.5}]}`)
+	jsonResult, err := ParseFile(path)
+	assert.Nil(t, err, "Expected file to parse")
+
+	name, _ := jsonResult.GetString("name")
+	assert.Equal(t, name, "abc", "Unexpected string")
+	pairs, _ := jsonResult.GetArray("pairs")
+	assert.Equal(t, len(pairs), 2, "Unexpected array length")
+	x0, _ := pairs[1].GetFloat("x0")
+	assert.Equal(t, x0, 2.5, "Unexpected float")
+
+	// Children keep the mapping alive too
+	assert.Equal(t, pairs[1].mapping, jsonResult.mapping, "Expected child to hold mapping")
+	if runtime.GOOS == "linux" {
+		assert.NotNil(t, jsonResult.mapping, "Expected file to be mapped")
+	}
+}
+
+func TestParseFileStringOutlivesMapping(t *testing.T) {
+	path := writeTempFile(t, `{"name": "abc"}`)
+	jsonResult, err := ParseFile(path)
+	assert.Nil(t, err, "Expected file to parse")
+	name, _ := jsonResult.GetString("name")
+
+	// Unmap, like the finalizer would once jsonResult is unreachable. Reading a string still
+	// pointing into the mapping would crash.
+	jsonResult.mapping.unmap()
+	assert.Equal(t, name, "abc", "Expected string to be copied out of mapping")
+}
+
+func TestParseFileEmpty(t *testing.T) {
+	path := writeTempFile(t, "")
+	_, err := ParseFile(path)
+	assert.NotNil(t, err, "Expected empty file to fail")
+}
+
+func TestParseFileErrors(t *testing.T) {
+	_, err := ParseFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Equal(t, errors.Is(err, os.ErrNotExist), true, "Expected missing file error")
+
+	path := writeTempFile(t, "{\n\"a\": }")
+	_, err = ParseFile(path)
+	parseErr := getParseError(err)
+	assert.NotNil(t, parseErr, "Expected ParseError")
+	assert.Equal(t, parseErr.Line, 2, "Unexpected error line")
+
+	options := DefaultParseOptions()
+	options.MaxDocumentSize = 4
+	_, err = ParseFileWithOptions(path, options)
+	assert.Equal(t, errors.Is(err, ErrMaxDocumentSizeExceeded), true, "Expected document size error")
+}
@@ -0,0 +1,306 @@
+package jsonParser
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+)
+
+/*
+	Decimal to float64 conversion for the number hot path.
+
+	strconv.ParseFloat is correct but general (it handles hex, underscores, "inf", etc.) & needs
+	a string, so every number token used to be a fresh substring. This works directly on a
+	string or []byte range & goes from cheapest to most expensive:
+
+	1. Fast path (Clinger): the mantissa fits in 53 bits & the power of 10 is exactly
+	   representable, so a single float multiply/divide is correctly rounded.
+	2. Eisel-Lemire: multiplies the mantissa by a 128-bit approximation of the power of 10. It
+	   either returns the correctly rounded result or reports that it can't decide.
+	3. Fallback: strconv.ParseFloat, which is always correct. Only hit for ambiguous halfway
+	   cases, literals with more than 19 significant digits that Eisel-Lemire can't settle, or
+	   out of range exponents.
+
+	Refs:
+	- Clinger, "How to Read Floating Point Numbers Accurately" (1990)
+	- Lemire, "Number Parsing at a Gigabyte per Second" (2021)
+	- Go's strconv/eisel_lemire.go, which this follows closely
+*/
+
+// Max significant decimal digits that always fit in a uint64 mantissa.
+const FLOAT_MAX_MANTISSA_DIGITS = 19
+
+// Range of powers of 10 covered by the Eisel-Lemire table. Anything outside is 0 or Inf.
+const FLOAT_POW10_MIN_EXP = -348
+const FLOAT_POW10_MAX_EXP = 347
+
+// Exactly representable powers of 10 for the fast path.
+var floatExactPow10 = [...]float64{
+	1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10, 1e11,
+	1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19, 1e20, 1e21, 1e22,
+}
+
+// 128-bit mantissas of 10^q for q in [FLOAT_POW10_MIN_EXP, FLOAT_POW10_MAX_EXP], normalized so
+// the top bit is set & rounded down. Stored as {low 64 bits, high 64 bits}.
+var floatPow10Table [FLOAT_POW10_MAX_EXP - FLOAT_POW10_MIN_EXP + 1][2]uint64
+
+// Builds floatPow10Table with math/big. It's ~700 entries so this is cheap, & it saves checking
+// in a generated table.
+func init() {
+	ten := big.NewInt(10)
+	for q := FLOAT_POW10_MIN_EXP; q <= FLOAT_POW10_MAX_EXP; q++ {
+		var m big.Int
+		if q >= 0 {
+			// 10^q is exact, shift it so it's exactly 128 bits
+			m.Exp(ten, big.NewInt(int64(q)), nil)
+			if m.BitLen() > 128 {
+				m.Rsh(&m, uint(m.BitLen()-128))
+			} else {
+				m.Lsh(&m, uint(128-m.BitLen()))
+			}
+		} else {
+			// 10^q = 1/10^-q. Divide a big enough power of 2 so the quotient is 128 bits.
+			var d big.Int
+			d.Exp(ten, big.NewInt(int64(-q)), nil)
+			m.Lsh(big.NewInt(1), uint(127+d.BitLen()))
+			m.Quo(&m, &d)
+			if m.BitLen() > 128 {
+				m.Rsh(&m, uint(m.BitLen()-128))
+			}
+		}
+
+		var lo, hi big.Int
+		lo.And(&m, new(big.Int).SetUint64(math.MaxUint64))
+		hi.Rsh(&m, 64)
+		floatPow10Table[q-FLOAT_POW10_MIN_EXP] = [2]uint64{lo.Uint64(), hi.Uint64()}
+	}
+}
+
+// Converts a JSON number literal to the nearest float64. Returns the same results & errors as
+// strconv.ParseFloat(s, 64).
+func parseFloat64[T ~string | ~[]byte](s T) (float64, error) {
+	mantissa, exp10, neg, truncated, ok := readDecimal(s)
+	if ok {
+		if !truncated {
+			if f, ok := floatFastPath(mantissa, exp10, neg); ok {
+				return f, nil
+			}
+			if f, ok := eiselLemire64(mantissa, exp10, neg); ok {
+				return f, nil
+			}
+		} else {
+			// Digits past the 19th were dropped, so the real value is between mantissa &
+			// mantissa+1. If both round to the same float, that's the answer.
+			f0, ok0 := eiselLemire64(mantissa, exp10, neg)
+			f1, ok1 := eiselLemire64(mantissa+1, exp10, neg)
+			if ok0 && ok1 && f0 == f1 {
+				return f0, nil
+			}
+		}
+	}
+
+	return strconv.ParseFloat(string(s), 64)
+}
+
+// Reads a JSON number literal into a decimal mantissa & power of 10, keeping at most 19
+// significant digits. Returns ok = false if s isn't a JSON number literal.
+func readDecimal[T ~string | ~[]byte](s T) (mantissa uint64, exp10 int, neg bool, truncated bool, ok bool) {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		neg = true
+		i += 1
+	}
+
+	numDigits := 0 // Significant digits stored in mantissa
+	dropped := 0   // Integer digits that didn't fit in mantissa, each adds a power of 10
+
+	// Int part. Leading zeros don't count as significant digits.
+	intStart := i
+	for i < len(s) && s[i] == '0' {
+		i += 1
+	}
+	for ; i < len(s); i++ {
+		// Subtracting first means 1 compare per digit instead of 2
+		c := s[i] - '0'
+		if c > 9 {
+			break
+		}
+		if numDigits < FLOAT_MAX_MANTISSA_DIGITS {
+			mantissa = mantissa*10 + uint64(c)
+			numDigits += 1
+		} else {
+			dropped += 1
+			if c != 0 {
+				truncated = true
+			}
+		}
+	}
+	// JSON doesn't allow leading zeros like "01"
+	if i == intStart || (s[intStart] == '0' && i-intStart > 1) {
+		return 0, 0, false, false, false
+	}
+	exp10 = dropped
+
+	// Fraction part
+	if i < len(s) && s[i] == '.' {
+		i += 1
+		fracStart := i
+		// Zeros right after the point only move the exponent if nothing significant came yet,
+		// like 0.001
+		if numDigits == 0 {
+			for i < len(s) && s[i] == '0' {
+				i += 1
+				exp10 -= 1
+			}
+		}
+		for ; i < len(s); i++ {
+			c := s[i] - '0'
+			if c > 9 {
+				break
+			}
+			if numDigits < FLOAT_MAX_MANTISSA_DIGITS {
+				mantissa = mantissa*10 + uint64(c)
+				numDigits += 1
+				exp10 -= 1
+			} else if c != 0 {
+				truncated = true
+			}
+		}
+		if i == fracStart {
+			return 0, 0, false, false, false
+		}
+	}
+
+	// Exponent part
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i += 1
+		expNeg := false
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			expNeg = s[i] == '-'
+			i += 1
+		}
+		expStart := i
+		exp := 0
+		for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			// Cap it. Anything this big is 0 or Inf & gets sent to the fallback anyway.
+			if exp < 100000 {
+				exp = exp*10 + int(s[i]-'0')
+			}
+		}
+		if i == expStart {
+			return 0, 0, false, false, false
+		}
+		if expNeg {
+			exp = -exp
+		}
+		exp10 += exp
+	}
+
+	if i != len(s) {
+		return 0, 0, false, false, false
+	}
+	return mantissa, exp10, neg, truncated, true
+}
+
+// Clinger's fast path. Exact when the mantissa & power of 10 are both exact float64s, since
+// IEEE multiply/divide of exact values is correctly rounded.
+func floatFastPath(mantissa uint64, exp10 int, neg bool) (float64, bool) {
+	if mantissa>>53 != 0 {
+		return 0, false
+	}
+
+	f := float64(mantissa)
+	if neg {
+		f = -f
+	}
+
+	switch {
+	case exp10 == 0:
+		return f, true
+	case exp10 > 0 && exp10 <= 15+22:
+		// Move some of the exponent into the mantissa if it's too big for the table, as long
+		// as the mantissa stays exact. For example 1e30 = 1e8 * 1e22.
+		if exp10 > 22 {
+			f *= floatExactPow10[exp10-22]
+			exp10 = 22
+		}
+		if f > 1e15 || f < -1e15 {
+			return 0, false
+		}
+		return f * floatExactPow10[exp10], true
+	case exp10 < 0 && exp10 >= -22:
+		return f / floatExactPow10[-exp10], true
+	}
+
+	return 0, false
+}
+
+// Eisel-Lemire algorithm. Returns ok = false when it can't guarantee a correctly rounded result,
+// in which case the caller must fall back to something slower.
+func eiselLemire64(mantissa uint64, exp10 int, neg bool) (float64, bool) {
+	// Zero is zero no matter the exponent
+	if mantissa == 0 {
+		if neg {
+			return math.Copysign(0, -1), true
+		}
+		return 0, true
+	}
+	if exp10 < FLOAT_POW10_MIN_EXP || exp10 > FLOAT_POW10_MAX_EXP {
+		return 0, false
+	}
+
+	// Normalize so the mantissa's top bit is set.
+	clz := bits.LeadingZeros64(mantissa)
+	mantissa <<= uint(clz)
+	const float64ExponentBias = 1023
+	// 217706 / 2^16 ~= log2(10)
+	retExp2 := uint64(217706*exp10>>16+64+float64ExponentBias) - uint64(clz)
+
+	// Multiply by the top 64 bits of the power of 10.
+	pow10 := floatPow10Table[exp10-FLOAT_POW10_MIN_EXP]
+	xHi, xLo := bits.Mul64(mantissa, pow10[1])
+
+	// If the low bits are all 1s, the truncated table value may matter, so include the low
+	// 64 bits of the power of 10 too.
+	if xHi&0x1FF == 0x1FF && xLo+mantissa < mantissa {
+		yHi, yLo := bits.Mul64(mantissa, pow10[0])
+		mergedHi, mergedLo := xHi, xLo+yHi
+		if mergedLo < xLo {
+			mergedHi += 1
+		}
+		if mergedHi&0x1FF == 0x1FF && mergedLo+1 == 0 && yLo+mantissa < mantissa {
+			return 0, false
+		}
+		xHi, xLo = mergedHi, mergedLo
+	}
+
+	// Shift down to 54 bits (53 + 1 for rounding).
+	msb := xHi >> 63
+	retMantissa := xHi >> (msb + 9)
+	retExp2 -= 1 ^ msb
+
+	// Exactly halfway between 2 floats, can't decide which way to round.
+	if xLo == 0 && xHi&0x1FF == 0 && retMantissa&3 == 1 {
+		return 0, false
+	}
+
+	// Round to 53 bits, which may carry into the exponent.
+	retMantissa += retMantissa & 1
+	retMantissa >>= 1
+	if retMantissa>>53 > 0 {
+		retMantissa >>= 1
+		retExp2 += 1
+	}
+
+	// Zero or underflow means subnormal, 0x7FF or above means Inf. Leave those to the fallback.
+	if retExp2-1 >= 0x7FF-1 {
+		return 0, false
+	}
+
+	retBits := retExp2<<52 | retMantissa&(1<<52-1)
+	if neg {
+		retBits |= 1 << 63
+	}
+	return math.Float64frombits(retBits), true
+}
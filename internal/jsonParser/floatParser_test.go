@@ -0,0 +1,143 @@
+package jsonParser
+
+/*
+	Tests the custom float parser against strconv.ParseFloat, which is the reference for
+	correctly rounded results.
+*/
+
+import (
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+// Parses s with both parsers & fails if the bits or error presence differ.
+func checkFloatAgainstStrconv(t *testing.T, s string) {
+	t.Helper()
+
+	expected, expectedErr := strconv.ParseFloat(s, 64)
+	actual, actualErr := parseFloat64(s)
+	if math.Float64bits(actual) != math.Float64bits(expected) || (actualErr == nil) != (expectedErr == nil) {
+		t.Errorf(`parseFloat64("%s") = %v (err %v), strconv gives %v (err %v)`, s, actual, actualErr, expected, expectedErr)
+	}
+
+	// Same input as a byte range
+	bytesActual, _ := parseFloat64([]byte(s))
+	if math.Float64bits(bytesActual) != math.Float64bits(expected) {
+		t.Errorf(`parseFloat64([]byte("%s")) = %v, strconv gives %v`, s, bytesActual, expected)
+	}
+}
+
+func TestFloatParserEdgeCases(t *testing.T) {
+	cases := []string{
+		// Simple
+		"0", "-0", "1", "-1", "0.1", "0.5", "1.5", "123.456", "0.000001", "1e0", "1E0", "-0.0e0",
+		// Fast path boundaries
+		"9007199254740991", "9007199254740992", "9007199254740993", "1e22", "1e23", "1e-22", "1e-23",
+		"123456789e20", "1e37", "1e38",
+		// Extremes
+		"1.7976931348623157e308", "1.7976931348623158e308", "1.7976931348623159e308", "1e308", "1e309",
+		"2.2250738585072014e-308", "2.2250738585072011e-308", "4.9e-324", "5e-324", "2.4703282292062327e-324",
+		"2.4703282292062328e-324", "1e-400", "-1e-400", "1e-100000", "1e100000",
+		// Halfway cases & more than 19 significant digits
+		"9007199254740993.0000000001", "0.1000000000000000055511151231257827021181583404541015625",
+		"0.1000000000000000055511151231257827021181583404541015624", "123456789012345678901234567890",
+		"1.00000000000002", "7.3177701707893310e+15", "2.0000000000000004440892098500626",
+		"2.00000000000000044408920985006261616945", "1448997445238699", "0.30000000000000004",
+		"00000000000000000000000000000000000000001e-1",
+		// Leading zeros in the fraction
+		"0.00000000000000000000000000000000001", "0.0000000000000000000000000000000000000000000001",
+		// Long zero runs after the mantissa fills
+		"10000000000000000000000000000000000000000", "1000000000000000000000000000000000000000.1",
+		// Haversine coordinates, which is the case we care most about
+		"-179.9999999999999716", "102.1633205722960440", "-24.9977499718717624", "62.6708294856625940",
+	}
+
+	for _, s := range cases {
+		checkFloatAgainstStrconv(t, s)
+	}
+}
+
+func TestFloatParserInvalid(t *testing.T) {
+	// These aren't JSON numbers. Some of them are valid for strconv, which is fine, they just
+	// can't take the fast path.
+	invalid := []string{"", "-", "01", "1.", ".1", "1e", "1e+", "+1", "--1", "1-2", "abc", "0x10", "inf", "1_0"}
+	for _, s := range invalid {
+		_, _, _, _, ok := readDecimal(s)
+		assert.Equal(t, ok, false, `Expected readDecimal("`+s+`") to fail`)
+	}
+
+	_, err := parseFloat64("abc")
+	assert.NotNil(t, err, "Expected error for non-number, did not error")
+
+	_, err = parseFloat64("1e309")
+	assert.NotNil(t, err, "Expected range error for overflow, did not error")
+}
+
+func TestFloatParserRandomBits(t *testing.T) {
+	// Every float64 printed in shortest, fixed & exponent forms must parse back to the exact
+	// same bits.
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 100000; i++ {
+		f := math.Float64frombits(r.Uint64())
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			continue
+		}
+
+		checkFloatAgainstStrconv(t, strconv.FormatFloat(f, 'g', -1, 64))
+		checkFloatAgainstStrconv(t, strconv.FormatFloat(f, 'e', 16, 64))
+		checkFloatAgainstStrconv(t, strconv.FormatFloat(f, 'e', 25, 64))
+		if math.Abs(f) > 1e-30 && math.Abs(f) < 1e30 {
+			checkFloatAgainstStrconv(t, strconv.FormatFloat(f, 'f', -1, 64))
+		}
+	}
+}
+
+func TestFloatParserRandomDecimals(t *testing.T) {
+	// Random digit strings, which hit truncation & halfway cases that printed floats never do.
+	r := rand.New(rand.NewPCG(3, 4))
+	var sb strings.Builder
+	for i := 0; i < 100000; i++ {
+		sb.Reset()
+		if r.IntN(2) == 0 {
+			sb.WriteByte('-')
+		}
+
+		// Int part
+		intDigits := 1 + r.IntN(25)
+		sb.WriteByte(byte('1' + r.IntN(9)))
+		for d := 1; d < intDigits; d++ {
+			sb.WriteByte(byte('0' + r.IntN(10)))
+		}
+
+		// Fraction part
+		if r.IntN(2) == 0 {
+			sb.WriteByte('.')
+			fracDigits := 1 + r.IntN(25)
+			for d := 0; d < fracDigits; d++ {
+				sb.WriteByte(byte('0' + r.IntN(10)))
+			}
+		}
+
+		// Exponent part
+		if r.IntN(2) == 0 {
+			sb.WriteByte("eE"[r.IntN(2)])
+			sb.WriteString(strconv.Itoa(r.IntN(700) - 350))
+		}
+
+		checkFloatAgainstStrconv(t, sb.String())
+	}
+}
+
+func TestFloatParserHaversineRange(t *testing.T) {
+	// Same format cmd/generateJson writes, "%.16f" for coordinates in [-180, 180]
+	r := rand.New(rand.NewPCG(5, 6))
+	for i := 0; i < 100000; i++ {
+		f := -180.0 + 360.0*r.Float64()
+		checkFloatAgainstStrconv(t, strconv.FormatFloat(f, 'f', 16, 64))
+	}
+}
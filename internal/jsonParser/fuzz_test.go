@@ -0,0 +1,191 @@
+package jsonParser
+
+/*
+	Fuzz tests for the lexer & parser. Without -fuzz, these just run the seeds, so they're part of
+	the normal test run. To fuzz:
+
+	```
+	go test -run=^$ -fuzz=FuzzParseJson -fuzztime=60s .
+	go test -run=^$ -fuzz=FuzzLexer -fuzztime=60s .
+	go test -run=^$ -fuzz=FuzzPushParser -fuzztime=60s .
+	```
+
+	Inputs that fail are saved to testdata/fuzz/ & rerun as seeds from then on.
+
+	encoding/json is the oracle for what's valid JSON. Invalid UTF-8 is skipped, since
+	encoding/json accepts it in strings & this parser rejects it by default. Valid() must agree
+	with the parser on everything.
+*/
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+)
+
+// Seeds taken from the lexer, parser & other tests, covering valid & invalid JSON.
+var FUZZ_SEEDS = []string{
+	``,
+	` `,
+	`{}`,
+	`{[]}`,
+	`{[[[{{[{[{[]}]}]}}]]]}`,
+	`{/}`,
+	`}`,
+	`{`,
+	`[`,
+	`"hello": "world"}`,
+	`{"hello" "world"}`,
+	`{ "a": 1 "b": 2 }`,
+	`{ a: 1 }`,
+	`{ "a": 1, "b": 2, }`,
+	`{ "a": [1,2,] }`,
+	`{ "a": [1,2,,] }`,
+	`{ 1: 2 }`,
+	`{ "a"`,
+	`{"a"`,
+	`{ "a": `,
+	`{ "a": [1`,
+	`{"a": 1, "b": [true, null, "x"]}`,
+	`{"pairs": [{"x0": 1.5, "y0": -2.25e1, "x1": 0, "y1": -0}]}`,
+	`[1, 1.0, -1.25E-3, 1e10, 1e+10, 0.001, 18446744073709551615]`,
+	`[012]`,
+	`[-01]`,
+	`[0.e1]`,
+	`[1e400]`,
+	`["\"\\\/\b\f\n\r\t\u0012é𐐷"]`,
+	`["\ud800"]`,
+	`["\x00"]`,
+	`["\u12"]`,
+	"[\"new\nline\"]",
+	`"lonely"`,
+	`42`,
+	`true`,
+	`false`,
+	`null`,
+	`nul`,
+	`[] []`,
+	`{"a":"b"}#{}`,
+}
+
+func addFuzzSeeds(f *testing.F) {
+	for _, seed := range FUZZ_SEEDS {
+		f.Add(seed)
+	}
+
+	// Plus the conformance corpus, see cmd/jsonConformance
+	paths, _ := filepath.Glob("../../cmd/jsonConformance/testdata/*.json")
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			f.Add(string(data))
+		}
+	}
+}
+
+func FuzzLexer(f *testing.F) {
+	addFuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, s string) {
+		tokens, err := newLexer(s).lex()
+		if err != nil {
+			if utf8.ValidString(s) && json.Valid([]byte(s)) {
+				t.Fatalf("Lexer rejected valid JSON %q: %s", s, err)
+			}
+			return
+		}
+
+		// Tokens must be in order & point into the data
+		lastPos := -1
+		for _, token := range tokens {
+			if token.Pos <= lastPos || token.Pos+len(token.Value) > len(s) {
+				t.Fatalf("Token %q at %d is out of order or past the end of %q", token.Value, token.Pos, s)
+			}
+			lastPos = token.Pos
+		}
+	})
+}
+
+func FuzzParseJson(f *testing.F) {
+	addFuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, s string) {
+		// Default options mustn't panic, whatever they return
+		ParseJson(s)
+
+		// Number mode keeps numbers too big for a float64 or int, which encoding/json accepts
+		options := DefaultParseOptions()
+		options.UseNumber = true
+		value, err := ParseJsonWithOptions(s, options)
+		if utf8.ValidString(s) {
+			valid := json.Valid([]byte(s))
+			if valid && err != nil {
+				t.Fatalf("Rejected valid JSON %q: %s", s, err)
+			}
+			if !valid && err == nil {
+				t.Fatalf("Accepted invalid JSON %q", s)
+			}
+		}
+		if Valid([]byte(s)) != (err == nil) {
+			t.Fatalf("Valid() disagrees with the parser for %q: %v", s, err)
+		}
+		if err != nil {
+			return
+		}
+
+		// Parse -> serialize -> parse gives the same value
+		serialized, err := value.Serialize()
+		if err != nil {
+			t.Fatalf("Couldn't serialize %q: %s", s, err)
+		}
+		reparsed, err := ParseJsonWithOptions(string(serialized), options)
+		if err != nil {
+			t.Fatalf("Couldn't reparse %q, serialized from %q: %s", serialized, s, err)
+		}
+		if !Equal(value, reparsed) {
+			t.Fatalf("%q serialized to %q, which parses to a different value", s, serialized)
+		}
+	})
+}
+
+func FuzzPushParser(f *testing.F) {
+	for _, seed := range FUZZ_SEEDS {
+		f.Add(seed, uint(len(seed)/2))
+	}
+
+	f.Fuzz(func(t *testing.T, s string, split uint) {
+		options := DefaultParseOptions()
+		options.UseNumber = true
+		expected, expectedErr := ParseJsonWithOptions(s, options)
+
+		// Push in 2 chunks, split anywhere
+		at := int(split % uint(len(s)+1))
+		var values []*JsonValue
+		parser := NewPushParser(options)
+		parser.OnValue = func(value *JsonValue, offset int) error {
+			values = append(values, value)
+			return nil
+		}
+		_, err := parser.Write([]byte(s[:at]))
+		if err == nil {
+			_, err = parser.Write([]byte(s[at:]))
+		}
+		if err == nil {
+			err = parser.Close()
+		}
+
+		// Streams can hold any number of values, so only 1 value must agree with ParseJson()
+		if expectedErr == nil {
+			if err != nil || len(values) != 1 {
+				t.Fatalf("Push parser split at %d didn't match ParseJson for %q: %d values, %v", at, s, len(values), err)
+			}
+			if !Equal(values[0], expected) {
+				t.Fatalf("Push parser split at %d gave a different value for %q", at, s)
+			}
+		} else if err == nil && len(values) == 1 {
+			t.Fatalf("Push parser split at %d accepted %q, which ParseJson rejects: %s", at, s, expectedErr)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package jsonParser
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+	Generic accessors get a typed value by JSON pointer path (see jsonPointer.go) in 1 call,
+	instead of a GetObject() per level:
+
+	```
+	x0, err := jsonParser.Get[float64](jsonResult, "/pairs/0/x0")
+	if errors.Is(err, jsonParser.ErrKeyNotFound) {
+		...
+	}
+	name := jsonParser.GetOr(jsonResult, "/name", "unnamed")	// Default if missing or wrong type
+	count := jsonParser.MustGet[int](jsonResult, "/count")		// Panics on error
+	```
+
+	The path "" is the value itself. Errors match ErrKeyNotFound (nothing at the path) or
+	ErrTypeMismatch (something there, but it can't be converted to T) with errors.Is().
+
+	Coercion
+
+	Get() converts between types following DefaultCoercion:
+	- Ints convert to float64 (IntToFloat), since JSON doesn't tell 1 & 1.0 apart.
+	- Numbers (see number.go) convert to any number type that holds their value exactly, except
+	  float64, which rounds as GetFloat() does.
+	- Any number converts to Number.
+
+	Other conversions are off by default. Turn them on with GetWithCoercion():
+	- FloatToInt: floats with no fraction (like 2.0) convert to int, int64 & uint64.
+	- NumericStrings: strings holding a JSON number (like "1.5") convert to number types.
+	- NumbersToStrings: numbers convert to their JSON text (like "1.5").
+	- BoolStrings: "true" & "false" convert to bool.
+
+	T is 1 of string, int, int64, uint64, float64, bool, Number, *JsonValue (any value) or
+	[]*JsonValue (an array).
+*/
+
+// Types that Get() can return.
+type Gettable interface {
+	string | int | int64 | uint64 | float64 | bool | Number | *JsonValue | []*JsonValue
+}
+
+// Conversions Get() makes between JSON types, see get.go.
+type Coercion struct {
+	IntToFloat       bool // 1 to 1.0
+	FloatToInt       bool // 1.0 to 1, but not 1.5
+	NumericStrings   bool // "1.5" to 1.5
+	NumbersToStrings bool // 1.5 to "1.5"
+	BoolStrings      bool // "true" to true
+}
+
+var DefaultCoercion = Coercion{IntToFloat: true}
+
+// Returns the value at the JSON pointer path as T, using DefaultCoercion.
+func Get[T Gettable](v *JsonValue, path string) (T, error) {
+	return GetWithCoercion[T](v, path, DefaultCoercion)
+}
+
+// Returns the value at the JSON pointer path as T, using the given coercion.
+func GetWithCoercion[T Gettable](v *JsonValue, path string, coercion Coercion) (T, error) {
+	var result T
+
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return result, err
+	}
+	index, ok := v.tape.lookupPointer(v.index, tokens)
+	if !ok {
+		return result, fmt.Errorf(`%w: "%s"`, ErrKeyNotFound, path)
+	}
+
+	if !convertValue(JsonValue{tape: v.tape, index: index, mapping: v.mapping}, &result, coercion) {
+		return result, fmt.Errorf(`%w at "%s"`, v.tape.typeMismatchError(index, fmt.Sprintf("%T", result)), path)
+	}
+	return result, nil
+}
+
+// Returns the value at the JSON pointer path as T, or defaultVal if it's missing or can't be
+// converted.
+func GetOr[T Gettable](v *JsonValue, path string, defaultVal T) T {
+	result, err := Get[T](v, path)
+	if err != nil {
+		return defaultVal
+	}
+	return result
+}
+
+// Returns the value at the JSON pointer path as T, & panics if it's missing or can't be
+// converted. For values that must be there, like in tests.
+func MustGet[T Gettable](v *JsonValue, path string) T {
+	result, err := Get[T](v, path)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Converts val into result, which points to a Gettable type. Returns false if it can't be
+// converted.
+func convertValue(val JsonValue, result any, coercion Coercion) bool {
+	n := val.tape.nodes[val.index]
+
+	// Numeric strings are converted like the Number they hold
+	kind := n.kind
+	if kind == tapeString && coercion.NumericStrings && isValidJsonNumber(val.tape.str(n)) {
+		if _, isString := result.(*string); !isString {
+			kind = tapeNumber
+		}
+	}
+
+	switch typedResult := result.(type) {
+	case *string:
+		switch kind {
+		case tapeString:
+			*typedResult = val.ownString(val.tape.str(n))
+			return true
+		case tapeInt, tapeFloat, tapeNumber:
+			if coercion.NumbersToStrings {
+				*typedResult = val.ownString(string(val.tape.numberLiteral(n)))
+				return true
+			}
+		}
+	case *int:
+		int64Val, ok := convertToInt64(val.tape, n, kind, coercion)
+		if ok && int64(int(int64Val)) == int64Val {
+			*typedResult = int(int64Val)
+			return true
+		}
+	case *int64:
+		int64Val, ok := convertToInt64(val.tape, n, kind, coercion)
+		if ok {
+			*typedResult = int64Val
+			return true
+		}
+	case *uint64:
+		switch kind {
+		case tapeInt:
+			if n.int() >= 0 {
+				*typedResult = uint64(n.int())
+				return true
+			}
+		case tapeNumber:
+			number := Number(val.tape.str(n))
+			uint64Val, err := number.Uint64()
+			if err == nil {
+				*typedResult = uint64Val
+				return true
+			}
+			if coercion.FloatToInt {
+				floatVal, err := number.Float64()
+				if err == nil && floatVal >= 0 && floatVal < math.MaxUint64 && floatVal == math.Trunc(floatVal) {
+					*typedResult = uint64(floatVal)
+					return true
+				}
+			}
+		case tapeFloat:
+			floatVal := n.float()
+			if coercion.FloatToInt && floatVal >= 0 && floatVal < math.MaxUint64 && floatVal == math.Trunc(floatVal) {
+				*typedResult = uint64(floatVal)
+				return true
+			}
+		}
+	case *float64:
+		switch kind {
+		case tapeFloat:
+			*typedResult = n.float()
+			return true
+		case tapeNumber:
+			floatVal, err := Number(val.tape.str(n)).Float64()
+			if err == nil {
+				*typedResult = floatVal
+				return true
+			}
+		case tapeInt:
+			if coercion.IntToFloat {
+				*typedResult = float64(n.int())
+				return true
+			}
+		}
+	case *bool:
+		switch kind {
+		case tapeTrue, tapeFalse:
+			*typedResult = kind == tapeTrue
+			return true
+		case tapeString:
+			strVal := val.tape.str(n)
+			if coercion.BoolStrings && (strVal == JSON_SYNTAX_BOOL_TRUE || strVal == JSON_SYNTAX_BOOL_FALSE) {
+				*typedResult = strVal == JSON_SYNTAX_BOOL_TRUE
+				return true
+			}
+		}
+	case *Number:
+		switch kind {
+		case tapeInt, tapeFloat, tapeNumber:
+			*typedResult = Number(val.ownString(string(val.tape.numberLiteral(n))))
+			return true
+		}
+	case **JsonValue:
+		// Copied, so val itself doesn't escape for the other types
+		valCopy := val
+		*typedResult = &valCopy
+		return true
+	case *[]*JsonValue:
+		if kind == tapeArray {
+			*typedResult = val.wrapArrayItems()
+			return true
+		}
+	}
+	return false
+}
+
+// Converts an int, Number, or (with FloatToInt) a float with no fraction to an int64. The kind
+// is n's, or tapeNumber for a string holding a number.
+func convertToInt64(t *tape, n tapeNode, kind tapeKind, coercion Coercion) (int64, bool) {
+	switch kind {
+	case tapeInt:
+		return int64(n.int()), true
+	case tapeNumber:
+		number := Number(t.str(n))
+		int64Val, err := number.Int64()
+		if err == nil {
+			return int64Val, true
+		}
+		if coercion.FloatToInt {
+			floatVal, err := number.Float64()
+			if err == nil {
+				return floatToInt64(floatVal)
+			}
+		}
+	case tapeFloat:
+		if coercion.FloatToInt {
+			return floatToInt64(n.float())
+		}
+	}
+	return 0, false
+}
+
+// Converts a float with no fraction to an int64, or returns false if it has 1 or is out of range.
+func floatToInt64(f float64) (int64, bool) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
@@ -0,0 +1,151 @@
+package jsonParser
+
+/*
+	Tests the generic Get accessors & coercion.
+*/
+
+import (
+	"errors"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+const GET_TEST_JSON = `{
+	"pairs": [{"x0": 1.5, "y0": 2}],
+	"name": "abc",
+	"count": 3,
+	"whole": 4.0,
+	"numStr": "12",
+	"floatStr": "1.25",
+	"flag": "true",
+	"on": true,
+	"nothing": null,
+	"a/b": {"c": 1}
+}`
+
+func TestGet(t *testing.T) {
+	value, _ := runParserWithStr(GET_TEST_JSON)
+
+	x0, err := Get[float64](value, "/pairs/0/x0")
+	assert.Nil(t, err, "Expected float")
+	assert.Equal(t, x0, 1.5, "Unexpected float")
+
+	// Ints convert to float by default
+	y0, err := Get[float64](value, "/pairs/0/y0")
+	assert.Nil(t, err, "Expected int to convert to float")
+	assert.Equal(t, y0, 2.0, "Unexpected float")
+
+	name, _ := Get[string](value, "/name")
+	assert.Equal(t, name, "abc", "Unexpected string")
+	count, _ := Get[int64](value, "/count")
+	assert.Equal(t, count, int64(3), "Unexpected int64")
+	on, _ := Get[bool](value, "/on")
+	assert.Equal(t, on, true, "Unexpected bool")
+	number, _ := Get[Number](value, "/pairs/0/x0")
+	assert.Equal(t, number, Number("1.5"), "Unexpected Number")
+	c, _ := Get[int](value, "/a~1b/c")
+	assert.Equal(t, c, 1, "Expected escaped path")
+
+	pairs, _ := Get[[]*JsonValue](value, "/pairs")
+	assert.Equal(t, len(pairs), 1, "Unexpected array")
+	self, _ := Get[*JsonValue](value, "")
+	assert.Equal(t, self.Kind(), KindObject, "Expected empty path to get self")
+	nothing, err := Get[*JsonValue](value, "/nothing")
+	assert.Nil(t, err, "Expected null as JsonValue")
+	assert.Equal(t, nothing.Kind(), KindNull, "Expected null")
+}
+
+func TestGetErrors(t *testing.T) {
+	value, _ := runParserWithStr(GET_TEST_JSON)
+
+	_, err := Get[int](value, "/missing")
+	assert.Equal(t, errors.Is(err, ErrKeyNotFound), true, "Expected key not found")
+	_, err = Get[int](value, "/pairs/5/x0")
+	assert.Equal(t, errors.Is(err, ErrKeyNotFound), true, "Expected key not found for bad index")
+
+	tests := []string{"/name", "/whole", "/numStr", "/nothing", "/pairs"}
+	for _, path := range tests {
+		_, err = Get[int](value, path)
+		assert.Equal(t, errors.Is(err, ErrTypeMismatch), true, "Expected type mismatch for "+path)
+	}
+	_, err = Get[float64](value, "/name")
+	assert.Equal(t, err.Error(), `Type mismatch: error casting string "abc" to float64 at "/name"`, "Unexpected error message")
+	_, err = Get[string](value, "/count")
+	assert.Equal(t, errors.Is(err, ErrTypeMismatch), true, "Expected no number to string by default")
+	_, err = Get[bool](value, "/flag")
+	assert.Equal(t, errors.Is(err, ErrTypeMismatch), true, "Expected no bool strings by default")
+
+	_, err = Get[int](value, "name")
+	assert.NotNil(t, err, "Expected error for invalid pointer")
+
+	assert.Equal(t, GetOr(value, "/missing", "default"), "default", "Expected default for missing")
+	assert.Equal(t, GetOr(value, "/name", 7), 7, "Expected default for mismatch")
+	assert.Equal(t, GetOr(value, "/count", 7), 3, "Expected value when present")
+
+	assert.Equal(t, MustGet[int](value, "/count"), 3, "Unexpected MustGet value")
+	defer func() {
+		assert.NotNil(t, recover(), "Expected MustGet to panic")
+	}()
+	MustGet[int](value, "/missing")
+}
+
+func TestGetCoercion(t *testing.T) {
+	value, _ := runParserWithStr(GET_TEST_JSON)
+	coercion := Coercion{
+		IntToFloat:       true,
+		FloatToInt:       true,
+		NumericStrings:   true,
+		NumbersToStrings: true,
+		BoolStrings:      true,
+	}
+
+	whole, err := GetWithCoercion[int](value, "/whole", coercion)
+	assert.Nil(t, err, "Expected whole float to convert to int")
+	assert.Equal(t, whole, 4, "Unexpected int")
+	_, err = GetWithCoercion[int](value, "/pairs/0/x0", coercion)
+	assert.Equal(t, errors.Is(err, ErrTypeMismatch), true, "Expected fraction not to convert to int")
+
+	numStr, _ := GetWithCoercion[uint64](value, "/numStr", coercion)
+	assert.Equal(t, numStr, uint64(12), "Unexpected numeric string")
+	floatStr, _ := GetWithCoercion[float64](value, "/floatStr", coercion)
+	assert.Equal(t, floatStr, 1.25, "Unexpected numeric string")
+	numberStr, _ := GetWithCoercion[Number](value, "/floatStr", coercion)
+	assert.Equal(t, numberStr, Number("1.25"), "Unexpected numeric string Number")
+	_, err = GetWithCoercion[float64](value, "/name", coercion)
+	assert.Equal(t, errors.Is(err, ErrTypeMismatch), true, "Expected non-numeric string not to convert")
+
+	countStr, _ := GetWithCoercion[string](value, "/count", coercion)
+	assert.Equal(t, countStr, "3", "Unexpected number string")
+	flag, _ := GetWithCoercion[bool](value, "/flag", coercion)
+	assert.Equal(t, flag, true, "Unexpected bool string")
+
+	_, err = GetWithCoercion[float64](value, "/count", Coercion{})
+	assert.Equal(t, errors.Is(err, ErrTypeMismatch), true, "Expected no int to float without coercion")
+
+	// Number mode
+	number, _ := ParseJsonWithOptions(`{"big": 18446744073709551615, "n": 2.0}`, ParseOptions{UseNumber: true})
+	big, err := Get[uint64](number, "/big")
+	assert.Nil(t, err, "Expected big Number to convert to uint64")
+	assert.Equal(t, big, uint64(18446744073709551615), "Unexpected uint64")
+	_, err = Get[int64](number, "/big")
+	assert.Equal(t, errors.Is(err, ErrTypeMismatch), true, "Expected big Number not to fit int64")
+	n, _ := GetWithCoercion[int](number, "/n", coercion)
+	assert.Equal(t, n, 2, "Expected whole Number to convert to int")
+}
+
+func TestGetterErrors(t *testing.T) {
+	value, _ := runParserWithStr(`{"a": 1.5, "b": true}`)
+
+	_, err := value.GetString("missing")
+	assert.Equal(t, errors.Is(err, ErrKeyNotFound), true, "Expected key not found")
+	_, err = value.GetBool("a")
+	assert.Equal(t, errors.Is(err, ErrTypeMismatch), true, "Expected type mismatch")
+	assert.Equal(t, err.Error(), "Type mismatch: error casting number 1.5 to bool", "Unexpected error message")
+
+	// Getting a key from something that isn't an object is an error, not a panic
+	b, _ := value.GetArray("")
+	assert.Equal(t, b == nil, true, "Expected no array")
+	_, err = NewJsonValue(1).GetInt("a")
+	assert.Equal(t, errors.Is(err, ErrTypeMismatch), true, "Expected type mismatch for key on number")
+}
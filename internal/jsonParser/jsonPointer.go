@@ -0,0 +1,98 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+	JSON Pointer (RFC 6901) names a value inside a document, like "/pairs/3/x0". Each "/" starts
+	a reference token: an object key or a zero based array index. "~" & "/" in keys are escaped
+	as "~0" & "~1". The empty pointer "" is the whole document.
+
+	Used for paths in schema errors, patches & diffs.
+*/
+
+// Returns pointer with token appended, escaping it.
+func appendPointerToken(pointer string, token string) string {
+	if strings.ContainsAny(token, "~/") {
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+	}
+	return pointer + "/" + token
+}
+
+// Returns pointer with an array index appended.
+func appendPointerIndex(pointer string, index int) string {
+	return pointer + "/" + strconv.Itoa(index)
+}
+
+// Splits pointer into its unescaped reference tokens. The empty pointer has none.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		msg := fmt.Sprintf(`JSON pointer "%s" must start with "/"`, pointer)
+		return nil, errors.New(msg)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+
+		// "~" must be followed by 0 or 1
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 >= len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				msg := fmt.Sprintf(`Invalid escape in JSON pointer "%s"`, pointer)
+				return nil, errors.New(msg)
+			}
+		}
+		// Order matters, so "~01" becomes "~1" rather than "/"
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens, nil
+}
+
+// Returns the array index a reference token names, or false if it isn't a valid index. Indexes
+// are decimal with no leading zeros or sign. Doesn't check the upper bound.
+func parsePointerIndex(token string) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return 0, false
+		}
+	}
+	index, err := strconv.Atoi(token)
+	return index, err == nil
+}
+
+// Returns the value the reference tokens name inside val, or false if there isn't one.
+func lookupPointer(val any, tokens []string) (any, bool) {
+	for _, token := range tokens {
+		switch typedVal := val.(type) {
+		case map[string]any:
+			child, ok := typedVal[token]
+			if !ok {
+				return nil, false
+			}
+			val = child
+		case []any:
+			index, ok := parsePointerIndex(token)
+			if !ok || index >= len(typedVal) {
+				return nil, false
+			}
+			val = typedVal[index]
+		default:
+			return nil, false
+		}
+	}
+	return val, true
+}
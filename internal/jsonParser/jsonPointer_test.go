@@ -0,0 +1,64 @@
+package jsonParser
+
+/*
+	Tests JSON Pointer parsing & lookup.
+*/
+
+import (
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func TestJsonPointerEscapes(t *testing.T) {
+	pointer := appendPointerToken(appendPointerToken("", "a/b"), "c~d")
+	assert.Equal(t, pointer, "/a~1b/c~0d", "Unexpected escaped pointer")
+
+	tokens, err := parsePointer(pointer)
+	assert.Nil(t, err, "Expected pointer to parse")
+	assert.Equal(t, len(tokens), 2, "Unexpected token count")
+	assert.Equal(t, tokens[0], "a/b", "Unexpected token")
+	assert.Equal(t, tokens[1], "c~d", "Unexpected token")
+
+	tokens, _ = parsePointer("/~01")
+	assert.Equal(t, tokens[0], "~1", "Expected ~0 to be unescaped last")
+
+	tokens, _ = parsePointer("")
+	assert.Equal(t, len(tokens), 0, "Expected root pointer to have no tokens")
+	tokens, _ = parsePointer("/")
+	assert.Equal(t, len(tokens), 1, "Expected empty key token")
+
+	for _, invalid := range []string{"a", "/a~", "/a~2"} {
+		_, err := parsePointer(invalid)
+		assert.NotNil(t, err, "Expected error for "+invalid)
+	}
+}
+
+func TestJsonPointerLookup(t *testing.T) {
+	jsonResult, _ := ParseJson(`{"a": [10, {"b": "c"}], "": 1}`)
+
+	tests := []struct {
+		pointer  string
+		expected any
+		found    bool
+	}{
+		{"/a/0", 10, true},
+		{"/a/1/b", "c", true},
+		{"/", 1, true},
+		{"/a/2", nil, false},
+		{"/a/01", nil, false},
+		{"/a/-", nil, false},
+		{"/a/0/b", nil, false},
+		{"/missing", nil, false},
+	}
+
+	for _, test := range tests {
+		tokens, err := parsePointer(test.pointer)
+		assert.Nil(t, err, "Expected pointer to parse")
+		val, found := lookupPointer(jsonResult.tree(), tokens)
+		assert.Equal(t, found, test.found, "Unexpected lookup result for "+test.pointer)
+		if test.found {
+			assert.Equal(t, val, test.expected, "Unexpected value for "+test.pointer)
+		}
+	}
+}
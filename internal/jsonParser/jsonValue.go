@@ -3,6 +3,9 @@ package jsonParser
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 /*
@@ -33,6 +36,8 @@ import (
 	jsonResult.GetString("theString") 	// Gets "a"
 	jsonResult.GetInt("theInt") 		// Gets 1
 	jsonResult.GetFloat("theFloat") 	// Gets 2.222
+	jsonResult.GetInt64("theInt") 		// Gets 1 as an int64 (also GetUint64)
+	jsonResult.GetNumber("theFloat") 	// Gets "2.222" as a Number (see number.go)
 
 	theObj := jsonResult.GetObject("theObj") // Gets theObj as a JsonValue
 	theObj.GetString("objA")			 	 // Gets "b"
@@ -44,165 +49,440 @@ import (
 		v, _ := item.GetInt("")					// Gets 1, then 2
 	}
 	```
+
+	To find out what a value holds & walk through it:
+	```
+	jsonResult.Kind()					// KindObject
+	jsonResult.Len()					// 5 members
+	jsonResult.Keys()					// ["theArray", "theFloat", "theInt", "theObj", "theString"]
+	jsonResult.ForEach(func(key string, v *JsonValue) bool {
+		fmt.Println(key, v.Kind())		// "theArray Array", "theFloat Number", ...
+		return true						// false to stop early
+	})
+
+	theArray, _ := jsonResult.GetArray("theArray")
+	first := theArray[0]				// [1,2]
+	first.Index(1)						// Gets 2 as a JsonValue
+
+	items := first.Items()				// Iterates without allocating, unlike GetArray()
+	for items.Next() {
+		v, _ := items.Value().GetInt("")	// Gets 1, then 2
+	}
+	```
 */
 
+// A value on a tape (see tape.go).
 type JsonValue struct {
-	data any
+	tape    *tape
+	index   int          // Of the value's node on the tape
+	mapping *fileMapping // Memory mapped file the data points into, if parsed by ParseFile()
 }
 
+// Returns a JsonValue holding Go data: map[string]any, []any, string, int, float64, Number, bool
+// or nil, nested in any way. The data is copied, so changing it afterwards doesn't change the
+// JsonValue.
 func NewJsonValue(data any) *JsonValue {
-	return &JsonValue{data: data}
+	return newJsonValue(data, nil)
+}
+
+// Returns a JsonValue holding Go data, whose strings may point into the given mapping.
+func newJsonValue(data any, mapping *fileMapping) *JsonValue {
+	t := &tape{}
+	t.appendTree(data)
+	return &JsonValue{tape: t, mapping: mapping}
 }
 
-func (j *JsonValue) getKeyValue(key string) (any, error) {
-	val, ok := j.data.(map[string]any)[key]
-	if !ok {
-		msg := fmt.Sprintf(`Key "%s" not found"`, key)
-		return "", errors.New(msg)
+// Kind of value a JsonValue holds.
+type Kind string
+
+const (
+	KindObject  Kind = "Object"
+	KindArray   Kind = "Array"
+	KindString  Kind = "String"
+	KindNumber  Kind = "Number"
+	KindBool    Kind = "Bool"
+	KindNull    Kind = "Null"
+	KindInvalid Kind = "Invalid" // Data that isn't JSON, only possible through NewJsonValue()
+)
+
+// Errors from getting values, which are wrapped with the key & type. Check with errors.Is().
+var (
+	ErrKeyNotFound  = errors.New("Key not found")
+	ErrTypeMismatch = errors.New("Type mismatch")
+)
+
+// Returns s, or a copy of it if it may point into a memory mapped file. Strings handed out can
+// outlive the JsonValue, & so the mapping, so they can't point into it.
+func (j *JsonValue) ownString(s string) string {
+	if j.mapping != nil {
+		return strings.Clone(s)
 	}
+	return s
+}
 
-	return val, nil
+// Returns the JsonValue for the node at index on the same tape.
+func (j *JsonValue) at(index int) *JsonValue {
+	return &JsonValue{tape: j.tape, index: index, mapping: j.mapping}
 }
 
+// Returns the value as Go data, see tape.tree().
+func (j *JsonValue) tree() any {
+	return j.tape.tree(j.index)
+}
 
-// Returns a string for the given key, or if key is blank, returns own data as string
-func (j *JsonValue) GetString(key string) (string, error) {
-	var val any
-	var err error
+// Returns the value as Go data that doesn't point into a memory mapped file, so it can be kept
+// in a new JsonValue.
+func (j *JsonValue) ownTree() any {
+	if j.mapping != nil {
+		return cloneJsonValue(j.tree(), true)
+	}
+	return j.tree()
+}
+
+// Returns the node index of the value for the given key, or own index if key is blank.
+func (j *JsonValue) lookup(key string) (int, error) {
+	if key == "" {
+		return j.index, nil
+	}
+
+	if j.tape.nodes[j.index].kind != tapeObject {
+		return 0, j.tape.typeMismatchError(j.index, "object")
+	}
+	index := j.tape.objectLookup(j.index, key)
+	if index < 0 {
+		return 0, fmt.Errorf(`%w: "%s"`, ErrKeyNotFound, key)
+	}
+	return index, nil
+}
 
-	if key != "" {
-		val, err = j.getKeyValue(key)
-		if err != nil {
-			return "", errors.New(err.Error())
+// Returns an error for a value that isn't the requested type, which matches ErrTypeMismatch.
+func typeMismatchError(val any, typeName string) error {
+	return fmt.Errorf(`%w: error casting %s %s to %s`, ErrTypeMismatch, jsonTypeName(val), describeValue(val), typeName)
+}
+
+// Returns a short description of val for error messages, like "abc" or 1.5.
+func describeValue(val any) string {
+	switch typedVal := val.(type) {
+	case string:
+		if len(typedVal) > 32 {
+			typedVal = typedVal[:32] + "..."
 		}
-	} else {
-		val = j.data
+		return strconv.Quote(typedVal)
+	case map[string]any:
+		return "{...}"
+	case []any:
+		return "[...]"
+	case nil:
+		return "null"
 	}
+	return fmt.Sprintf("%v", val)
+}
 
-	strVal, strOk := val.(string)
-	if !strOk {
-		strMsg := fmt.Sprintf(`Error casting "%s" to string`, val)
-		return "", errors.New(strMsg)
+// Returns a string for the given key, or if key is blank, returns own data as string
+func (j *JsonValue) GetString(key string) (string, error) {
+	index, err := j.lookup(key)
+	if err != nil {
+		return "", err
 	}
-	return strVal, nil
+
+	n := j.tape.nodes[index]
+	if n.kind != tapeString {
+		return "", j.tape.typeMismatchError(index, "string")
+	}
+	return j.ownString(j.tape.str(n)), nil
 }
 
 // Returns an int for the given key, or if key is blank, returns own data as int
 func (j *JsonValue) GetInt(key string) (int, error) {
-	var val any
-	var err error
+	index, err := j.lookup(key)
+	if err != nil {
+		return 0, err
+	}
 
-	if key != "" {
-		val, err = j.getKeyValue(key)
-		if err != nil {
-			return 0, errors.New(err.Error())
+	n := j.tape.nodes[index]
+	switch n.kind {
+	case tapeInt:
+		return n.int(), nil
+	// Number mode stores the literal, so convert it
+	case tapeNumber:
+		int64Val, err := Number(j.tape.str(n)).Int64()
+		if err == nil && int64(int(int64Val)) == int64Val {
+			return int(int64Val), nil
 		}
-	} else {
-		val = j.data
 	}
 
-	intVal, intOk := val.(int)
-	if !intOk {
-		intMsg := fmt.Sprintf(`Error casting "%s" to int`, val)
-		return 0, errors.New(intMsg)
+	return 0, j.tape.typeMismatchError(index, "int")
+}
+
+// Returns an int64 for the given key, or if key is blank, returns own data as int64
+func (j *JsonValue) GetInt64(key string) (int64, error) {
+	index, err := j.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	n := j.tape.nodes[index]
+	switch n.kind {
+	case tapeInt:
+		return int64(n.int()), nil
+	case tapeNumber:
+		int64Val, err := Number(j.tape.str(n)).Int64()
+		if err == nil {
+			return int64Val, nil
+		}
+	}
+
+	return 0, j.tape.typeMismatchError(index, "int64")
+}
+
+// Returns a uint64 for the given key, or if key is blank, returns own data as uint64
+func (j *JsonValue) GetUint64(key string) (uint64, error) {
+	index, err := j.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	n := j.tape.nodes[index]
+	switch n.kind {
+	case tapeInt:
+		if n.int() >= 0 {
+			return uint64(n.int()), nil
+		}
+	case tapeNumber:
+		uint64Val, err := Number(j.tape.str(n)).Uint64()
+		if err == nil {
+			return uint64Val, nil
+		}
 	}
 
-	return intVal, nil
+	return 0, j.tape.typeMismatchError(index, "uint64")
 }
 
 // Returns a float64 for the given key, or if key is blank, returns own data as float64
 func (j *JsonValue) GetFloat(key string) (float64, error) {
-	var val any
-	var err error
+	index, err := j.lookup(key)
+	if err != nil {
+		return 0, err
+	}
 
-	if key != "" {
-		val, err = j.getKeyValue(key)
-		if err != nil {
-			return 0, errors.New(err.Error())
+	n := j.tape.nodes[index]
+	switch n.kind {
+	case tapeFloat:
+		return n.float(), nil
+	// Number mode stores the literal, so convert it
+	case tapeNumber:
+		floatVal, err := Number(j.tape.str(n)).Float64()
+		if err == nil {
+			return floatVal, nil
 		}
-	} else {
-		val = j.data
 	}
 
-	floatVal, floatOk := val.(float64)
-	if !floatOk {
-		floatMsg := fmt.Sprintf(`Error casting "%s" to float`, val)
-		return 0, errors.New(floatMsg)
+	return 0, j.tape.typeMismatchError(index, "float")
+}
+
+// Returns a Number for the given key, or if key is blank, returns own data as Number. Values
+// parsed without UseNumber are formatted back into a literal.
+func (j *JsonValue) GetNumber(key string) (Number, error) {
+	index, err := j.lookup(key)
+	if err != nil {
+		return "", err
+	}
+
+	n := j.tape.nodes[index]
+	switch n.kind {
+	case tapeNumber:
+		return Number(j.ownString(j.tape.str(n))), nil
+	case tapeInt, tapeFloat:
+		return j.tape.numberLiteral(n), nil
 	}
 
-	return floatVal, nil
+	return "", j.tape.typeMismatchError(index, "number")
 }
 
 // Returns a bool for the given key, or if the key is blank, returns own data as bool
 func (j *JsonValue) GetBool(key string) (bool, error) {
-	var val any
-	var err error
-
-	if key != "" {
-		val, err = j.getKeyValue(key)
-		if err != nil {
-			return false, errors.New(err.Error())
-		}
-	} else {
-		val = j.data
+	index, err := j.lookup(key)
+	if err != nil {
+		return false, err
 	}
 
-	boolVal, ok := val.(bool)
-	if !ok {
-		msg := fmt.Sprintf(`Error casting "%s" to float`, val)
-		return false, errors.New(msg)
+	switch j.tape.nodes[index].kind {
+	case tapeTrue:
+		return true, nil
+	case tapeFalse:
+		return false, nil
 	}
 
-	return boolVal, nil
+	return false, j.tape.typeMismatchError(index, "bool")
 }
 
 // Returns a *JsonValue for the given key, or if key is blank, returns own data as *JsonValue
 func (j *JsonValue) GetObject(key string) (*JsonValue, error) {
-	var val any
-	var err error
-
-	if key != "" {
-		val, err = j.getKeyValue(key)
-		if err != nil {
-			return nil, errors.New(err.Error())
-		}
-	} else {
-		val = j.data
+	index, err := j.lookup(key)
+	if err != nil {
+		return nil, err
 	}
 
-	objectVal, objectOk := val.(map[string]any)
-	if !objectOk {
-		objectMsg := fmt.Sprintf(`Error casting "%s" to object`, val)
-		return nil, errors.New(objectMsg)
+	if j.tape.nodes[index].kind != tapeObject {
+		return nil, j.tape.typeMismatchError(index, "object")
 	}
 
-	return &JsonValue{objectVal}, nil
+	return j.at(index), nil
 }
 
 // Returns a []*JsonValue for the given key, or if key is blank, returns own data as []*JsonValue
 func (j *JsonValue) GetArray(key string) ([]*JsonValue, error) {
-	var val any
-	var err error
+	index, err := j.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.tape.nodes[index].kind != tapeArray {
+		return nil, j.tape.typeMismatchError(index, "array")
+	}
+
+	return j.at(index).wrapArrayItems(), nil
+}
+
+// Wraps each item of the array in a JsonValue. They share 1 backing slice, rather than being
+// allocated 1 at a time.
+func (j *JsonValue) wrapArrayItems() []*JsonValue {
+	numItems := int(j.tape.nodes[j.index].payload)
+	values := make([]JsonValue, numItems)
+	resultArray := make([]*JsonValue, numItems)
+	item := j.index + 1
+	for i := range values {
+		values[i] = JsonValue{tape: j.tape, index: item, mapping: j.mapping}
+		resultArray[i] = &values[i]
+		item = j.tape.next(item)
+	}
+	return resultArray
+}
+
+// Returns the kind of value held. Ints, floats & Numbers are all KindNumber.
+func (j *JsonValue) Kind() Kind {
+	switch j.tape.nodes[j.index].kind {
+	case tapeObject:
+		return KindObject
+	case tapeArray:
+		return KindArray
+	case tapeString:
+		return KindString
+	case tapeInt, tapeFloat, tapeNumber:
+		return KindNumber
+	case tapeTrue, tapeFalse:
+		return KindBool
+	case tapeNull:
+		return KindNull
+	}
+	return KindInvalid
+}
+
+// Returns the number of members in an object or items in an array, or 0 for other kinds.
+func (j *JsonValue) Len() int {
+	n := j.tape.nodes[j.index]
+	if n.kind == tapeObject || n.kind == tapeArray {
+		return int(n.payload)
+	}
+	return 0
+}
+
+// Returns an object's keys in sorted order, or nil if it's not an object.
+func (j *JsonValue) Keys() []string {
+	n := j.tape.nodes[j.index]
+	if n.kind != tapeObject {
+		return nil
+	}
+
+	keys := make([]string, 0, n.payload)
+	for key := j.index + 1; key < j.tape.next(j.index); key = j.tape.next(key + 1) {
+		keys = append(keys, j.ownString(j.tape.str(j.tape.nodes[key])))
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Returns the array item at index i. Steps over the items before it, so use Items() to iterate.
+// Errors match ErrTypeMismatch if j isn't an array, & ErrKeyNotFound if i is out of range.
+func (j *JsonValue) Index(i int) (*JsonValue, error) {
+	n := j.tape.nodes[j.index]
+	if n.kind != tapeArray {
+		return nil, j.tape.typeMismatchError(j.index, "array")
+	}
+	item := j.tape.arrayItem(j.index, i)
+	if item < 0 {
+		return nil, fmt.Errorf("%w: index %d out of range for array of length %d", ErrKeyNotFound, i, n.payload)
+	}
+	return j.at(item), nil
+}
 
-	if key != "" {
-		val, err = j.getKeyValue(key)
-		if err != nil {
-			return nil, errors.New(err.Error())
+// Calls fn for each member of an object, in key order, or each item of an array, with its index
+// as the key. Stops early if fn returns false. Does nothing for other kinds.
+func (j *JsonValue) ForEach(fn func(key string, v *JsonValue) bool) {
+	switch j.tape.nodes[j.index].kind {
+	case tapeObject:
+		keyIndexes := make([]int, 0, j.tape.nodes[j.index].payload)
+		for key := j.index + 1; key < j.tape.next(j.index); key = j.tape.next(key + 1) {
+			keyIndexes = append(keyIndexes, key)
+		}
+		slices.SortFunc(keyIndexes, func(a, b int) int {
+			return strings.Compare(j.tape.str(j.tape.nodes[a]), j.tape.str(j.tape.nodes[b]))
+		})
+		for _, key := range keyIndexes {
+			if !fn(j.ownString(j.tape.str(j.tape.nodes[key])), j.at(key+1)) {
+				return
+			}
+		}
+	case tapeArray:
+		i := 0
+		for item := j.index + 1; item < j.tape.next(j.index); item = j.tape.next(item) {
+			if !fn(strconv.Itoa(i), j.at(item)) {
+				return
+			}
+			i += 1
 		}
-	} else {
-		val = j.data
 	}
+}
+
+// Iterates over an array's items without allocating. The value returned by Value() is reused
+// for each item, so get what you need out of it before calling Next() again.
+type ArrayIterator struct {
+	next  int // Node index of the next item
+	end   int // Node index past the last item
+	pos   int
+	value JsonValue
+}
 
-	arrayVal, arrayOk := val.([]any)
-	if !arrayOk {
-		arrayMsg := fmt.Sprintf(`Error casting "%s" to array`, val)
-		return nil, errors.New(arrayMsg)
+// Returns an iterator over an array's items. It's empty if j isn't an array.
+func (j *JsonValue) Items() ArrayIterator {
+	it := ArrayIterator{
+		pos:   -1,
+		value: JsonValue{tape: j.tape, mapping: j.mapping},
 	}
+	if j.tape.nodes[j.index].kind == tapeArray {
+		it.next = j.index + 1
+		it.end = j.tape.next(j.index)
+	}
+	return it
+}
 
-	resultArray := make([]*JsonValue, len(arrayVal))
-	for i, v := range arrayVal {
-		resultArray[i] = &JsonValue{v}
+// Moves to the next item, returning false when there are no more.
+func (it *ArrayIterator) Next() bool {
+	if it.next >= it.end {
+		return false
 	}
+	it.pos += 1
+	it.value.index = it.next
+	it.next = it.value.tape.next(it.next)
+	return true
+}
+
+// Returns the current item. Only valid until the next call to Next().
+func (it *ArrayIterator) Value() *JsonValue {
+	return &it.value
+}
 
-	return resultArray, nil
+// Returns the index of the current item.
+func (it *ArrayIterator) Index() int {
+	return it.pos
 }
@@ -0,0 +1,105 @@
+package jsonParser
+
+/*
+	Tests JsonValue introspection & iteration.
+*/
+
+import (
+	"errors"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func TestJsonValueKind(t *testing.T) {
+	tests := map[string]Kind{
+		`{"a": 1}`: KindObject,
+		`[1]`:      KindArray,
+		`"a"`:      KindString,
+		`1`:        KindNumber,
+		`1.5`:      KindNumber,
+		`true`:     KindBool,
+		`null`:     KindNull,
+	}
+	for input, expected := range tests {
+		value, _ := runParserWithStr(input)
+		assert.Equal(t, value.Kind(), expected, "Unexpected kind for "+input)
+	}
+
+	number, _ := ParseJsonWithOptions(`1`, ParseOptions{UseNumber: true})
+	assert.Equal(t, number.Kind(), KindNumber, "Expected Number to be KindNumber")
+	assert.Equal(t, NewJsonValue(struct{}{}).Kind(), KindInvalid, "Expected non-JSON data to be KindInvalid")
+}
+
+func TestJsonValueLenKeysIndex(t *testing.T) {
+	value, _ := runParserWithStr(`{"b": [1, 2, 3], "a": "xyz", "c": {}}`)
+	assert.Equal(t, value.Len(), 3, "Unexpected object length")
+
+	keys := value.Keys()
+	assert.Equal(t, len(keys), 3, "Unexpected key count")
+	assert.Equal(t, keys[0]+keys[1]+keys[2], "abc", "Expected sorted keys")
+
+	arr, _ := value.GetArray("b")
+	assert.Equal(t, NewJsonValue([]any{1, 2}).Len(), 2, "Unexpected array length")
+	s, _ := value.GetObject("c")
+	assert.Equal(t, s.Len(), 0, "Expected empty object length")
+	assert.Equal(t, arr[0].Len(), 0, "Expected 0 length for number")
+	assert.Equal(t, arr[0].Keys() == nil, true, "Expected no keys for number")
+
+	array := NewJsonValue([]any{10, "x"})
+	item, err := array.Index(1)
+	assert.Nil(t, err, "Expected item")
+	str, _ := item.GetString("")
+	assert.Equal(t, str, "x", "Unexpected item")
+	_, err = array.Index(2)
+	assert.Equal(t, errors.Is(err, ErrKeyNotFound), true, "Expected out of range error")
+	_, err = array.Index(-1)
+	assert.Equal(t, errors.Is(err, ErrKeyNotFound), true, "Expected out of range error")
+	_, err = value.Index(0)
+	assert.Equal(t, errors.Is(err, ErrTypeMismatch), true, "Expected error indexing object")
+}
+
+func TestJsonValueForEach(t *testing.T) {
+	value, _ := runParserWithStr(`{"b": 2, "a": 1, "c": 3}`)
+	keys := ""
+	sum := 0
+	value.ForEach(func(key string, v *JsonValue) bool {
+		keys += key
+		n, _ := v.GetInt("")
+		sum += n
+		return key != "b"
+	})
+	assert.Equal(t, keys, "ab", "Expected key order & early stop")
+	assert.Equal(t, sum, 3, "Unexpected values")
+
+	array, _ := runParserWithStr(`[5, 6]`)
+	keys = ""
+	array.ForEach(func(key string, v *JsonValue) bool {
+		keys += key
+		return true
+	})
+	assert.Equal(t, keys, "01", "Expected indexes as keys")
+}
+
+func TestJsonValueItems(t *testing.T) {
+	value, _ := runParserWithStr(`[{"x": 1}, {"x": 2}, {"x": 3}]`)
+
+	sum := 0
+	items := value.Items()
+	for items.Next() {
+		x, _ := items.Value().GetInt("x")
+		sum += x * (items.Index() + 1)
+	}
+	assert.Equal(t, sum, 14, "Unexpected item values or indexes")
+
+	empty := NewJsonValue("not an array").Items()
+	assert.Equal(t, empty.Next(), false, "Expected no items for non-array")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		items := value.Items()
+		for items.Next() {
+			items.Value().GetInt("x")
+		}
+	})
+	assert.Equal(t, allocs, 0.0, "Expected iteration not to allocate")
+}
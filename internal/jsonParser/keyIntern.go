@@ -0,0 +1,83 @@
+package jsonParser
+
+/*
+	Object keys are interned while parsing, so a key repeated across many objects (like the x0,
+	y0, x1 & y1 of every pair) is stored in the tape's string table once, & every key node with
+	it shares 1 string index. Without this, the table grows by 1 string per member.
+
+	The intern table is a small direct mapped cache, made per parse: each key hashes to 1 slot,
+	& a key that misses replaces whatever was in its slot. It never grows, so documents with lots
+	of distinct keys (like an object used as a dictionary) only pay for the hashing.
+
+	Keys known ahead of time can be seeded with ParseOptions.ExpectedKeys:
+
+	```
+	options := jsonParser.DefaultParseOptions()
+	options.ExpectedKeys = []string{"pairs", "x0", "y0", "x1", "y1"}
+	jsonResult, err := jsonParser.ParseJsonWithOptions(fileData, options)
+	```
+
+	A seeded key that's found is stored as the given string rather than a slice of the input, so
+	a JsonValue holding only seeded keys doesn't keep key bytes of the input alive. Seeds that
+	hash to the same slot replace each other, so only seed a handful.
+
+	Hits & lookups are added to the "Parser.KeyIntern" profiler counter.
+*/
+
+// Number of slots in the intern table. Must be a power of 2.
+const KEY_INTERN_SLOTS = 64
+
+const (
+	internEmpty uint8 = iota
+	internSeeded
+	internOnTape
+)
+
+type internSlot struct {
+	key   string
+	index uint64 // Index of key in the tape's strings, once it's on the tape
+	state uint8
+}
+
+type keyInterner struct {
+	slots  [KEY_INTERN_SLOTS]internSlot
+	hits   uint64
+	misses uint64
+}
+
+// FNV-1a hash of key, reduced to a slot index.
+func internSlotIndex(key string) int {
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return int(hash & (KEY_INTERN_SLOTS - 1))
+}
+
+// Fills the table with keys the document is expected to have.
+func (k *keyInterner) seed(keys []string) {
+	for _, key := range keys {
+		k.slots[internSlotIndex(key)] = internSlot{key: key, state: internSeeded}
+	}
+}
+
+// Appends a key node for key to the tape, sharing the string of an earlier equal key if it's
+// still in the table.
+func (k *keyInterner) appendKey(t *tape, key string) {
+	slot := &k.slots[internSlotIndex(key)]
+	if slot.state != internEmpty && slot.key == key {
+		k.hits += 1
+		if slot.state == internSeeded {
+			slot.index = uint64(len(t.strings))
+			slot.state = internOnTape
+			t.strings = append(t.strings, slot.key)
+		}
+		t.appendNode(tapeKey, slot.index)
+		return
+	}
+
+	k.misses += 1
+	*slot = internSlot{key: key, index: uint64(len(t.strings)), state: internOnTape}
+	t.appendString(tapeKey, key)
+}
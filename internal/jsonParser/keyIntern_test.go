@@ -0,0 +1,92 @@
+package jsonParser
+
+/*
+	Tests interning repeated object keys on the tape, with & without seeded keys.
+*/
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func TestKeyInternSharesRepeatedKeys(t *testing.T) {
+	value, err := runParserWithStr(`[{"x0": 1, "y0": 2}, {"x0": 3, "y0": 4}, {"y0": 6, "x0": 5}]`)
+	assert.Nil(t, err, "Expected JSON to parse")
+	assert.Equal(t, strings.Join(value.tape.strings, ","), "x0,y0", "Expected each key to be stored once")
+
+	for i, expected := range []int{1, 3, 5} {
+		x0, err := Get[int](value, fmt.Sprintf("/%d/x0", i))
+		assert.Nil(t, err, "Expected interned key to be found")
+		assert.Equal(t, x0, expected, fmt.Sprintf("Unexpected x0 for item %d", i))
+	}
+
+	// Strings that look like keys aren't interned
+	value, _ = runParserWithStr(`[{"a": "a"}, {"a": "a"}]`)
+	assert.Equal(t, strings.Join(value.tape.strings, ","), "a,a,a", "Expected only keys to be shared")
+
+	assert.Finished()
+}
+
+func TestKeyInternSeededKeys(t *testing.T) {
+	options := DefaultParseOptions()
+	x0 := strings.Clone("x0")
+	options.ExpectedKeys = []string{"pairs", x0, "unused"}
+	value, err := ParseJsonWithOptions(`{"pairs": [{"x0": 1.5, "other": 2}, {"x0": 2.5, "other": 3}]}`, options)
+	assert.Nil(t, err, "Expected JSON to parse")
+
+	// Unused seeds never reach the tape, & used ones are the given strings
+	assert.Equal(t, strings.Join(value.tape.strings, ","), "pairs,x0,other", "Expected only used keys on the tape")
+	assert.Equal(t, unsafe.StringData(value.tape.strings[1]), unsafe.StringData(x0), "Expected seeded key to be stored")
+	sum := 0.0
+	pairs, _ := value.GetArray("pairs")
+	for _, pair := range pairs {
+		x0, _ := pair.GetFloat("x0")
+		sum += x0
+	}
+	assert.Equal(t, sum, 4.0, "Unexpected sum of seeded key values")
+
+	// Parallel chunks seed their own tables
+	assertParallelMatches(t, `{"pairs": `+makePairsArray(1000)+`}`, options)
+
+	assert.Finished()
+}
+
+func TestKeyInternManyKeys(t *testing.T) {
+	// More keys than slots, so some evict others, & then are repeated
+	numKeys := KEY_INTERN_SLOTS * 3
+	var sb strings.Builder
+	sb.WriteString("[")
+	for object := 0; object < 2; object++ {
+		if object > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("{")
+		for i := 0; i < numKeys; i++ {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, `"k%d": %d`, i, i*(object+1))
+		}
+		sb.WriteString("}")
+	}
+	sb.WriteString("]")
+
+	value, err := runParserWithStr(sb.String())
+	assert.Nil(t, err, "Expected JSON to parse")
+	assert.Equal(t, len(value.tape.strings) < numKeys*2, true, "Expected some keys to be shared")
+	for object := 0; object < 2; object++ {
+		item, _ := value.Index(object)
+		assert.Equal(t, item.Len(), numKeys, fmt.Sprintf("Unexpected number of keys in object %d", object))
+		for i := 0; i < numKeys; i++ {
+			k, err := item.GetInt(fmt.Sprintf("k%d", i))
+			assert.Nil(t, err, "Expected every key to be found")
+			assert.Equal(t, k, i*(object+1), fmt.Sprintf("Unexpected value for k%d in object %d", i, object))
+		}
+	}
+
+	assert.Finished()
+}
@@ -3,6 +3,8 @@ package jsonParser
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"tmelot.jsonparser/internal/profiler"
 )
@@ -23,6 +25,7 @@ const JSON_SYNTAX_COMMA = ","
 const JSON_SYNTAX_QUOTE = "\""
 const JSON_SYNTAX_BOOL_TRUE = "true"
 const JSON_SYNTAX_BOOL_FALSE = "false"
+const JSON_SYNTAX_NULL = "null"
 
 // Identifies which type of JSON syntax the token represents
 type TokenType string
@@ -37,18 +40,26 @@ const (
 	JsonString          TokenType = "String"
 	JsonNumber          TokenType = "Number"
 	JsonBool            TokenType = "Bool"
+	JsonNull            TokenType = "Null"
+	JsonIdentifier      TokenType = "Identifier" // Unquoted key, relaxed mode only
+	JsonInvalid         TokenType = "Invalid"    // Text that couldn't be lexed, diagnostic mode only
 )
 
 // Represents a lexed token
 type Token struct {
 	Type  TokenType
 	Value string
+	Pos   int // Byte offset of the token's first character
 }
 
 type Lexer struct {
-	Debug bool
-	data  string
-	pos   int
+	Debug       bool
+	data        string
+	pos         int
+	options     ParseOptions
+	recover     bool          // Diagnostic mode, see diagnostics.go
+	diagnostics []*ParseError // Errors recovered from in diagnostic mode
+	noProfile   bool          // Set on worker goroutines, the global profiler isn't goroutine safe
 }
 
 // Create & return a new Lexer instance
@@ -68,7 +79,9 @@ func (l *Lexer) getUnlexedData() string {
 // the actual lexing only return the number of characters consumed, which lex() uses to advance
 // the position.
 func (l *Lexer) lex() ([]Token, error) {
-	profiler.GlobalProfiler.StartBlock("Parser.Lex")
+	if !l.noProfile {
+		profiler.GlobalProfiler.StartBlock("Parser.Lex")
+	}
 	var tokens []Token
 
 	for l.pos < len(l.data) {
@@ -77,6 +90,10 @@ func (l *Lexer) lex() ([]Token, error) {
 		// Lex JSON syntax
 		syntaxToken, syntaxCharsRead := l.lexJsonSyntax()
 		if syntaxCharsRead > 0 {
+			if err := l.checkTokenLimit(len(tokens)); err != nil {
+				return tokens, err
+			}
+			syntaxToken.Pos = l.pos
 			tokens = append(tokens, *syntaxToken)
 			l.pos += syntaxCharsRead
 			continue
@@ -89,12 +106,37 @@ func (l *Lexer) lex() ([]Token, error) {
 			continue
 		}
 
+		// Lex comments & extra whitespace in relaxed mode (also ignored)
+		if l.options.Relaxed {
+			relaxedCharsRead, err := l.lexRelaxedWhitespace()
+			if err != nil {
+				// Unclosed block comment, which runs to the end
+				if !l.recover {
+					return tokens, err
+				}
+				l.recordDiagnostic(err)
+				relaxedCharsRead = len(l.getUnlexedData())
+			}
+			if relaxedCharsRead > 0 {
+				l.pos += relaxedCharsRead
+				continue
+			}
+		}
+
 		// Lex strings
 		stringToken, stringCharsRead, err := l.lexString()
 		if err != nil {
-			return tokens, err
+			if !l.recover || isLimitError(err) {
+				return tokens, err
+			}
+			l.recordDiagnostic(err)
+			stringToken, stringCharsRead = l.recoverString()
 		}
 		if stringCharsRead > 0 {
+			if err := l.checkTokenLimit(len(tokens)); err != nil {
+				return tokens, err
+			}
+			stringToken.Pos = l.pos
 			tokens = append(tokens, *stringToken)
 			l.pos += stringCharsRead
 			continue
@@ -102,31 +144,93 @@ func (l *Lexer) lex() ([]Token, error) {
 
 		// Lex numbers
 		// NOTE: Numbers are read as strings. Later the parser will convert to correct data type.
-		numberToken, numberCharsRead := l.lexNumber()
+		var numberToken *Token
+		var numberCharsRead int
+		if l.options.Relaxed {
+			numberToken, numberCharsRead = l.lexRelaxedNumber()
+		}
+		if numberCharsRead == 0 {
+			numberToken, numberCharsRead = l.lexNumber()
+		}
 		if numberCharsRead > 0 {
+			if err := l.checkTokenLimit(len(tokens)); err != nil {
+				return tokens, err
+			}
+			numberToken.Pos = l.pos
 			tokens = append(tokens, *numberToken)
 			l.pos += numberCharsRead
 			continue
 		}
 
+		// Lex identifiers in relaxed mode. This also lexes bools, so that keys like "trueish"
+		// aren't split into a bool & an identifier.
+		if l.options.Relaxed {
+			identifierToken, identifierCharsRead := l.lexIdentifier()
+			if identifierCharsRead > 0 {
+				if err := l.checkTokenLimit(len(tokens)); err != nil {
+					return tokens, err
+				}
+				identifierToken.Pos = l.pos
+				tokens = append(tokens, *identifierToken)
+				l.pos += identifierCharsRead
+				continue
+			}
+		}
+
 		// Lex bools
 		boolToken, boolCharsRead := l.lexBool()
 		if boolCharsRead > 0 {
+			if err := l.checkTokenLimit(len(tokens)); err != nil {
+				return tokens, err
+			}
+			boolToken.Pos = l.pos
 			tokens = append(tokens, *boolToken)
 			l.pos += boolCharsRead
 			continue
 		}
 
-		// TODO: Lex null?
+		// Lex null
+		nullToken, nullCharsRead := l.lexNull()
+		if nullCharsRead > 0 {
+			if err := l.checkTokenLimit(len(tokens)); err != nil {
+				return tokens, err
+			}
+			nullToken.Pos = l.pos
+			tokens = append(tokens, *nullToken)
+			l.pos += nullCharsRead
+			continue
+		}
 
-		err = errors.New(fmt.Sprintf("Unexpected character \"%s\"", l.getUnlexedData()))
-		return tokens, err
+		invalidCharsRead := invalidTextLength(l.getUnlexedData(), l.options.Relaxed)
+		invalidText := l.getUnlexedData()[:invalidCharsRead]
+		err = newParseError(l.data, l.pos, errors.New(fmt.Sprintf("Unexpected character \"%s\"", invalidText)))
+		if !l.recover {
+			return tokens, err
+		}
+
+		// Diagnostic mode keeps going, with a token the parser can use as a placeholder value
+		l.recordDiagnostic(err)
+		if err := l.checkTokenLimit(len(tokens)); err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, Token{Type: JsonInvalid, Value: invalidText, Pos: l.pos})
+		l.pos += invalidCharsRead
 	}
 
-	profiler.GlobalProfiler.EndBlock("Parser.Lex")
+	if !l.noProfile {
+		profiler.GlobalProfiler.EndBlock("Parser.Lex")
+	}
 	return tokens, nil
 }
 
+// Returns an error if adding another token would go over the MaxTokens limit.
+func (l *Lexer) checkTokenLimit(numTokens int) error {
+	if l.options.MaxTokens > 0 && numTokens >= l.options.MaxTokens {
+		return newParseError(l.data, l.pos, ErrMaxTokensExceeded)
+	}
+	return nil
+}
+
 // Scans for consecutive whitespace & returns number of characters consumed.
 // (Whitespace is thrown away)
 func (l *Lexer) lexJsonWhitespace() int {
@@ -187,51 +291,115 @@ func (l *Lexer) lexJsonSyntax() (*Token, int) {
 }
 
 // Scans for strings (like "a_string") & returns it along with number of characters consumed.
+// Handles escapes, rejects unescaped control characters, & deals with invalid UTF-8 according to
+// the InvalidUTF8 option.
 func (l *Lexer) lexString() (*Token, int, error) {
 	s := l.getUnlexedData()
-	lexedStr := ""
-	numCharsRead := 0
 
-	// Read past starting quote
-	if string(s[0]) == JSON_SYNTAX_QUOTE {
-		s = s[1:]
-		numCharsRead += 1
-	} else {
+	// Read past starting quote. Relaxed mode also allows single quotes.
+	quote := s[0]
+	if quote != JSON_SYNTAX_QUOTE[0] && !(l.options.Relaxed && quote == JSON5_SYNTAX_SINGLE_QUOTE[0]) {
 		l.DebugPrintf("%s is not a string\n", string(s[0]))
-		return nil, numCharsRead, nil
+		return nil, 0, nil
 	}
 
-	// Scan string until we find closing quote
-	for _, c := range s {
-		l.DebugPrintf("Checking %c for string...\n", c)
-		if string(c) == JSON_SYNTAX_QUOTE {
-			numCharsRead += 1
-			l.DebugPrintf("Returning lexed string %s\n", lexedStr)
-			return &Token{Type: JsonString, Value: lexedStr}, numCharsRead, nil
-		} else {
-			numCharsRead += 1
-			lexedStr += string(c)
-			l.DebugPrintln("lexedStr =", lexedStr)
+	// Most strings have no escapes or bad bytes, so the value is just a slice of the data. Only
+	// build a new string once something has to change. segmentStart tracks the start of bytes
+	// that haven't been copied into the builder yet.
+	var sb strings.Builder
+	building := false
+	segmentStart := 1
+	i := 1
+
+	for {
+		i = skipPlainStringBytes(s, i, quote)
+		if i >= len(s) {
+			break
+		}
+
+		c := s[i]
+		switch {
+		// End of string
+		case c == quote:
+			value := s[segmentStart:i]
+			if building {
+				sb.WriteString(value)
+				value = sb.String()
+			}
+			if l.options.MaxStringLength > 0 && len(value) > l.options.MaxStringLength {
+				return nil, i + 1, newParseError(l.data, l.pos, ErrMaxStringLengthExceeded)
+			}
+			l.DebugPrintf("Returning lexed string %s\n", value)
+			return &Token{Type: JsonString, Value: value}, i + 1, nil
+
+		// Escape sequence
+		case c == '\\':
+			sb.WriteString(s[segmentStart:i])
+			building = true
+			escapeLen, err := writeEscape(&sb, s[i:], l.options.Relaxed)
+			if err != nil {
+				return nil, i, newParseError(l.data, l.pos+i, err)
+			}
+			i += escapeLen
+			segmentStart = i
+
+		// The other kind of quote in a single quoted string
+		case c == JSON_SYNTAX_QUOTE[0]:
+			i += 1
+
+		// Control characters must be escaped
+		case c < 0x20:
+			msg := fmt.Sprintf("Unescaped control character 0x%02x in string", c)
+			return nil, i, newParseError(l.data, l.pos+i, errors.New(msg))
+
+		// Non-ASCII, must be valid UTF-8
+		default:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r != utf8.RuneError || size > 1 {
+				i += size
+				continue
+			}
+
+			switch l.options.InvalidUTF8 {
+			case InvalidUTF8Replace:
+				sb.WriteString(s[segmentStart:i])
+				sb.WriteRune(utf8.RuneError)
+				building = true
+				i += 1
+				segmentStart = i
+			case InvalidUTF8PassThrough:
+				i += 1
+			default:
+				return nil, i, newParseError(l.data, l.pos+i, ErrInvalidUTF8)
+			}
 		}
 	}
 
 	// Error becasue we ran off edge of string without finding end quote
-	err := errors.New(fmt.Sprint("End quote for string not found"))
-	return nil, numCharsRead, err
+	return nil, len(s), newParseError(l.data, l.pos, errors.New("End quote for string not found"))
 }
 
-// Scans for numbers (like "1" or "1.234") & returns it along with number of characters consumed.
+// Scans for numbers (like "1", "1.234" or "-1.2e+3") & returns it along with number of characters
+// consumed.
 func (l *Lexer) lexNumber() (*Token, int) {
 	s := l.getUnlexedData()
-	lexedStr := ""
 	numCharsRead := 0
 
+	// Numbers start with a minus or a digit. Without this check exponent characters would be
+	// lexed as numbers on their own. Relaxed mode also allows a leading plus or decimal point.
+	isStart := s[0] == '-' || (s[0] >= '0' && s[0] <= '9')
+	if l.options.Relaxed {
+		isStart = isStart || s[0] == '+' || s[0] == '.'
+	}
+	if !isStart {
+		return nil, 0
+	}
+
 	for _, c := range s {
 		l.DebugPrintf("Checking %c for number... ", c)
 		isDigit := c >= '0' && c <= '9'
-		isSymbol := c == '-' || c == '.'
+		isSymbol := c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
 		if isDigit || isSymbol {
-			lexedStr += string(c)
 			numCharsRead += 1
 		} else {
 			l.DebugPrintln("Found non number character, returning")
@@ -239,8 +407,9 @@ func (l *Lexer) lexNumber() (*Token, int) {
 		}
 	}
 
+	// Slice the number out of the data instead of building it up, so there's no allocation
 	if numCharsRead > 0 {
-		return &Token{Type: JsonNumber, Value: lexedStr}, numCharsRead
+		return &Token{Type: JsonNumber, Value: s[:numCharsRead]}, numCharsRead
 	} else {
 		return nil, 0
 	}
@@ -250,20 +419,23 @@ func (l *Lexer) lexNumber() (*Token, int) {
 func (l *Lexer) lexBool() (*Token, int) {
 	s := l.getUnlexedData()
 
-	// Not a bool if remaining string is shorter than possible bool values
-	if len(s) < len(JSON_SYNTAX_BOOL_TRUE) || len(s) < len(JSON_SYNTAX_BOOL_FALSE) {
-		return nil, 0
-	}
-
-	if s[:4] == JSON_SYNTAX_BOOL_TRUE {
+	if strings.HasPrefix(s, JSON_SYNTAX_BOOL_TRUE) {
 		return &Token{Type: JsonBool, Value: JSON_SYNTAX_BOOL_TRUE},  len(JSON_SYNTAX_BOOL_TRUE)
-	} else if s[:5] == JSON_SYNTAX_BOOL_FALSE {
+	} else if strings.HasPrefix(s, JSON_SYNTAX_BOOL_FALSE) {
 		return &Token{Type: JsonBool, Value: JSON_SYNTAX_BOOL_FALSE}, len(JSON_SYNTAX_BOOL_FALSE)
 	}
 
 	return nil, 0
 }
 
+// Scans for null & returns it with number of characters consumed.
+func (l *Lexer) lexNull() (*Token, int) {
+	if strings.HasPrefix(l.getUnlexedData(), JSON_SYNTAX_NULL) {
+		return &Token{Type: JsonNull, Value: JSON_SYNTAX_NULL}, len(JSON_SYNTAX_NULL)
+	}
+	return nil, 0
+}
+
 func (l *Lexer) DebugPrintf(format string, a ...interface{}) {
 	if l.Debug {
 		fmt.Printf(format, a...)
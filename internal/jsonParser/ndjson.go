@@ -0,0 +1,134 @@
+package jsonParser
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+/*
+	NDJSON (newline delimited JSON, a.k.a. JSON Lines) holds 1 JSON value per line, rather than
+	1 root for the whole file.
+
+	Reading:
+	```
+	reader := jsonParser.NewNdjsonReader(file, jsonParser.DefaultParseOptions())
+	for {
+		value, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err // A *ParseError with the line number
+		}
+		...
+	}
+	```
+
+	Set `reader.SkipInvalidLines = true` to skip lines that fail to parse instead of stopping.
+	Their errors are kept in `reader.SkippedErrors()`.
+
+	Writing:
+	```
+	writer := jsonParser.NewNdjsonWriter(file)
+	writer.Write(value)
+	writer.Flush()
+	```
+
+	Lines end in "\n". Readers also accept "\r\n", & skip blank lines.
+*/
+
+type NdjsonReader struct {
+	// Skip lines that fail to parse, rather than returning their error from Next().
+	SkipInvalidLines bool
+
+	reader        *bufio.Reader
+	options       ParseOptions
+	line          int // Line number of the last line read, starting at 1
+	offset        int // Byte offset of the next line
+	skippedErrors []error
+}
+
+// Creates a reader that parses each line of r with the given options.
+func NewNdjsonReader(r io.Reader, options ParseOptions) *NdjsonReader {
+	return &NdjsonReader{
+		reader:  bufio.NewReader(r),
+		options: options,
+	}
+}
+
+// Returns the value on the next non-blank line, or io.EOF when there are no more lines. Parse
+// errors are *ParseError, positioned by line & column in the whole input.
+func (r *NdjsonReader) Next() (*JsonValue, error) {
+	for {
+		// ReadString() rather than a bufio.Scanner, which has a max line length
+		line, readErr := r.reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, readErr
+		}
+		if readErr == io.EOF && line == "" {
+			return nil, io.EOF
+		}
+
+		r.line += 1
+		lineOffset := r.offset
+		r.offset += len(line)
+
+		line = strings.TrimSuffix(line, "\n")
+		line = strings.TrimSuffix(line, "\r")
+		if strings.Trim(line, JSON_SYNTAX_WHITESPACE) == "" {
+			continue
+		}
+
+		value, err := ParseJsonWithOptions(line, r.options)
+		if err == nil {
+			return value, nil
+		}
+
+		err = relocateParseError(err, lineOffset, r.line, lineOffset)
+		if !r.SkipInvalidLines {
+			return nil, err
+		}
+		r.skippedErrors = append(r.skippedErrors, err)
+	}
+}
+
+// Returns the line number of the last line read, starting at 1.
+func (r *NdjsonReader) Line() int {
+	return r.line
+}
+
+// Returns the errors for lines skipped because of SkipInvalidLines.
+func (r *NdjsonReader) SkippedErrors() []error {
+	return r.skippedErrors
+}
+
+type NdjsonWriter struct {
+	writer *bufio.Writer
+	buf    []byte // Reused between values
+}
+
+// Creates a writer that writes 1 value per line to w. Call Flush() when done.
+func NewNdjsonWriter(w io.Writer) *NdjsonWriter {
+	return &NdjsonWriter{
+		writer: bufio.NewWriter(w),
+	}
+}
+
+// Writes the value as compact JSON on its own line.
+func (w *NdjsonWriter) Write(value *JsonValue) error {
+	var err error
+	w.buf, err = value.AppendJson(w.buf[:0])
+	if err != nil {
+		return err
+	}
+	w.buf = append(w.buf, '\n')
+
+	_, err = w.writer.Write(w.buf)
+	return err
+}
+
+// Writes any buffered data to the underlying writer.
+func (w *NdjsonWriter) Flush() error {
+	return w.writer.Flush()
+}
@@ -0,0 +1,85 @@
+package jsonParser
+
+/*
+	Tests the NDJSON reader & writer.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func TestNdjsonReader(t *testing.T) {
+	input := "{\"a\": 1}\n\n{\"a\": 2}\r\n  \n{\"a\": 3}"
+	reader := NewNdjsonReader(strings.NewReader(input), DefaultParseOptions())
+
+	for expected := 1; expected <= 3; expected++ {
+		value, err := reader.Next()
+		assert.Nil(t, err, "Expected line to parse")
+		a, _ := value.GetInt("a")
+		assert.Equal(t, a, expected, "Unexpected value")
+	}
+	assert.Equal(t, reader.Line(), 5, "Expected blank lines to be counted")
+
+	_, err := reader.Next()
+	assert.Equal(t, err, io.EOF, "Expected EOF after last line")
+}
+
+func TestNdjsonReaderErrors(t *testing.T) {
+	input := "{\"a\": 1}\n{\"a\": \"bad\xff\"}\n{\"a\" 3}\n{\"a\": 4}\n"
+
+	// Stops at the first bad line
+	reader := NewNdjsonReader(strings.NewReader(input), DefaultParseOptions())
+	_, err := reader.Next()
+	assert.Nil(t, err, "Expected first line to parse")
+	_, err = reader.Next()
+	parseErr := getParseError(err)
+	assert.NotNil(t, parseErr, "Expected a ParseError")
+	assert.Equal(t, errors.Is(err, ErrInvalidUTF8), true, "Expected invalid UTF-8 error")
+	assert.Equal(t, parseErr.Line, 2, "Expected error on line 2")
+	assert.Equal(t, parseErr.Column, 11, "Expected error column within the line")
+	assert.Equal(t, parseErr.Offset, 19, "Expected error offset within the whole input")
+
+	// Skips bad lines
+	reader = NewNdjsonReader(strings.NewReader(input), DefaultParseOptions())
+	reader.SkipInvalidLines = true
+	var values []int
+	for {
+		value, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err, "Expected bad lines to be skipped")
+		a, _ := value.GetInt("a")
+		values = append(values, a)
+	}
+	assert.Equal(t, len(values), 2, "Expected 2 good lines")
+	assert.Equal(t, values[1], 4, "Expected last good line")
+	skipped := reader.SkippedErrors()
+	assert.Equal(t, len(skipped), 2, "Expected 2 skipped lines")
+	assert.Equal(t, getParseError(skipped[1]).Line, 3, "Expected skipped error on line 3")
+}
+
+func TestNdjsonWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewNdjsonWriter(&buf)
+	for _, data := range []any{map[string]any{"x0": 1.5, "y0": -2}, map[string]any{"s": "a\nb"}} {
+		err := writer.Write(NewJsonValue(data))
+		assert.Nil(t, err, "Expected value to write")
+	}
+	err := writer.Flush()
+	assert.Nil(t, err, "Expected flush to succeed")
+	assert.Equal(t, buf.String(), "{\"x0\":1.5,\"y0\":-2}\n{\"s\":\"a\\nb\"}\n", "Unexpected NDJSON output")
+
+	// Reads back what it wrote
+	reader := NewNdjsonReader(&buf, DefaultParseOptions())
+	value, err := reader.Next()
+	assert.Nil(t, err, "Expected written line to parse")
+	x0, _ := value.GetFloat("x0")
+	assert.Equal(t, x0, 1.5, "Expected written value to read back")
+}
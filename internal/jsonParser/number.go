@@ -0,0 +1,274 @@
+package jsonParser
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+/*
+	Number holds the original text of a JSON number literal, exactly as it appeared in the input.
+
+	The default parser converts numbers to int or float64 as it goes, which overflows on big IDs
+	& rounds precision-sensitive decimals. Parsing with `ParseOptions{UseNumber: true}` keeps
+	every number as a Number instead, & the caller decides how to convert it:
+
+	```
+	n, _ := jsonResult.GetNumber("id")
+	n.String()   // "18446744073709551615", untouched
+	n.Uint64()   // 18446744073709551615
+	n.Int64()    // Error, out of range
+	n.BigInt()   // Arbitrary size integer
+	n.BigFloat() // Arbitrary precision float
+	```
+*/
+
+type Number string
+
+// Minimum precision (in bits) used by BigFloat(). Longer literals get more.
+const NUMBER_MIN_BIG_FLOAT_PREC = 64
+
+// Returns the original number literal.
+func (n Number) String() string {
+	return string(n)
+}
+
+// Returns an error if n isn't a JSON number literal, so conversions don't accept other syntax Go
+// does, like "inf", "0x1p3", "1_0" or "+1".
+func (n Number) checkLiteral() error {
+	if !isValidJsonNumber(string(n)) {
+		msg := fmt.Sprintf(`Error converting "%s", it is not a JSON number`, n)
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// Returns the number as an int64, or an error if it has a fraction/exponent or is out of range.
+func (n Number) Int64() (int64, error) {
+	if err := n.checkLiteral(); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// Returns the number as a uint64, or an error if it's negative, has a fraction/exponent or is
+// out of range.
+func (n Number) Uint64() (uint64, error) {
+	if err := n.checkLiteral(); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(n), 10, 64)
+}
+
+// Returns the number as the nearest float64. Infinity & NaN, which relaxed mode makes Numbers
+// for, are +/-Inf & NaN.
+func (n Number) Float64() (float64, error) {
+	unsigned := strings.TrimPrefix(string(n), "-")
+	if !isRelaxedSpecialNumber(unsigned) {
+		if err := n.checkLiteral(); err != nil {
+			return 0, err
+		}
+	}
+	return parseFloat64(n)
+}
+
+// Returns the number as a big.Int. Works for any size of integer, including ones written with
+// a fraction or exponent as long as the value is integral (like "1.5e3").
+func (n Number) BigInt() (*big.Int, error) {
+	if err := n.checkLiteral(); err != nil {
+		return nil, err
+	}
+	if i, ok := new(big.Int).SetString(string(n), 10); ok {
+		return i, nil
+	}
+
+	// Fall back to an exact rational to handle fractions & exponents
+	r, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		msg := fmt.Sprintf(`Error converting "%s" to big int`, n)
+		return nil, errors.New(msg)
+	}
+	if !r.IsInt() {
+		msg := fmt.Sprintf(`Error converting "%s" to big int, it is not an integer`, n)
+		return nil, errors.New(msg)
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
+
+// Returns the number as a big.Float. Precision scales with the literal's length (~3.3 bits per
+// digit) so no digits written in the input are lost.
+func (n Number) BigFloat() (*big.Float, error) {
+	if err := n.checkLiteral(); err != nil {
+		return nil, err
+	}
+	prec := uint(len(n)) * 4
+	if prec < NUMBER_MIN_BIG_FLOAT_PREC {
+		prec = NUMBER_MIN_BIG_FLOAT_PREC
+	}
+
+	f, _, err := big.ParseFloat(string(n), 10, prec, big.ToNearestEven)
+	if err != nil {
+		msg := fmt.Sprintf(`Error converting "%s" to big float: %s`, n, err)
+		return nil, errors.New(msg)
+	}
+	return f, nil
+}
+
+// Returns true if s is a valid JSON number literal per RFC 8259:
+//
+//	[ minus ] int [ frac ] [ exp ]
+func isValidJsonNumber(s string) bool {
+	i := 0
+
+	// Optional minus
+	if i < len(s) && s[i] == '-' {
+		i += 1
+	}
+
+	// Int part: single 0, or a non-zero digit followed by any digits
+	if i >= len(s) {
+		return false
+	}
+	if s[i] == '0' {
+		i += 1
+	} else if s[i] >= '1' && s[i] <= '9' {
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i += 1
+		}
+	} else {
+		return false
+	}
+
+	// Fraction part: "." followed by at least 1 digit
+	if i < len(s) && s[i] == '.' {
+		i += 1
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i += 1
+		}
+		if i == start {
+			return false
+		}
+	}
+
+	// Exponent part: "e" or "E", optional sign, at least 1 digit
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i += 1
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i += 1
+		}
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i += 1
+		}
+		if i == start {
+			return false
+		}
+	}
+
+	return i == len(s)
+}
+
+// Max exponent parseDecimalParts() handles, so adding up the decimal point can't overflow.
+const DECIMAL_MAX_EXPONENT = math.MaxInt32
+
+// A JSON number literal split up so literals with the same value have the same parts: 1, 1.0,
+// 10e-1 & 0.1e1 are all digits "1" with point 1.
+type decimalParts struct {
+	digits string // From the first to the last non-zero digit of the mantissa, so may include "."
+	point  int    // The value is 0.digits * 10^point
+	neg    bool
+}
+
+// Splits up a JSON number literal. Returns false if it isn't valid, or its exponent is past
+// DECIMAL_MAX_EXPONENT. Zero has no digits, & isn't negative, so -0 & 0 are the same.
+func parseDecimalParts(s string) (decimalParts, bool) {
+	if !isValidJsonNumber(s) {
+		return decimalParts{}, false
+	}
+
+	start := 0
+	neg := s[0] == '-'
+	if neg {
+		start = 1
+	}
+	mantissaEnd := strings.IndexAny(s, "eE")
+	exp := 0
+	if mantissaEnd < 0 {
+		mantissaEnd = len(s)
+	} else {
+		var err error
+		exp, err = strconv.Atoi(s[mantissaEnd+1:])
+		if err != nil || exp > DECIMAL_MAX_EXPONENT || exp < -DECIMAL_MAX_EXPONENT {
+			return decimalParts{}, false
+		}
+	}
+
+	// Trim zeros from both ends, along with the "." if it's among them
+	mantissa := s[start:mantissaEnd]
+	intDigits := strings.IndexByte(mantissa, '.')
+	if intDigits < 0 {
+		intDigits = len(mantissa)
+	}
+	first := 0
+	for first < len(mantissa) && (mantissa[first] == '0' || mantissa[first] == '.') {
+		first += 1
+	}
+	end := len(mantissa)
+	for end > first && (mantissa[end-1] == '0' || mantissa[end-1] == '.') {
+		end -= 1
+	}
+	if first == end {
+		return decimalParts{}, true
+	}
+
+	// Digits trimmed from the front move the point left
+	trimmedDigits := first
+	if first > intDigits {
+		trimmedDigits -= 1
+	}
+	return decimalParts{digits: mantissa[first:end], point: intDigits - trimmedDigits + exp, neg: neg}, true
+}
+
+// Returns true if d & other are the same value.
+func (d decimalParts) equal(other decimalParts) bool {
+	if d.neg != other.neg || d.point != other.point {
+		return false
+	}
+	i, j := 0, 0
+	for {
+		if i < len(d.digits) && d.digits[i] == '.' {
+			i += 1
+		}
+		if j < len(other.digits) && other.digits[j] == '.' {
+			j += 1
+		}
+		if i == len(d.digits) || j == len(other.digits) {
+			return i == len(d.digits) && j == len(other.digits)
+		}
+		if d.digits[i] != other.digits[j] {
+			return false
+		}
+		i += 1
+		j += 1
+	}
+}
+
+// Appends the digits without any ".", with their count first.
+func (d decimalParts) appendDigits(b []byte) []byte {
+	count := len(d.digits)
+	if strings.IndexByte(d.digits, '.') >= 0 {
+		count -= 1
+	}
+	b = binary.LittleEndian.AppendUint64(b, uint64(count))
+	for i := 0; i < len(d.digits); i++ {
+		if d.digits[i] != '.' {
+			b = append(b, d.digits[i])
+		}
+	}
+	return b
+}
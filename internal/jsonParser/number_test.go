@@ -0,0 +1,146 @@
+package jsonParser
+
+/*
+	Tests the Number type & number mode parsing.
+*/
+
+import (
+	"math"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func runParserWithNumbers(s string) (*JsonValue, error) {
+	return ParseJsonWithOptions(s, ParseOptions{UseNumber: true})
+}
+
+func TestNumberValidLiterals(t *testing.T) {
+	valid := []string{"0", "-0", "1", "-1", "123", "1.5", "-1.5", "0.001", "1e10", "1E10", "1e+10", "1e-10", "-1.25E-3"}
+	for _, s := range valid {
+		assert.Equal(t, isValidJsonNumber(s), true, "Expected "+s+" to be a valid number")
+	}
+
+	invalid := []string{"", "-", "01", "-01", "1.", ".1", "1e", "1e+", "+1", "--1", "1-2", "1.2.3", "1ee2"}
+	for _, s := range invalid {
+		assert.Equal(t, isValidJsonNumber(s), false, "Expected "+s+" to be an invalid number")
+	}
+}
+
+func TestNumberConversions(t *testing.T) {
+	// Int64 & Uint64 bounds
+	i, err := Number("-9223372036854775808").Int64()
+	assert.Nil(t, err, "Expected min int64 to convert")
+	assert.Equal(t, i, int64(-9223372036854775808), "Expected min int64")
+
+	u, err := Number("18446744073709551615").Uint64()
+	assert.Nil(t, err, "Expected max uint64 to convert")
+	assert.Equal(t, u, uint64(18446744073709551615), "Expected max uint64")
+
+	_, err = Number("18446744073709551615").Int64()
+	assert.NotNil(t, err, "Expected max uint64 to overflow int64")
+
+	_, err = Number("-1").Uint64()
+	assert.NotNil(t, err, "Expected negative number to fail uint64")
+
+	_, err = Number("1.5").Int64()
+	assert.NotNil(t, err, "Expected fraction to fail int64")
+
+	// Float64
+	f, err := Number("-1.25e-3").Float64()
+	assert.Nil(t, err, "Expected float to convert")
+	assert.Equal(t, f, -0.00125, "Expected float value")
+
+	// BigInt, including integral values written with a fraction or exponent
+	b, err := Number("123456789012345678901234567890").BigInt()
+	assert.Nil(t, err, "Expected big int to convert")
+	assert.Equal(t, b.String(), "123456789012345678901234567890", "Expected big int value")
+
+	b, err = Number("1.5e3").BigInt()
+	assert.Nil(t, err, "Expected integral exponent to convert to big int")
+	assert.Equal(t, b.String(), "1500", "Expected big int value")
+
+	_, err = Number("1.5").BigInt()
+	assert.NotNil(t, err, "Expected fraction to fail big int")
+
+	// BigFloat keeps digits a float64 would round away
+	bf, err := Number("0.1000000000000000000000000001").BigFloat()
+	assert.Nil(t, err, "Expected big float to convert")
+	assert.Equal(t, bf.Text('f', 28), "0.1000000000000000000000000001", "Expected big float value")
+
+	// Only JSON literals convert, not other syntax Go accepts
+	for _, s := range []string{"inf", "-Inf", "0x1p3", "0x10", "1_0", "+1", "01", ".5", ""} {
+		n := Number(s)
+		_, err := n.Float64()
+		assert.NotNil(t, err, "Expected "+s+" to fail float64")
+		_, err = n.Int64()
+		assert.NotNil(t, err, "Expected "+s+" to fail int64")
+		_, err = n.Uint64()
+		assert.NotNil(t, err, "Expected "+s+" to fail uint64")
+		_, err = n.BigInt()
+		assert.NotNil(t, err, "Expected "+s+" to fail big int")
+		_, err = n.BigFloat()
+		assert.NotNil(t, err, "Expected "+s+" to fail big float")
+		_, err = Get[float64](NewJsonValue(n), "")
+		assert.NotNil(t, err, "Expected "+s+" to fail Get[float64]")
+	}
+
+	// Except the Infinity & NaN relaxed mode makes
+	f, err = Number("-Infinity").Float64()
+	assert.Nil(t, err, "Expected relaxed -Infinity to convert")
+	assert.Equal(t, math.IsInf(f, -1), true, "Expected -Inf")
+	f, err = Number("NaN").Float64()
+	assert.Nil(t, err, "Expected relaxed NaN to convert")
+	assert.Equal(t, math.IsNaN(f), true, "Expected NaN")
+}
+
+func TestParserNumberMode(t *testing.T) {
+	result, err := runParserWithNumbers(`{
+		"id": 18446744073709551615,
+		"neg": -9223372036854775808,
+		"price": 0.1000000000000000000000000001,
+		"exp": 1.5e3,
+		"arr": [1, 2.5]
+	}`)
+	assert.Nil(t, err, "Expected number mode to parse")
+
+	id, _ := result.GetNumber("id")
+	assert.Equal(t, id.String(), "18446744073709551615", "Expected id literal to be kept")
+	idUint, _ := result.GetUint64("id")
+	assert.Equal(t, idUint, uint64(18446744073709551615), "Expected id as uint64")
+
+	neg, _ := result.GetInt64("neg")
+	assert.Equal(t, neg, int64(-9223372036854775808), "Expected neg as int64")
+
+	price, _ := result.GetNumber("price")
+	assert.Equal(t, price.String(), "0.1000000000000000000000000001", "Expected price literal to be kept")
+
+	// Existing accessors convert numbers too
+	exp, _ := result.GetFloat("exp")
+	assert.Equal(t, exp, 1500.0, "Expected exp as float")
+
+	arr, _ := result.GetArray("arr")
+	arr0, _ := arr[0].GetInt("")
+	arr1, _ := arr[1].GetFloat("")
+	assert.Equal(t, arr0, 1, "Expected arr[0] as int")
+	assert.Equal(t, arr1, 2.5, "Expected arr[1] as float")
+
+	// Invalid literals are rejected since nothing else converts them
+	_, err = runParserWithNumbers(`{ "a": 01 }`)
+	assert.NotNil(t, err, "Expected error on leading zero, did not error")
+
+	_, err = runParserWithNumbers(`{ "a": 1-2 }`)
+	assert.NotNil(t, err, "Expected error on malformed number, did not error")
+
+	// Without number mode, the new accessors still work on int & float values
+	result, _ = runParserWithStr(`{ "a": 1, "b": 2.5 }`)
+	a, _ := result.GetInt64("a")
+	assert.Equal(t, a, int64(1), "Expected a as int64")
+	bNum, _ := result.GetNumber("b")
+	assert.Equal(t, bNum.String(), "2.5", "Expected b formatted as number")
+
+	// Exponents parse as floats
+	result, _ = runParserWithStr(`{ "a": 1e3 }`)
+	e, _ := result.GetFloat("a")
+	assert.Equal(t, e, 1000.0, "Expected exponent as float")
+}
@@ -0,0 +1,453 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"tmelot.jsonparser/internal/profiler"
+)
+
+/*
+	Parallel parsing splits a big array into chunks & parses them on separate goroutines. Made for
+	files like pairs.json, where nearly all the data is in 1 array:
+
+	```
+	jsonResult, err := jsonParser.ParseJsonParallel(fileData, jsonParser.DefaultParseOptions(), runtime.NumCPU())
+	```
+
+	Design
+	- A structural pre-scan finds the array to split: the root array, or the biggest array value
+	  of the root object. It only tracks strings & bracket depth (like the value scanner), so
+	  it's much faster than lexing. While scanning, it notes element separating commas about
+	  every PARALLEL_MIN_CHUNK_SIZE bytes. Those are the safe places to split.
+	- The rest of the document is parsed on its own, with the array swapped for "[]".
+	- Each chunk is lexed & parsed onto its own tape by a worker, & the tapes are stitched into the
+	  array in order. Tape sizes are relative (see tape.go), so that's a copy.
+	- Each worker gets a profiler block, so it's easy to see how evenly the work was split.
+
+	Falls back to a normal parse if there's no array worth splitting.
+
+	Results & errors match a normal parse, except:
+	- When there are several errors, the first 1 in the input is returned, which may not be
+	  the 1 a normal parse would stop at.
+	- MaxTokens & MaxContainerSize are checked once all chunks are parsed, so their errors are
+	  positioned at the start of the chunk or array that went over.
+*/
+
+// Min bytes per chunk. Smaller chunks aren't worth a goroutine.
+const PARALLEL_MIN_CHUNK_SIZE = 64 * 1024
+
+// An array found by the pre-scan that can be split into chunks.
+type splittableArray struct {
+	start       int    // Offset of "["
+	end         int    // Offset just past "]"
+	inObject    bool   // True if the array is a member of the root object, false if it's the root
+	key         string // Member key, when inObject
+	checkpoints []int  // Offsets of element separating commas, in order
+}
+
+// Parses the given string using the given options, splitting the biggest top level array across
+// up to the given number of goroutines.
+func ParseJsonParallel(fileData string, options ParseOptions, threads int) (*JsonValue, error) {
+	if options.MaxDocumentSize > 0 && len(fileData) > options.MaxDocumentSize {
+		return nil, newParseError(fileData, options.MaxDocumentSize, ErrMaxDocumentSizeExceeded)
+	}
+
+	profiler.GlobalProfiler.StartBandwidth("Parser.PreScan", uint64(len(fileData)))
+	array := findSplittableArray(fileData, options)
+	profiler.GlobalProfiler.EndBandwidth("Parser.PreScan")
+	if threads <= 1 || array == nil || len(array.checkpoints) == 0 {
+		return ParseJsonWithOptions(fileData, options)
+	}
+	chunks := array.chunkBounds(threads)
+
+	// Parse everything else, with an empty array in place of the big 1
+	reduced := fileData[:array.start] + JSON_SYNTAX_LEFT_BRACKET + JSON_SYNTAX_RIGHT_BRACKET + fileData[array.end:]
+	result, reducedErr := ParseJsonWithOptions(reduced, options)
+	if reducedErr != nil {
+		reducedErr = array.relocateReducedError(fileData, reducedErr)
+	}
+
+	// Parse chunks
+	arrayDepth := 1
+	if array.inObject {
+		arrayDepth = 2
+	}
+	chunkTapes := make([]*tape, len(chunks))
+	chunkItems := make([]int, len(chunks))
+	chunkTokens := make([]int, len(chunks))
+	chunkErrs := make([]error, len(chunks))
+
+	profiler.GlobalProfiler.StartBlock("Parser.Parallel")
+	var wg sync.WaitGroup
+	for i, bounds := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			blockName := fmt.Sprintf("Parser.Worker%02d", i)
+			startTSC := profiler.GlobalProfiler.StartConcurrentBlock(blockName, uint64(bounds[1]-bounds[0]))
+			chunkTapes[i], chunkItems[i], chunkTokens[i], chunkErrs[i] = parseArrayChunk(fileData, bounds[0], bounds[1], options, arrayDepth)
+			profiler.GlobalProfiler.EndConcurrentBlock(blockName, startTSC)
+		}()
+	}
+	wg.Wait()
+	profiler.GlobalProfiler.EndBlock("Parser.Parallel")
+
+	// Return the first error in the input
+	firstErr := reducedErr
+	for _, err := range chunkErrs {
+		if err != nil && (firstErr == nil || errorOffset(err) < errorOffset(firstErr)) {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// Stitch chunks together, checking limits that span chunks
+	profiler.GlobalProfiler.StartBlock("Parser.Stitch")
+	numItems := 0
+	numTokens := 0
+	numNodes := 0
+	numStrings := 0
+	for i, items := range chunkItems {
+		numItems += items
+		numTokens += chunkTokens[i]
+		numNodes += len(chunkTapes[i].nodes)
+		numStrings += len(chunkTapes[i].strings)
+		if options.MaxTokens > 0 && numTokens > options.MaxTokens {
+			return nil, newParseError(fileData, chunks[i][0], ErrMaxTokensExceeded)
+		}
+	}
+	if options.MaxContainerSize > 0 && numItems > options.MaxContainerSize {
+		return nil, newParseError(fileData, array.start, ErrMaxContainerSizeExceeded)
+	}
+
+	// The reduced parse has an empty array where the big 1 goes, which the chunks are spliced into
+	arrayIndex := 0
+	if array.inObject {
+		arrayIndex = result.tape.objectLookup(0, array.key)
+	}
+	// The pre-scan should rule out anything else, but stitching into the wrong node would corrupt
+	// the tape, so make sure
+	if arrayIndex < 0 || result.tape.nodes[arrayIndex] != (tapeNode{kind: tapeArray, size: 1}) {
+		profiler.GlobalProfiler.EndBlock("Parser.Stitch")
+		return ParseJsonWithOptions(fileData, options)
+	}
+	t := result.tape
+	nodes := make([]tapeNode, 0, len(t.nodes)+numNodes)
+	nodes = append(nodes, t.nodes[:arrayIndex+1]...)
+	t.strings = slices.Grow(t.strings, numStrings)
+	for _, chunk := range chunkTapes {
+		nodes = appendRebasedNodes(nodes, chunk.nodes, len(t.strings))
+		t.strings = append(t.strings, chunk.strings...)
+	}
+	nodes = append(nodes, t.nodes[arrayIndex+1:]...)
+	nodes[arrayIndex].size += uint32(numNodes)
+	nodes[arrayIndex].payload = uint64(numItems)
+	if array.inObject {
+		nodes[0].size += uint32(numNodes)
+	}
+	t.nodes = nodes
+	profiler.GlobalProfiler.EndBlock("Parser.Stitch")
+
+	return result, nil
+}
+
+// Appends nodes from another tape to dst, with string indexes moved up by stringBase for the
+// strings being appended after stringBase others.
+func appendRebasedNodes(dst []tapeNode, nodes []tapeNode, stringBase int) []tapeNode {
+	start := len(dst)
+	dst = append(dst, nodes...)
+	for i := start; i < len(dst); i++ {
+		switch dst[i].kind {
+		case tapeString, tapeKey, tapeNumber:
+			dst[i].payload += uint64(stringBase)
+		}
+	}
+	return dst
+}
+
+// Lexes & parses the items between start & end, a run of an array's items with no brackets, onto
+// a tape of their own. Also returns the number of items & tokens lexed.
+func parseArrayChunk(fileData string, start, end int, options ParseOptions, depth int) (*tape, int, int, error) {
+	chunk := fileData[start:end]
+
+	lexer := newLexer(chunk)
+	lexer.options = options
+	lexer.noProfile = true
+	tokens, err := lexer.lex()
+	if err != nil {
+		return nil, 0, 0, rebaseParseError(fileData, start, err)
+	}
+
+	parser := newParser(tokens)
+	parser.data = chunk
+	parser.options = options
+	parser.depth = depth
+	numItems, err := parser.parseArrayChunk()
+	parser.profileKeyInterning()
+	if err != nil {
+		return nil, 0, 0, rebaseParseError(fileData, start, err)
+	}
+	return parser.tape, numItems, len(tokens), nil
+}
+
+// Parses a run of comma separated array items with no brackets, split out of a bigger array, &
+// returns the number of items.
+func (p *Parser) parseArrayChunk() (int, error) {
+	p.startTape()
+	numItems := 0
+
+	for {
+		if err := p.parseValue(p.getNextToken()); err != nil {
+			return numItems, err
+		}
+		numItems += 1
+
+		nextToken := p.getNextToken()
+		if nextToken == nil {
+			return numItems, nil
+		}
+		if nextToken.Type != JsonFieldSeparator {
+			msg := fmt.Sprintf("Expected field separator \"%s\" or close array \"%s\", found \"%s\" instead", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACKET, nextToken.Value)
+			return numItems, p.syntaxError(nextToken, msg)
+		}
+		// Relaxed mode allows a trailing comma at the end of the last chunk
+		if p.options.Relaxed && p.peekToken(p.pos) == nil {
+			return numItems, nil
+		}
+	}
+}
+
+// Converts an error from parsing a slice of fileData starting at base into a ParseError for all
+// of fileData.
+func rebaseParseError(fileData string, base int, err error) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return newParseError(fileData, base+parseErr.Offset, parseErr.Err)
+	}
+	return newParseError(fileData, base, err)
+}
+
+// Returns the offset of a ParseError, or 0 for other errors.
+func errorOffset(err error) int {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.Offset
+	}
+	return 0
+}
+
+// Converts an error from parsing the reduced document (with "[]" in place of the array) into a
+// ParseError for fileData.
+func (a *splittableArray) relocateReducedError(fileData string, err error) error {
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		return err
+	}
+
+	offset := parseErr.Offset
+	if offset >= a.start+2 {
+		offset += a.end - a.start - 2
+	}
+	return newParseError(fileData, offset, parseErr.Err)
+}
+
+// Returns [start, end) bounds of up to the given number of chunks of about the same size. Chunks
+// hold the items between checkpoints, without the brackets or separating commas.
+func (a *splittableArray) chunkBounds(numChunks int) [][2]int {
+	numChunks = min(numChunks, len(a.checkpoints)+1)
+	chunkSize := (a.end - a.start) / numChunks
+
+	var bounds [][2]int
+	chunkStart := a.start + 1
+	for i := 1; i < numChunks; i++ {
+		// Split at the first checkpoint past the ideal split point
+		target := a.start + i*chunkSize
+		checkpoint := sort.SearchInts(a.checkpoints, target)
+		if checkpoint == len(a.checkpoints) {
+			break
+		}
+		split := a.checkpoints[checkpoint]
+		if split <= chunkStart {
+			continue
+		}
+		bounds = append(bounds, [2]int{chunkStart, split})
+		chunkStart = split + 1
+	}
+
+	return append(bounds, [2]int{chunkStart, a.end - 1})
+}
+
+// Pre-scans the document for the array to split: the root array, or the biggest array value of
+// the root object. Returns nil if there isn't 1, or if the document looks broken, in which case
+// a normal parse will report the error.
+func findSplittableArray(data string, options ParseOptions) *splittableArray {
+	relaxed := options.Relaxed
+	i, _ := skipValueWhitespace(data, 0, relaxed, true)
+	if i >= len(data) {
+		return nil
+	}
+
+	switch data[i] {
+	case '[':
+		return scanArrayCheckpoints(data, i, relaxed)
+	case '{':
+	default:
+		return nil
+	}
+
+	// Scan root object members
+	var best *splittableArray
+	var bestKey string
+	i += 1
+	for {
+		i, _ = skipValueWhitespace(data, i, relaxed, true)
+		if i >= len(data) {
+			return nil
+		}
+		if data[i] == '}' {
+			break
+		}
+
+		// Key. Unquoted relaxed keys are left to the normal parse.
+		if data[i] != '"' && !(relaxed && data[i] == '\'') {
+			return nil
+		}
+		keyEnd, complete := scanStringEnd(data, i)
+		if !complete {
+			return nil
+		}
+		key, ok := decodeScannedKey(data[i:keyEnd], options)
+		if !ok {
+			return nil
+		}
+		// A later duplicate key would replace the array, so leave that to the normal parse. Keys
+		// are compared decoded, since "\u0070airs" is also "pairs".
+		if best != nil && key == bestKey {
+			return nil
+		}
+
+		// Colon
+		i, _ = skipValueWhitespace(data, keyEnd, relaxed, true)
+		if i >= len(data) || data[i] != ':' {
+			return nil
+		}
+		i, _ = skipValueWhitespace(data, i+1, relaxed, true)
+		if i >= len(data) {
+			return nil
+		}
+
+		// Value
+		if data[i] == '[' {
+			array := scanArrayCheckpoints(data, i, relaxed)
+			if array == nil {
+				return nil
+			}
+			if best == nil || array.end-array.start > best.end-best.start {
+				best = array
+				bestKey = key
+			}
+			i = array.end
+		} else {
+			valueEnd, complete := scanValueEnd(data, i, relaxed)
+			if !complete {
+				return nil
+			}
+			i = valueEnd
+		}
+
+		// Separator or end
+		i, _ = skipValueWhitespace(data, i, relaxed, true)
+		if i >= len(data) {
+			return nil
+		}
+		if data[i] == ',' {
+			i += 1
+			continue
+		}
+		if data[i] != '}' {
+			return nil
+		}
+		break
+	}
+	if best == nil {
+		return nil
+	}
+	best.inObject = true
+	best.key = bestKey
+	return best
+}
+
+// Decodes a quoted key found by the pre-scan the same way the lexer would. Returns false if it
+// doesn't lex, in which case a normal parse will report the error.
+func decodeScannedKey(keyText string, options ParseOptions) (string, bool) {
+	if keyText[0] == '"' && isPlainAscii(keyText) {
+		return keyText[1 : len(keyText)-1], true
+	}
+
+	lexer := newLexer(keyText)
+	lexer.options = ParseOptions{Relaxed: options.Relaxed, InvalidUTF8: options.InvalidUTF8}
+	lexer.noProfile = true
+	tokens, err := lexer.lex()
+	if err != nil || len(tokens) != 1 {
+		return "", false
+	}
+	return tokens[0].Value, true
+}
+
+// Returns true if s has no escapes or non-ASCII bytes, so a quoted key decodes to its contents.
+func isPlainAscii(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' || s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// Scans the array starting at start, noting element separating commas about every
+// PARALLEL_MIN_CHUNK_SIZE bytes. Returns nil if the array isn't closed.
+func scanArrayCheckpoints(data string, start int, relaxed bool) *splittableArray {
+	var checkpoints []int
+	lastCheckpoint := start
+	depth := 0
+
+	for i := start; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '"' || (relaxed && c == '\''):
+			stringEnd, complete := scanStringEnd(data, i)
+			if !complete {
+				return nil
+			}
+			i = stringEnd
+			continue
+		case relaxed && c == '/':
+			commentEnd, complete := skipComment(data, i)
+			if !complete {
+				return nil
+			}
+			if commentEnd > i {
+				i = commentEnd
+				continue
+			}
+		case c == '{' || c == '[':
+			depth += 1
+		case c == '}' || c == ']':
+			depth -= 1
+			if depth == 0 {
+				return &splittableArray{start: start, end: i + 1, checkpoints: checkpoints}
+			}
+		case c == ',' && depth == 1 && i-lastCheckpoint >= PARALLEL_MIN_CHUNK_SIZE:
+			checkpoints = append(checkpoints, i)
+			lastCheckpoint = i
+		}
+		i += 1
+	}
+	return nil
+}
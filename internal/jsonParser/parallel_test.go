@@ -0,0 +1,113 @@
+package jsonParser
+
+/*
+	Tests parallel parsing of big arrays.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+// Returns a pairs array with strings holding brackets & commas, so splits have to be string
+// aware. About 100 bytes per pair.
+func makePairsArray(numPairs int) string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; i < numPairs; i++ {
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+		fmt.Fprintf(&sb, `{"x0":%d.5, "y0":-%d.25, "x1":%d, "note":"a,b]\"}[%d", "list":[1,[2]]}`, i, i, i, i)
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// Parses with both ParseJson() & ParseJsonParallel() & checks they agree.
+func assertParallelMatches(t *testing.T, input string, options ParseOptions) {
+	expected, err := ParseJsonWithOptions(input, options)
+	assert.Nil(t, err, "Expected normal parse to succeed")
+	actual, err := ParseJsonParallel(input, options, 4)
+	assert.Nil(t, err, "Expected parallel parse to succeed")
+	assert.Equal(t, serializeToStr(t, actual), serializeToStr(t, expected), "Expected parallel parse to match normal parse")
+}
+
+func TestParallelMatchesNormalParse(t *testing.T) {
+	pairs := makePairsArray(4000)
+
+	// Splits the root array, or the biggest array in the root object
+	array := findSplittableArray(pairs, ParseOptions{})
+	assert.Equal(t, len(array.chunkBounds(4)), 4, "Expected 4 chunks")
+	assertParallelMatches(t, pairs, DefaultParseOptions())
+
+	object := `{"before": [1, 2], "pairs": ` + pairs + `, "after": {"a": "]"}}`
+	array = findSplittableArray(object, ParseOptions{})
+	assert.Equal(t, array.key, "pairs", "Expected to split the biggest array")
+	assertParallelMatches(t, object, DefaultParseOptions())
+
+	// Other options still apply in workers
+	options := DefaultParseOptions()
+	options.UseNumber = true
+	assertParallelMatches(t, object, options)
+
+	options = DefaultParseOptions()
+	options.Relaxed = true
+	relaxed := "// pairs\n{pairs: " + strings.ReplaceAll(pairs, ",\n", ", // comma, ]\n") + ",}"
+	assertParallelMatches(t, relaxed, options)
+
+	// A later duplicate key replaces the array, however the key's spelled
+	big := makePairsArray(40000)
+	for _, dup := range []string{`"pairs"`, `"\u0070airs"`, `"p\u0061irs"`} {
+		input := `{"pairs": ` + big + `, ` + dup + `: 7}`
+		assertParallelMatches(t, input, DefaultParseOptions())
+		actual, _ := ParseJsonParallel(input, DefaultParseOptions(), 4)
+		assert.Equal(t, serializeToStr(t, actual), `{"pairs":7}`, "Expected the duplicate key's value for "+dup)
+	}
+	options = DefaultParseOptions()
+	options.Relaxed = true
+	assertParallelMatches(t, `{'pairs': `+pairs+`, "pairs": [1]}`, options)
+
+	// Too small to split, or nothing to split
+	assertParallelMatches(t, `{"a": [1, 2, 3]}`, DefaultParseOptions())
+	assertParallelMatches(t, `"abc"`, DefaultParseOptions())
+}
+
+func TestParallelErrors(t *testing.T) {
+	pairs := makePairsArray(4000)
+	broken := []string{
+		// Error deep in the array
+		`{"pairs": ` + strings.Replace(pairs, `"x0":3000.5`, `"x0":3000.5,`, 1) + `}`,
+		// Error after the array
+		`{"pairs": ` + pairs + `, "after" 1}`,
+		// Error before the array
+		`{"before": tru, "pairs": ` + pairs + `}`,
+	}
+
+	for _, input := range broken {
+		_, expectedErr := ParseJson(input)
+		_, err := ParseJsonParallel(input, DefaultParseOptions(), 4)
+		assert.NotNil(t, err, "Expected parallel parse to error")
+		expected := getParseError(expectedErr)
+		actual := getParseError(err)
+		assert.NotNil(t, actual, "Expected a ParseError")
+		assert.Equal(t, actual.Offset, expected.Offset, "Expected same error offset as normal parse")
+		assert.Equal(t, actual.Line, expected.Line, "Expected same error line as normal parse")
+		assert.Equal(t, actual.Column, expected.Column, "Expected same error column as normal parse")
+	}
+
+	// Limits that span chunks
+	options := DefaultParseOptions()
+	options.MaxContainerSize = 3999
+	_, err := ParseJsonParallel(pairs, options, 4)
+	assert.Equal(t, errors.Is(err, ErrMaxContainerSizeExceeded), true, "Expected container size error")
+
+	options = DefaultParseOptions()
+	options.MaxDepth = 2
+	_, err = ParseJsonParallel(`{"pairs": `+pairs+`}`, options, 4)
+	assert.Equal(t, errors.Is(err, ErrMaxDepthExceeded), true, "Expected depth error from a worker")
+}
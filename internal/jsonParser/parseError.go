@@ -0,0 +1,102 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+	ParseError is an error at a known position in the input. Err holds the underlying error,
+	which may be one of the sentinel errors below, so callers can check with errors.Is():
+
+	```
+	_, err := jsonParser.ParseJson(fileData)
+	if errors.Is(err, jsonParser.ErrMaxDepthExceeded) {
+		...
+	}
+
+	var parseErr *jsonParser.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println(parseErr.Line, parseErr.Column)
+	}
+	```
+*/
+
+// Resource limit errors, see ParseOptions.
+var (
+	ErrMaxDepthExceeded         = errors.New("Max nesting depth exceeded")
+	ErrMaxDocumentSizeExceeded  = errors.New("Max document size exceeded")
+	ErrMaxTokensExceeded        = errors.New("Max token count exceeded")
+	ErrMaxStringLengthExceeded  = errors.New("Max string length exceeded")
+	ErrMaxContainerSizeExceeded = errors.New("Max container size exceeded")
+)
+
+// Invalid UTF-8 in a string, see ParseOptions.InvalidUTF8. The ParseError's offset points at the
+// first bad byte.
+var ErrInvalidUTF8 = errors.New("Invalid UTF-8")
+
+type ParseError struct {
+	Err    error
+	Offset int // Byte offset into the input, starting at 0
+	Line   int // Starts at 1
+	Column int // Byte column within the line, starting at 1
+}
+
+// Creates a ParseError for the given byte offset into data, working out the line & column.
+func newParseError(data string, offset int, err error) *ParseError {
+	if offset > len(data) {
+		offset = len(data)
+	}
+
+	// Errors are rare, so scanning for newlines here is cheaper than tracking lines while lexing.
+	line := 1
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		if data[i] == '\n' {
+			line += 1
+			lineStart = i + 1
+		}
+	}
+
+	return &ParseError{
+		Err:    err,
+		Offset: offset,
+		Line:   line,
+		Column: offset - lineStart + 1,
+	}
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at line %d, column %d (offset %d)", e.Err, e.Line, e.Column, e.Offset)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Converts an error from parsing a slice of a bigger input into a ParseError for the whole input.
+// offset is where the slice starts in the whole input, line is the line it starts on, &
+// lineStart is where that line starts. Errors without a position are put at the slice start.
+func relocateParseError(err error, offset, line, lineStart int) *ParseError {
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		return &ParseError{
+			Err:    err,
+			Offset: offset,
+			Line:   line,
+			Column: offset - lineStart + 1,
+		}
+	}
+
+	// Columns on the slice's first line are relative to the slice start
+	column := parseErr.Column
+	if parseErr.Line == 1 {
+		column += offset - lineStart
+	}
+	return &ParseError{
+		Err:    parseErr.Err,
+		Offset: offset + parseErr.Offset,
+		Line:   line + parseErr.Line - 1,
+		Column: column,
+	}
+}
@@ -0,0 +1,67 @@
+package jsonParser
+
+/*
+	ParseOptions changes how the lexer & parser handle input. ParseJson() uses
+	DefaultParseOptions(), so start from that to keep the default limits:
+
+	```
+	options := jsonParser.DefaultParseOptions()
+	options.UseNumber = true
+	options.MaxDocumentSize = 10 * 1024 * 1024
+	jsonResult, err := jsonParser.ParseJsonWithOptions(fileData, options)
+	```
+
+	Limits protect against hostile or broken input. For all of them, 0 means no limit. Going over
+	a limit fails with a ParseError wrapping the matching Err*Exceeded error (see parseError.go).
+*/
+
+// Default max nesting depth. Same as encoding/json, & deep enough for any real document while
+// keeping recursion far away from the goroutine stack limit.
+const DEFAULT_MAX_DEPTH = 10000
+
+// How the lexer handles invalid UTF-8 inside strings.
+type InvalidUTF8Policy int
+
+const (
+	// Fail with ErrInvalidUTF8 at the first bad byte. This is the default.
+	InvalidUTF8Reject InvalidUTF8Policy = iota
+	// Replace each bad byte with U+FFFD.
+	InvalidUTF8Replace
+	// Keep bad bytes as-is. Strings may then hold invalid UTF-8.
+	InvalidUTF8PassThrough
+)
+
+type ParseOptions struct {
+	// Keep numbers as their original literal text (type Number) instead of converting them to
+	// int or float64. Use this to avoid overflow on big integers & rounding on long decimals.
+	UseNumber bool
+
+	// Accept JSON5 & JSONC (comments, trailing commas, single quotes, unquoted keys, hex numbers,
+	// Infinity & NaN, etc.) See relaxed.go for the full list.
+	Relaxed bool
+
+	// What to do with invalid UTF-8 inside strings.
+	InvalidUTF8 InvalidUTF8Policy
+
+	// Max nesting depth of objects & arrays. The root object is depth 1.
+	MaxDepth int
+	// Max size of the input in bytes.
+	MaxDocumentSize int
+	// Max number of tokens the lexer will produce.
+	MaxTokens int
+	// Max length of a string (keys included) in bytes.
+	MaxStringLength int
+	// Max number of members in an object or elements in an array.
+	MaxContainerSize int
+
+	// Object keys the document is expected to repeat, seeded into the key intern table so they're
+	// shared from their first use. Optional, see keyIntern.go.
+	ExpectedKeys []string
+}
+
+// Returns the options used by ParseJson().
+func DefaultParseOptions() ParseOptions {
+	return ParseOptions{
+		MaxDepth: DEFAULT_MAX_DEPTH,
+	}
+}
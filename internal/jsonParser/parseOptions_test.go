@@ -0,0 +1,108 @@
+package jsonParser
+
+/*
+	Tests ParseOptions resource limits.
+*/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+// Returns the ParseError wrapped in err, or nil.
+func getParseError(err error) *ParseError {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr
+	}
+	return nil
+}
+
+func TestParseOptionsMaxDepth(t *testing.T) {
+	options := DefaultParseOptions()
+	options.MaxDepth = 3
+
+	// Depth 3 is ok
+	_, err := ParseJsonWithOptions(`{"a": [{"b": 1}]}`, options)
+	assert.Nil(t, err, "Expected depth 3 to parse")
+
+	// Depth 4 isn't, & the error points at the 4th open bracket
+	_, err = ParseJsonWithOptions(`{"a": [{"b": [1]}]}`, options)
+	assert.Equal(t, errors.Is(err, ErrMaxDepthExceeded), true, "Expected max depth error")
+	parseErr := getParseError(err)
+	assert.NotNil(t, parseErr, "Expected a ParseError")
+	assert.Equal(t, parseErr.Offset, 13, "Expected error offset at 4th bracket")
+
+	// Default limit stops deep nesting long before the stack runs out
+	deep := `{"a":` + strings.Repeat("[", DEFAULT_MAX_DEPTH*10) + strings.Repeat("]", DEFAULT_MAX_DEPTH*10) + "}"
+	_, err = ParseJson(deep)
+	assert.Equal(t, errors.Is(err, ErrMaxDepthExceeded), true, "Expected max depth error by default")
+
+	// No limit with a zero value
+	_, err = ParseJsonWithOptions(`{"a": [[[[[[1]]]]]]}`, ParseOptions{})
+	assert.Nil(t, err, "Expected no depth limit with zero options")
+}
+
+func TestParseOptionsMaxDocumentSize(t *testing.T) {
+	options := DefaultParseOptions()
+	options.MaxDocumentSize = 10
+
+	_, err := ParseJsonWithOptions(`{"a": 1}`, options)
+	assert.Nil(t, err, "Expected small document to parse")
+
+	_, err = ParseJsonWithOptions(`{"a": 1, "b": 2}`, options)
+	assert.Equal(t, errors.Is(err, ErrMaxDocumentSizeExceeded), true, "Expected max document size error")
+	assert.Equal(t, getParseError(err).Offset, 10, "Expected error offset at the limit")
+}
+
+func TestParseOptionsMaxTokens(t *testing.T) {
+	options := DefaultParseOptions()
+	options.MaxTokens = 5
+
+	_, err := ParseJsonWithOptions(`{"a": 1}`, options)
+	assert.Nil(t, err, "Expected 5 tokens to parse")
+
+	_, err = ParseJsonWithOptions(`{"a": [1]}`, options)
+	assert.Equal(t, errors.Is(err, ErrMaxTokensExceeded), true, "Expected max tokens error")
+	assert.Equal(t, getParseError(err).Offset, 8, "Expected error offset at 6th token")
+}
+
+func TestParseOptionsMaxStringLength(t *testing.T) {
+	options := DefaultParseOptions()
+	options.MaxStringLength = 4
+
+	_, err := ParseJsonWithOptions(`{"abcd": "efgh"}`, options)
+	assert.Nil(t, err, "Expected 4 char strings to parse")
+
+	// Values
+	_, err = ParseJsonWithOptions(`{"a": "abcde"}`, options)
+	assert.Equal(t, errors.Is(err, ErrMaxStringLengthExceeded), true, "Expected max string length error")
+	assert.Equal(t, getParseError(err).Offset, 6, "Expected error offset at string start")
+
+	// Keys
+	_, err = ParseJsonWithOptions(`{"abcde": 1}`, options)
+	assert.Equal(t, errors.Is(err, ErrMaxStringLengthExceeded), true, "Expected max string length error on key")
+}
+
+func TestParseOptionsMaxContainerSize(t *testing.T) {
+	options := DefaultParseOptions()
+	options.MaxContainerSize = 2
+
+	_, err := ParseJsonWithOptions(`{"a": [1, 2], "b": 2}`, options)
+	assert.Nil(t, err, "Expected 2 item containers to parse")
+
+	// Arrays
+	_, err = ParseJsonWithOptions(`{"a": [1, 2, 3]}`, options)
+	assert.Equal(t, errors.Is(err, ErrMaxContainerSizeExceeded), true, "Expected max container size error on array")
+	assert.Equal(t, getParseError(err).Offset, 13, "Expected error offset at 3rd element")
+
+	// Objects
+	_, err = ParseJsonWithOptions("{\"a\": 1,\n \"b\": 2,\n \"c\": 3}", options)
+	assert.Equal(t, errors.Is(err, ErrMaxContainerSizeExceeded), true, "Expected max container size error on object")
+	parseErr := getParseError(err)
+	assert.Equal(t, parseErr.Line, 3, "Expected error on line 3")
+	assert.Equal(t, parseErr.Column, 2, "Expected error on column 2")
+}
@@ -22,7 +22,8 @@ import (
 	    "my current design is trading off type safety to gain simplicity. the way it's implemented, my parsing functions will return a string or a number or a map. the calling client code will be responsible for safely traversing the map. since i know the exact use case, and it's only 1 JSON format, that seems like a fair trade off."
 
 	Parser
-	- Loops over the list of tokens, parsing out JSON primitives into a map of any that is returned.
+	- Loops over the list of tokens, appending each JSON value to a tape (see tape.go) that is
+	  returned as a JsonValue.
 
 	Design
 	- Recursive descent
@@ -32,61 +33,133 @@ import (
 
 // Parses the given string & returns result.
 func ParseJson(fileData string) (*JsonValue, error) {
+	return ParseJsonWithOptions(fileData, DefaultParseOptions())
+}
+
+// Parses the given string using the given options & returns result.
+func ParseJsonWithOptions(fileData string, options ParseOptions) (*JsonValue, error) {
 	profiler.GlobalProfiler.StartBlock("Parser")
+	if options.MaxDocumentSize > 0 && len(fileData) > options.MaxDocumentSize {
+		return nil, newParseError(fileData, options.MaxDocumentSize, ErrMaxDocumentSizeExceeded)
+	}
+
 	// Lex into tokens
 	lexer := newLexer(fileData)
+	lexer.options = options
 	tokens, err := lexer.lex()
 	if err != nil {
-		msg := fmt.Sprintf("Lexer error: %s\n", err)
-		return nil, errors.New(msg)
+		// Wrap so callers can still get at a ParseError with errors.As()
+		return nil, fmt.Errorf("Lexer error: %w", err)
 	}
 
-	// Parse into map
+	// Parse onto a tape
 	profiler.GlobalProfiler.StartBlock("Parser.Parse")
 	parser := newParser(tokens)
-	jsonResult, parseErr := parser.parse()
-	if parseErr != nil {
-		msg := fmt.Sprintln(parseErr)
-		return nil, errors.New(msg)
+	parser.data = fileData
+	parser.options = options
+	if parseErr := parser.parse(); parseErr != nil {
+		return nil, parseErr
 	}
 	profiler.GlobalProfiler.EndBlock("Parser.Parse")
+	parser.profileKeyInterning()
 
 	profiler.GlobalProfiler.EndBlock("Parser")
-	return &JsonValue{jsonResult}, nil
+	return &JsonValue{tape: parser.tape}, nil
 }
 
 type Parser struct {
-	Debug  bool
-	Tokens []Token
-	pos    int
+	Debug       bool
+	Tokens      []Token
+	pos         int
+	data        string // Input the tokens were lexed from, used to position errors
+	depth       int    // Current object/array nesting depth
+	options     ParseOptions
+	recover     bool          // Diagnostic mode, see diagnostics.go
+	diagnostics []*ParseError // Errors recovered from in diagnostic mode
+	rootParsed  bool          // The root value parsed, which tells a null root from a failed 1
+	tape        *tape         // Parsed values are appended here
+	keys        keyInterner   // Shares repeated object keys on the tape, see keyIntern.go
 }
 
 func newParser(tokens []Token) *Parser {
 	return &Parser{
 		Tokens: tokens,
+		tape:   &tape{},
 	}
 }
 
-// Parses tokens & returns map of result, or a partial result with an error. It tries to
-// return as much as it's parsed so far.
-func (p *Parser) parse() (map[string]any, error) {
-	var result map[string]any
-	var err error
+// Sets up the tape & key intern table before parsing.
+func (p *Parser) startTape() {
+	// About half the tokens are punctuation, so half is a good guess at the number of nodes
+	p.tape.nodes = make([]tapeNode, 0, len(p.Tokens)/2+1)
+	p.keys.seed(p.options.ExpectedKeys)
+}
+
+// Adds the key intern table's hits & lookups to the profiler.
+func (p *Parser) profileKeyInterning() {
+	profiler.GlobalProfiler.AddCounter("Parser.KeyIntern", p.keys.hits, p.keys.hits+p.keys.misses)
+}
 
-	// Check for open brace to start JSON
-	firstToken := p.peekToken(0)
-	if firstToken != nil && firstToken.Type != JsonObjectStart {
-		msg := fmt.Sprintf("Expected start of JSON \"%s\", found \"%s\" instead\n", JSON_SYNTAX_LEFT_BRACE, firstToken.Value)
-		err = errors.New(msg)
-		return result, err
+// Parses tokens onto the tape, where the root value is the first node. On an error, the tape
+// holds as much as was parsed so far, with any containers closed.
+//
+// The root can be any value, not just an object. It must be the only value, anything after it
+// is an error. See decoder.go for inputs holding more than 1 value.
+func (p *Parser) parse() error {
+	p.startTape()
+
+	rootToken := p.getNextToken()
+	err := p.parseValue(rootToken)
+	if err != nil {
+		// Diagnostic mode reports it, but there's no telling where the root should have ended
+		if p.recoverFrom(err) {
+			return nil
+		}
+		return err
 	}
+	p.rootParsed = true
 
-	// Advance to next token
-	p.getNextToken()
-	var objParseErr error
-	result, objParseErr = p.parseObject()
+	// Check for anything after the root
+	if extraToken := p.peekToken(p.pos); extraToken != nil {
+		msg := fmt.Sprintf("Unexpected \"%s\" after end of JSON value", extraToken.Value)
+		err = p.syntaxError(extraToken, msg)
+		if !p.recoverFrom(err) {
+			return err
+		}
+	}
 
-	return result, objParseErr
+	return nil
+}
+
+// Tracks nesting depth when starting an object or array at the given token, returning an error
+// if it goes over the MaxDepth limit. Must be paired with exitContainer().
+func (p *Parser) enterContainer(token *Token) error {
+	p.depth += 1
+	if p.options.MaxDepth > 0 && p.depth > p.options.MaxDepth {
+		return newParseError(p.data, p.tokenOffset(token), ErrMaxDepthExceeded)
+	}
+	return nil
+}
+
+func (p *Parser) exitContainer() {
+	p.depth -= 1
+}
+
+// Returns an error if a container with the given number of items is over the MaxContainerSize
+// limit. The token is used to position the error.
+func (p *Parser) checkContainerSize(numItems int, token *Token) error {
+	if p.options.MaxContainerSize > 0 && numItems > p.options.MaxContainerSize {
+		return newParseError(p.data, p.tokenOffset(token), ErrMaxContainerSizeExceeded)
+	}
+	return nil
+}
+
+// Returns the byte offset of the given token, or the end of the data if there's no token.
+func (p *Parser) tokenOffset(token *Token) int {
+	if token == nil {
+		return len(p.data)
+	}
+	return token.Pos
 }
 
 // Returns token at index, otherwise nil. DOES NOT increment position.
@@ -110,145 +183,319 @@ func (p *Parser) getNextToken() *Token {
 	return &p.Tokens[oldPos]
 }
 
-// Parses & returns JSON object starting at the next token. If parsing an object or array, consumes the open brace/bracket
-// and then parses the value, which could recurse back in here.
-func (p *Parser) parseObject() (map[string]any, error) {
+// Parses JSON object members starting at the next token onto the tape, up to the close brace.
+// If parsing an object or array, consumes the open brace/bracket and then parses the value,
+// which could recurse back in here.
+func (p *Parser) parseObject() error {
 	// profiler.GlobalProfiler.StartBlock("ParseJSONObject")
-	result := make(map[string]any)
+	numMembers := 0
 
-	// Prime loop by parsing 1st key
-	keyToken := p.getNextToken()
-	for keyToken != nil {
-		// Validate ":" after key
-		assignmentToken := p.getNextToken()
-		if assignmentToken.Type != JsonFieldAssignment {
-			msg := fmt.Sprintf("Expected field assignment \"%s\", found \"%s\" instead", JSON_SYNTAX_COLON, assignmentToken.Value)
-			err := errors.New(msg)
-			return result, err
-		}
-
-		// Parse value
-		valueToken := p.getNextToken()
-		parsedValue, valueErr := p.parseValue(valueToken)
-		if valueErr != nil {
-			msg := fmt.Sprint(valueErr)
-			return result, errors.New(msg)
-		}
-		if parsedValue != nil {
-			// fmt.Printf("parseObject(): Setting result[%s] = %d\n", keyToken.Value, parsedValue)
-			result[keyToken.Value] = parsedValue
-		}
-
-		// Parse next item or finish
-		nextToken := p.getNextToken()
-		switch nextToken.Type {
-		case JsonFieldSeparator:
-			keyToken = p.getNextToken()
-			// Error on trailing comma with no next key-value pair
-			if keyToken.Type != JsonString {
-				msg := fmt.Sprintf("Expected key string, found \"%s\" instead", keyToken.Value)
-				err := errors.New(msg)
-				return result, err
+	// Empty object
+	if p.consumeIfNext(JsonObjectEnd) {
+		return nil
+	}
+
+	for {
+		numMembers += 1
+		done, err := p.parseObjectMember(numMembers)
+		if err != nil {
+			// Diagnostic mode skips past the bad member & carries on
+			if !p.recoverFrom(err) {
+				return err
 			}
-		case JsonObjectEnd:
+			done = !p.skipToNextItem(JsonObjectEnd)
+		}
+		if done {
 			// profiler.GlobalProfiler.EndBlock("ParseJSONObject")
-			return result, nil
-		default:
-			msg := fmt.Sprintf("Expected field separator \"%s\" or close object \"%s\", found \"%s\" instead", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACE, nextToken.Value)
-			err := errors.New(msg)
-			return result, err
+			return nil
+		}
+	}
+}
+
+// Parses the next "key": value member onto the tape, along with the separator or close brace
+// after it. Returns true if that was the close brace.
+func (p *Parser) parseObjectMember(numMembers int) (bool, error) {
+	// Parse key
+	keyToken := p.getNextToken()
+	if keyToken == nil {
+		msg := fmt.Sprintf("Expected end of JSON \"%s\", found end of string instead", JSON_SYNTAX_RIGHT_BRACE)
+		return false, p.syntaxError(keyToken, msg)
+	}
+	if !p.isKeyToken(keyToken) {
+		msg := fmt.Sprintf("Expected key string, found \"%s\" instead", keyToken.Value)
+		return false, p.syntaxError(keyToken, msg)
+	}
+	if err := p.checkContainerSize(numMembers, keyToken); err != nil {
+		return false, err
+	}
+
+	// Validate ":" after key
+	assignmentToken := p.getNextToken()
+	if assignmentToken == nil || assignmentToken.Type != JsonFieldAssignment {
+		msg := fmt.Sprintf("Expected field assignment \"%s\", found \"%s\" instead", JSON_SYNTAX_COLON, tokenDescription(assignmentToken))
+		return false, p.syntaxError(assignmentToken, msg)
+	}
+
+	// Parse value, dropping the key if the value fails or is skipped
+	keyIndex := len(p.tape.nodes)
+	p.keys.appendKey(p.tape, keyToken.Value)
+	valueToken := p.getNextToken()
+	valueErr := p.parseValue(valueToken)
+	if valueErr != nil || len(p.tape.nodes) == keyIndex+1 {
+		p.tape.nodes = p.tape.nodes[:keyIndex]
+	}
+	if valueErr != nil {
+		return false, valueErr
+	}
+
+	// Parse next item or finish
+	nextToken := p.getNextToken()
+	if nextToken == nil {
+		msg := fmt.Sprintf("Expected end of JSON \"%s\", found end of string instead", JSON_SYNTAX_RIGHT_BRACE)
+		return false, p.syntaxError(nextToken, msg)
+	}
+	switch nextToken.Type {
+	case JsonFieldSeparator:
+		// Relaxed mode allows a trailing comma before the close brace
+		if p.options.Relaxed && p.consumeIfNext(JsonObjectEnd) {
+			return true, nil
 		}
+		return false, nil
+	case JsonObjectEnd:
+		return true, nil
 	}
 
-	msg := fmt.Sprintf("Expected end of JSON \"%s\", found end of string instead", JSON_SYNTAX_RIGHT_BRACE)
-	return result, errors.New(msg)
+	msg := fmt.Sprintf("Expected field separator \"%s\" or close object \"%s\", found \"%s\" instead", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACE, nextToken.Value)
+	err := p.syntaxError(nextToken, msg)
+	// A key right after a value is most likely a missing comma, so keep the next member
+	if p.recover && p.isKeyToken(nextToken) {
+		p.recordDiagnostic(err)
+		p.pos -= 1
+		return false, nil
+	}
+	return false, err
 }
 
-// TODO
-func (p *Parser) parseArray() ([]any, error) {
-	var result []any
+// Parses JSON array items starting at the next token onto the tape, up to the close bracket.
+func (p *Parser) parseArray() error {
+	numItems := 0
 
-	// Parse 1st item
-	itemToken := p.getNextToken()
-	for itemToken != nil {
-		value, err := p.parseValue(itemToken)
+	// Empty array
+	if p.consumeIfNext(JsonArrayEnd) {
+		return nil
+	}
+
+	for {
+		done, err := p.parseArrayItem(&numItems)
 		if err != nil {
-			msg := fmt.Sprint(err)
-			return result, errors.New(msg)
+			// Diagnostic mode skips past the bad item & carries on
+			if !p.recoverFrom(err) {
+				return err
+			}
+			done = !p.skipToNextItem(JsonArrayEnd)
+		}
+		if done {
+			return nil
 		}
-		// Add to result
-		if value != nil {
-			result = append(result, value)
+	}
+}
+
+// Parses the next item onto the tape & counts it in numItems, along with the separator or close
+// bracket after it. Returns true if that was the close bracket.
+func (p *Parser) parseArrayItem(numItems *int) (bool, error) {
+	// Parse item, dropping it if it fails
+	itemToken := p.getNextToken()
+	if err := p.checkContainerSize(*numItems+1, itemToken); err != nil {
+		return false, err
+	}
+	itemIndex := len(p.tape.nodes)
+	if err := p.parseValue(itemToken); err != nil {
+		p.tape.nodes = p.tape.nodes[:itemIndex]
+		return false, err
+	}
+	if len(p.tape.nodes) > itemIndex {
+		*numItems += 1
+	}
+
+	// Parse next item or finish
+	nextToken := p.getNextToken()
+	if nextToken == nil {
+		msg := fmt.Sprintf("Expected end of array \"%s\", found end of string instead", JSON_SYNTAX_RIGHT_BRACKET)
+		return false, p.syntaxError(nextToken, msg)
+	}
+	switch nextToken.Type {
+	case JsonFieldSeparator:
+		// Relaxed mode allows a trailing comma before the close bracket
+		if p.options.Relaxed && p.consumeIfNext(JsonArrayEnd) {
+			return true, nil
 		}
+		return false, nil
+	case JsonArrayEnd:
+		return true, nil
+	}
+
+	msg := fmt.Sprintf("Expected field separator \"%s\" or close array \"%s\", found \"%s\" instead", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACKET, nextToken.Value)
+	err := p.syntaxError(nextToken, msg)
+	// A value right after a value is most likely a missing comma, so keep the next item
+	if p.recover && isValueStartToken(nextToken) {
+		p.recordDiagnostic(err)
+		p.pos -= 1
+		return false, nil
+	}
+	return false, err
+}
+
+// Returns a ParseError with the given message, positioned at the token (or the end of the data
+// if there's no token).
+func (p *Parser) syntaxError(token *Token, msg string) error {
+	return newParseError(p.data, p.tokenOffset(token), errors.New(msg))
+}
+
+// Returns true if the token can be an object key. Relaxed mode also allows unquoted identifiers.
+func (p *Parser) isKeyToken(token *Token) bool {
+	return token.Type == JsonString || (p.options.Relaxed && token.Type == JsonIdentifier)
+}
+
+// Consumes the next token if it has the given type, returning true if it did.
+func (p *Parser) consumeIfNext(tokenType TokenType) bool {
+	nextToken := p.peekToken(p.pos)
+	if nextToken != nil && nextToken.Type == tokenType {
+		p.getNextToken()
+		return true
+	}
+	return false
+}
+
+// Returns the token's value for error messages, or "end of string" if there's no token.
+func tokenDescription(token *Token) string {
+	if token == nil {
+		return "end of string"
+	}
+	return token.Value
+}
+
+// Appends a number token with parseNumber(), positioning any error at the token.
+func (p *Parser) parseNumberToken(token *Token) error {
+	if err := p.parseNumber(token.Value); err != nil {
+		return newParseError(p.data, token.Pos, err)
+	}
+	return nil
+}
 
-		// Parse next item or finish
-		nextToken := p.getNextToken()
-		switch nextToken.Type {
-		case JsonFieldSeparator:
-			itemToken = p.getNextToken()
-		case JsonArrayEnd:
-			return result, nil
-		// TODO: Objects or other arrays
-		default:
-			msg := fmt.Sprintf("Expected field separator \"%s\" or close object \"%s\", found \"%s\" instead", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACKET, itemToken.Value)
-			return result, errors.New(msg)
+// Appends a number literal to the tape as an int, float64 or Number, depending on options.
+func (p *Parser) parseNumber(literal string) error {
+	// Relaxed mode allows hex, Infinity, etc. Convert those to something strict mode handles.
+	if p.options.Relaxed {
+		var special any
+		var isSpecial bool
+		var err error
+		literal, special, isSpecial, err = normalizeRelaxedNumber(literal, p.options.UseNumber)
+		if err != nil {
+			return err
+		}
+		if isSpecial {
+			p.tape.appendTree(special)
+			return nil
 		}
 	}
 
-	return result, nil
+	// The lexer takes any run of number characters, & strconv accepts more than JSON does (like
+	// leading zeros), so validate the literal here
+	if !isValidJsonNumber(literal) {
+		msg := fmt.Sprintf("Invalid number \"%s\"", literal)
+		return errors.New(msg)
+	}
+
+	// Number mode keeps the literal text
+	if p.options.UseNumber {
+		p.tape.appendString(tapeNumber, literal)
+		return nil
+	}
+
+	// TODO: How to handle strconv errors?
+	// Float
+	if strings.ContainsAny(literal, ".eE") {
+		floatVal, err := parseFloat64(literal)
+		if err != nil {
+			return err
+		}
+		p.tape.appendFloat(floatVal)
+		return nil
+	}
+	// Int
+	intVal, err := strconv.Atoi(literal)
+	if err != nil {
+		return err
+	}
+	p.tape.appendInt(intVal)
+	return nil
 }
 
-// Parses & returns the given value token. May recurse back into parseObject or Array. Does not
-// itself consume tokens, but may make calls that will.
-func (p *Parser) parseValue(valueToken *Token) (any, error) {
+// Parses the given value token onto the tape. May recurse back into parseObject or Array. Does
+// not itself consume tokens, but may make calls that will. Values skipped in diagnostic mode
+// aren't appended.
+func (p *Parser) parseValue(valueToken *Token) error {
 	// profiler.GlobalProfiler.StartBlock("ParseJSONValue")
-	var result any
-	var err error
+	if valueToken == nil {
+		return p.syntaxError(valueToken, "Expected value, found end of string instead")
+	}
 
 	switch valueToken.Type {
 	// Value is a nested object
 	case JsonObjectStart:
-		result, err = p.parseObject()
+		if err := p.enterContainer(valueToken); err != nil {
+			return err
+		}
+		index := p.tape.openContainer(tapeObject)
+		err := p.parseObject()
+		p.tape.closeContainer(index)
+		p.exitContainer()
 		if err != nil {
-			return result, err
+			return err
 		}
 	// Value is an array
 	case JsonArrayStart:
-		result, err = p.parseArray()
+		if err := p.enterContainer(valueToken); err != nil {
+			return err
+		}
+		index := p.tape.openContainer(tapeArray)
+		err := p.parseArray()
+		p.tape.closeContainer(index)
+		p.exitContainer()
 		if err != nil {
-			return result, err
+			return err
 		}
 	// Value is a string
 	case JsonString:
-		result = valueToken.Value
+		p.tape.appendString(tapeString, valueToken.Value)
 	// Value is a number
 	case JsonNumber:
-		// TODO: How to handle strconv errors?
-		// Float
-		if strings.Contains(valueToken.Value, ".") {
-			result, err = strconv.ParseFloat(valueToken.Value, 64)
-			if err != nil {
-				return result, err
-			}
-		} else {
-			// Int
-			result, err = strconv.Atoi(valueToken.Value)
-			if err != nil {
-				return result, err
-			}
+		return p.parseNumberToken(valueToken)
+	// Value is an identifier, which relaxed mode allows for Infinity & NaN
+	case JsonIdentifier:
+		if p.options.Relaxed && isRelaxedSpecialNumber(valueToken.Value) {
+			return p.parseNumberToken(valueToken)
+		}
+		msg := fmt.Sprintf("Unexpected identifier \"%s\", only allowed as an object key", valueToken.Value)
+		return p.syntaxError(valueToken, msg)
+	// Value couldn't be lexed, which diagnostic mode has already reported
+	case JsonInvalid:
+		if p.recover {
+			return nil
 		}
+		msg := fmt.Sprintf("Unexpected \"%s\"", valueToken.Value)
+		return p.syntaxError(valueToken, msg)
 	// Value is a bool
 	case JsonBool:
-		if valueToken.Value == JSON_SYNTAX_BOOL_TRUE {
-			return true, err
-		} else if valueToken.Value == JSON_SYNTAX_BOOL_FALSE {
-			return false, err
-		}
+		p.tape.appendBool(valueToken.Value == JSON_SYNTAX_BOOL_TRUE)
+	// Value is null
+	case JsonNull:
+		p.tape.appendNode(tapeNull, 0)
 	default:
 		msg := fmt.Sprintf("Cannot parse value of unknown token \"%s\" (type %s)", valueToken.Value, valueToken.Type)
-		return result, errors.New(msg)
+		return p.syntaxError(valueToken, msg)
 	}
 
 	// profiler.GlobalProfiler.EndBlock("ParseJSONValue")
-	return result, nil
+	return nil
 }
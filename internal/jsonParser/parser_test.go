@@ -56,6 +56,24 @@ func TestParserInvalidJson(t *testing.T) {
 	// Test invalid array trailing commaa
 	_, err = runParserWithStr(`{ "a": [1,2,,] }`)
 	assert.NotNil(t, err, "Expected error on multiple trailing array commas, did not error")
+
+	// Test non-string key
+	_, err = runParserWithStr(`{ 1: 2 }`)
+	assert.NotNil(t, err, "Expected error on number key, did not error")
+
+	// Test input ending in the middle of an object
+	_, err = runParserWithStr(`{ "a"`)
+	assert.NotNil(t, err, "Expected error on missing field assignment at end, did not error")
+	_, err = runParserWithStr(`{ "a": `)
+	assert.NotNil(t, err, "Expected error on missing value at end, did not error")
+	_, err = runParserWithStr(`{ "a": [1`)
+	assert.NotNil(t, err, "Expected error on unclosed array at end, did not error")
+
+	// Test numbers strconv accepts but JSON doesn't
+	for _, s := range []string{"[012]", "[-01]", "[0.e1]", "[1.]", "[1e]"} {
+		_, err = runParserWithStr(s)
+		assert.NotNil(t, err, "Expected error on invalid number "+s+", did not error")
+	}
 }
 
 func TestParserValidJson(t *testing.T) {
@@ -151,5 +169,32 @@ func TestParserValidJson(t *testing.T) {
 	assert.Equal(t, trueVal, true)
 	assert.Equal(t, falseVal, false)
 
+	// Test empty object & arrays
+	result, err := runParserWithStr(`{ "obj": {}, "arr": [], "nested": [[], {}] }`)
+	assert.Nil(t, err, "Expected empty containers to parse")
+	emptyObj, _ := result.GetObject("obj")
+	assert.NotNil(t, emptyObj, "Expected empty object")
+	emptyArr, _ := result.GetArray("arr")
+	assert.Equal(t, len(emptyArr), 0, "Expected empty array")
+	nestedArr, _ := result.GetArray("nested")
+	assert.Equal(t, len(nestedArr), 2, "Expected nested empty containers")
+
+	_, err = runParserWithStr(`{}`)
+	assert.Nil(t, err, "Expected empty root object to parse")
+
+	// Test null values are kept
+	result, err = runParserWithStr(`{ "a": null, "arr": [null, 1, null] }`)
+	assert.Nil(t, err, "Expected null values to parse")
+	assert.Equal(t, result.tree().(map[string]any)["a"], nil)
+	_, hasNull := result.tree().(map[string]any)["a"]
+	assert.Equal(t, hasNull, true, "Expected null member to be kept")
+	nullArr, _ := result.GetArray("arr")
+	assert.Equal(t, len(nullArr), 3, "Expected null items to be kept")
+	result, err = runParserWithStr(`null`)
+	assert.Nil(t, err, "Expected root null to parse")
+	assert.Equal(t, result.tree(), nil)
+	_, err = runParserWithStr(`nul`)
+	assert.NotNil(t, err, "Expected error on truncated null, did not error")
+
 	assert.Finished()
 }
@@ -0,0 +1,315 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+/*
+	Patching applies changes to a parsed document, like a config overlay. 2 formats are
+	supported:
+
+	JSON Patch (RFC 6902) is an array of operations, applied in order:
+	```
+	patch, _ := jsonParser.ParseJson(`[
+		{"op": "replace", "path": "/server/port", "value": 8080},
+		{"op": "add", "path": "/server/hosts/-", "value": "example.com"},
+		{"op": "remove", "path": "/debug"}
+	]`)
+	patched, err := config.ApplyPatch(patch)
+	```
+	Operations are add, remove, replace, move, copy & test. Paths are JSON pointers (see
+	jsonPointer.go), with "-" naming the end of an array for add.
+
+	JSON Merge Patch (RFC 7386) is a partial document, merged into the target. null removes a key:
+	```
+	patch, _ := jsonParser.ParseJson(`{"server": {"port": 8080}, "debug": null}`)
+	patched := config.ApplyMergePatch(patch)
+	```
+
+	Both return a new document & leave the original untouched, so a patch that fails part way
+	through changes nothing. Errors from ApplyPatch() are *PatchError, naming the operation that
+	failed.
+*/
+
+// Returned (wrapped in a PatchError) when a "test" operation doesn't match.
+var ErrPatchTestFailed = errors.New("Test failed")
+
+type PatchError struct {
+	Index int    // Index of the failed operation in the patch
+	Op    string // Operation name, like "add"
+	Path  string
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf(`Patch operation %d (%s "%s") failed: %s`, e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// Applies a JSON Patch (an array of operations) & returns the patched document. Nothing is
+// changed if any operation fails.
+func (j *JsonValue) ApplyPatch(patch *JsonValue) (*JsonValue, error) {
+	operations, ok := patch.tree().([]any)
+	if !ok {
+		return nil, errors.New("Patch must be an array of operations")
+	}
+
+	// tree() builds a copy, so failing part way leaves j untouched
+	doc := j.ownTree()
+	for i, operationVal := range operations {
+		var err error
+		doc, err = applyPatchOperation(doc, operationVal, patch.mapping != nil)
+		if err != nil {
+			var patchErr *PatchError
+			if errors.As(err, &patchErr) {
+				patchErr.Index = i
+				return nil, patchErr
+			}
+			return nil, &PatchError{Index: i, Err: err}
+		}
+	}
+	return NewJsonValue(doc), nil
+}
+
+// Applies a JSON Merge Patch & returns the merged document. A merge patch can't fail: any value
+// is a valid patch.
+func (j *JsonValue) ApplyMergePatch(patch *JsonValue) *JsonValue {
+	return NewJsonValue(mergePatch(j.ownTree(), patch.tree(), patch.mapping != nil))
+}
+
+// Applies 1 JSON Patch operation to doc, which it may change, & returns the result. ownStrings
+// is passed to cloneJsonValue() for values taken from the patch.
+func applyPatchOperation(doc any, operationVal any, ownStrings bool) (any, error) {
+	operation, ok := operationVal.(map[string]any)
+	if !ok {
+		return nil, errors.New("Operation must be an object")
+	}
+	op, ok := operation["op"].(string)
+	if !ok {
+		return nil, errors.New(`Operation must have an "op" string`)
+	}
+	path, ok := operation["path"].(string)
+	if !ok {
+		return nil, &PatchError{Op: op, Err: errors.New(`Operation must have a "path" string`)}
+	}
+	wrapErr := func(err error) error {
+		return &PatchError{Op: op, Path: path, Err: err}
+	}
+
+	pathTokens, err := parsePointer(path)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	// "value" may be null, so check it's there rather than checking for nil
+	value, hasValue := operation["value"]
+	if (op == "add" || op == "replace" || op == "test") && !hasValue {
+		return nil, wrapErr(errors.New(`Operation must have a "value"`))
+	}
+
+	var fromTokens []string
+	if op == "move" || op == "copy" {
+		from, ok := operation["from"].(string)
+		if !ok {
+			return nil, wrapErr(errors.New(`Operation must have a "from" string`))
+		}
+		fromTokens, err = parsePointer(from)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+	}
+
+	switch op {
+	case "add":
+		doc, err = patchAdd(doc, pathTokens, cloneJsonValue(value, ownStrings))
+	case "remove":
+		doc, _, err = patchRemove(doc, pathTokens)
+	case "replace":
+		doc, err = patchReplace(doc, pathTokens, cloneJsonValue(value, ownStrings))
+	case "move":
+		// Moving a value into itself would orphan it
+		if len(fromTokens) < len(pathTokens) && slices.Equal(fromTokens, pathTokens[:len(fromTokens)]) {
+			return nil, wrapErr(errors.New("Can't move a value into itself"))
+		}
+		var moved any
+		doc, moved, err = patchRemove(doc, fromTokens)
+		if err == nil {
+			doc, err = patchAdd(doc, pathTokens, moved)
+		}
+	case "copy":
+		copied, ok := lookupPointer(doc, fromTokens)
+		if !ok {
+			err = pointerNotFoundError(fromTokens)
+		} else {
+			doc, err = patchAdd(doc, pathTokens, cloneJsonValue(copied, false))
+		}
+	case "test":
+		current, ok := lookupPointer(doc, pathTokens)
+		if !ok {
+			err = pointerNotFoundError(pathTokens)
+		} else if !jsonValuesEqual(current, value) {
+			err = ErrPatchTestFailed
+		}
+	default:
+		err = errors.New(fmt.Sprintf(`Unknown operation "%s"`, op))
+	}
+
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return doc, nil
+}
+
+func pointerNotFoundError(tokens []string) error {
+	pointer := ""
+	for _, token := range tokens {
+		pointer = appendPointerToken(pointer, token)
+	}
+	msg := fmt.Sprintf(`Path "%s" not found`, pointer)
+	return errors.New(msg)
+}
+
+// Walks tokens down from val (starting at tokens[depth]) to the container holding the target, &
+// calls update with it & the last token. The container is replaced with the 1 update returns,
+// since changing an array's length makes a new slice. Returns val with the change made.
+func updateAtPointer(val any, tokens []string, depth int, update func(container any, token string) (any, error)) (any, error) {
+	if depth == len(tokens)-1 {
+		return update(val, tokens[depth])
+	}
+
+	token := tokens[depth]
+	switch container := val.(type) {
+	case map[string]any:
+		child, ok := container[token]
+		if !ok {
+			return nil, pointerNotFoundError(tokens)
+		}
+		newChild, err := updateAtPointer(child, tokens, depth+1, update)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = newChild
+		return container, nil
+	case []any:
+		index, ok := parsePointerIndex(token)
+		if !ok || index >= len(container) {
+			return nil, pointerNotFoundError(tokens)
+		}
+		newChild, err := updateAtPointer(container[index], tokens, depth+1, update)
+		if err != nil {
+			return nil, err
+		}
+		container[index] = newChild
+		return container, nil
+	}
+	return nil, pointerNotFoundError(tokens)
+}
+
+// Adds value at the pointer, inserting it into arrays or setting it in objects. Adding at the
+// root replaces the whole document.
+func patchAdd(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return updateAtPointer(doc, tokens, 0, func(container any, token string) (any, error) {
+		switch typedContainer := container.(type) {
+		case map[string]any:
+			typedContainer[token] = value
+			return typedContainer, nil
+		case []any:
+			if token == "-" {
+				return append(typedContainer, value), nil
+			}
+			index, ok := parsePointerIndex(token)
+			if !ok || index > len(typedContainer) {
+				msg := fmt.Sprintf(`Array index "%s" out of range`, token)
+				return nil, errors.New(msg)
+			}
+			return slices.Insert(typedContainer, index, value), nil
+		}
+		return nil, errors.New(fmt.Sprintf(`Can't add "%s" to a %s`, token, jsonTypeName(container)))
+	})
+}
+
+// Removes the value at the pointer, which must exist, & returns it.
+func patchRemove(doc any, tokens []string) (any, any, error) {
+	if len(tokens) == 0 {
+		return nil, nil, errors.New("Can't remove the whole document")
+	}
+
+	var removed any
+	doc, err := updateAtPointer(doc, tokens, 0, func(container any, token string) (any, error) {
+		switch typedContainer := container.(type) {
+		case map[string]any:
+			value, ok := typedContainer[token]
+			if ok {
+				removed = value
+				delete(typedContainer, token)
+				return typedContainer, nil
+			}
+		case []any:
+			index, ok := parsePointerIndex(token)
+			if ok && index < len(typedContainer) {
+				removed = typedContainer[index]
+				return slices.Delete(typedContainer, index, index+1), nil
+			}
+		}
+		return nil, pointerNotFoundError(tokens)
+	})
+	return doc, removed, err
+}
+
+// Replaces the value at the pointer, which must exist.
+func patchReplace(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return updateAtPointer(doc, tokens, 0, func(container any, token string) (any, error) {
+		switch typedContainer := container.(type) {
+		case map[string]any:
+			if _, ok := typedContainer[token]; ok {
+				typedContainer[token] = value
+				return typedContainer, nil
+			}
+		case []any:
+			index, ok := parsePointerIndex(token)
+			if ok && index < len(typedContainer) {
+				typedContainer[index] = value
+				return typedContainer, nil
+			}
+		}
+		return nil, pointerNotFoundError(tokens)
+	})
+}
+
+// Merges patch into target as RFC 7386 describes, changing target, & returns the result.
+func mergePatch(target any, patch any, ownStrings bool) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return cloneJsonValue(patch, ownStrings)
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	for key, patchVal := range patchObject {
+		if patchVal == nil {
+			delete(targetObject, key)
+			continue
+		}
+		if ownStrings {
+			key = strings.Clone(key)
+		}
+		targetObject[key] = mergePatch(targetObject[key], patchVal, ownStrings)
+	}
+	return targetObject
+}
@@ -0,0 +1,131 @@
+package jsonParser
+
+/*
+	Tests JSON Patch & JSON Merge Patch.
+*/
+
+import (
+	"errors"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func applyPatchWithStr(t *testing.T, docStr string, patchStr string) (*JsonValue, error) {
+	doc, err := ParseJson(docStr)
+	assert.Nil(t, err, "Expected document to parse")
+	patch, err := ParseJson(patchStr)
+	assert.Nil(t, err, "Expected patch to parse")
+	return doc.ApplyPatch(patch)
+}
+
+func TestPatchOperations(t *testing.T) {
+	// Mostly examples from RFC 6902 appendix A
+	tests := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc"]}]`, `{"foo":["bar",["abc"]]}`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "", "value": [1]}]`, `[1]`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/child", "value": null}]`, `{"child":null,"foo":"bar"}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`, `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"a": {"b": 1}}`, `[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "replace", "path": "/c/b", "value": 2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{`{"baz": "qux", "foo": ["a", 2, "c"]}`, `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": 10}, {"op": "remove", "path": "/~1"}]`, `{"~1":10}`},
+	}
+
+	for _, test := range tests {
+		patched, err := applyPatchWithStr(t, test.doc, test.patch)
+		assert.Nil(t, err, "Expected patch to apply: "+test.patch)
+		if err == nil {
+			assert.Equal(t, serializeToStr(t, patched), test.expected, "Unexpected result for "+test.patch)
+		}
+	}
+}
+
+func TestPatchErrors(t *testing.T) {
+	tests := []struct {
+		patch string
+		index int
+	}{
+		{`[{"op": "add", "path": "/a/b", "value": 1}]`, 0},
+		{`[{"op": "add", "path": "/arr/5", "value": 1}]`, 0},
+		{`[{"op": "replace", "path": "/x", "value": 1}, {"op": "remove", "path": "/missing"}]`, 1},
+		{`[{"op": "remove", "path": "/arr/3"}]`, 0},
+		{`[{"op": "remove", "path": ""}]`, 0},
+		{`[{"op": "replace", "path": "/missing", "value": 1}]`, 0},
+		{`[{"op": "add", "path": "/y"}]`, 0},
+		{`[{"op": "move", "from": "/obj", "path": "/obj/inner"}]`, 0},
+		{`[{"op": "copy", "from": "/missing", "path": "/y"}]`, 0},
+		{`[{"op": "test", "path": "/x", "value": 2}, {"op": "add", "path": "/y", "value": 1}]`, 0},
+		{`[{"op": "add", "path": "/y", "value": 1}, {"op": "frobnicate", "path": "/x"}]`, 1},
+		{`[{"op": "add", "path": "y", "value": 1}]`, 0},
+		{`[{"path": "/x"}]`, 0},
+		{`[1]`, 0},
+	}
+
+	docStr := `{"x": 1, "arr": [1, 2, 3], "obj": {"a": 1}}`
+	for _, test := range tests {
+		doc, _ := ParseJson(docStr)
+		patch, _ := ParseJson(test.patch)
+		_, err := doc.ApplyPatch(patch)
+
+		var patchErr *PatchError
+		assert.Equal(t, errors.As(err, &patchErr), true, "Expected PatchError for "+test.patch)
+		if patchErr != nil {
+			assert.Equal(t, patchErr.Index, test.index, "Unexpected failed operation index for "+test.patch)
+		}
+
+		// Nothing applied, even for operations before the failed 1
+		assert.Equal(t, serializeToStr(t, doc), `{"arr":[1,2,3],"obj":{"a":1},"x":1}`, "Expected document to be untouched")
+	}
+
+	_, err := applyPatchWithStr(t, `{"x": 1}`, `[{"op": "test", "path": "/x", "value": 2}]`)
+	assert.Equal(t, errors.Is(err, ErrPatchTestFailed), true, "Expected test failure error")
+	assert.Equal(t, err.Error(), `Patch operation 0 (test "/x") failed: Test failed`, "Unexpected error message")
+
+	_, err = applyPatchWithStr(t, `{}`, `{"op": "add"}`)
+	assert.NotNil(t, err, "Expected error for patch that isn't an array")
+}
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7386 appendix A
+	tests := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		{`{"a": "b"}`, `{"a": "c"}`, `{"a":"c"}`},
+		{`{"a": "b"}`, `{"b": "c"}`, `{"a":"b","b":"c"}`},
+		{`{"a": "b"}`, `{"a": null}`, `{}`},
+		{`{"a": "b", "b": "c"}`, `{"a": null}`, `{"b":"c"}`},
+		{`{"a": ["b"]}`, `{"a": "c"}`, `{"a":"c"}`},
+		{`{"a": "c"}`, `{"a": ["b"]}`, `{"a":["b"]}`},
+		{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a":{"b":"d"}}`},
+		{`{"a": [{"b": "c"}]}`, `{"a": [1]}`, `{"a":[1]}`},
+		{`["a", "b"]`, `["c", "d"]`, `["c","d"]`},
+		{`{"a": "b"}`, `["c"]`, `["c"]`},
+		{`{"a": "foo"}`, `null`, `null`},
+		{`{"a": "foo"}`, `"bar"`, `"bar"`},
+		{`{"e": null}`, `{"a": 1}`, `{"a":1,"e":null}`},
+		{`[1, 2]`, `{"a": "b", "c": null}`, `{"a":"b"}`},
+		{`{}`, `{"a": {"bb": {"ccc": null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range tests {
+		doc, _ := ParseJson(test.doc)
+		patch, _ := ParseJson(test.patch)
+		original := serializeToStr(t, doc)
+
+		merged := doc.ApplyMergePatch(patch)
+		assert.Equal(t, serializeToStr(t, merged), test.expected, "Unexpected result for "+test.patch)
+		assert.Equal(t, serializeToStr(t, doc), original, "Expected document to be untouched")
+	}
+}
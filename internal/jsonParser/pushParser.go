@@ -0,0 +1,563 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+/*
+	PushParser parses JSON that arrives in chunks, like data from socket callbacks, where there's
+	no reader to pull from. Bytes are pushed in with Write() as they come, & split anywhere: mid
+	string, mid number, mid escape, etc. Completed values & events are handed to callbacks during
+	the Write() that completes them.
+
+	```
+	parser := jsonParser.NewPushParser(jsonParser.DefaultParseOptions())
+	parser.OnValue = func(value *jsonParser.JsonValue, offset int) error {
+		...
+		return nil
+	}
+	conn.OnData(func(chunk []byte) {
+		if _, err := parser.Write(chunk); err != nil {
+			... // A *ParseError, positioned in the whole stream
+		}
+	})
+	conn.OnClose(func() {
+		err := parser.Close()
+	})
+	```
+
+	The stream can hold any number of values, like Decoder (see decoder.go): `{...}{...}\n[...]`.
+	OnValue gets each 1 once it's complete, with its offset in the stream. OnEvent gets tokens as
+	they're read, for handling a value before it's finished, & without building it if OnValue is
+	nil. Either callback can return an error to stop parsing.
+
+	PushParser is an io.Writer, so io.Copy() can feed it too. Chunks aren't kept after Write()
+	returns, so the caller can reuse them.
+
+	Strings & numbers are only decoded once they're complete, using the lexer & parser, so they
+	follow the options exactly like ParseJson(). Relaxed mode isn't supported. Limits apply per
+	value, so set MaxDocumentSize to bound how much of an unfinished value is buffered.
+
+	A number at the end of the stream isn't complete until Close(), since more digits could come.
+	Errors are sticky: after 1, Write() & Close() keep returning it.
+*/
+
+// A token read by PushParser. Commas & colons aren't reported.
+type PushEvent struct {
+	Type   TokenType // Object & array starts & ends, strings, numbers, bools & nulls
+	Value  string    // Decoded string or key, number literal, or the bool or null literal
+	Key    bool      // The string is an object key
+	Offset int       // Byte offset of the token's first character in the stream
+	Depth  int       // Number of containers around the token. A container's start & end are outside it.
+}
+
+type PushParser struct {
+	// Called with each complete value & the byte offset it starts at in the stream. Optional.
+	OnValue func(value *JsonValue, offset int) error
+	// Called with each token as it's read. Optional.
+	OnEvent func(event PushEvent) error
+
+	options ParseOptions
+	err     error // First error, returned from every call after it
+	closed  bool
+
+	// Grammar
+	state pushState
+	stack []pushContainer // Open objects & arrays
+
+	// Token being read, which may be split across chunks
+	tokenKind  pushTokenKind
+	token      []byte // Raw bytes of the string, number or literal so far
+	tokenStart int    // Stream offset of the token's first character
+	isKey      bool   // The string is an object key
+	escaped    bool   // The string's last byte was an unescaped backslash
+	literal    string // The literal being read: true, false or null
+
+	// Position
+	chunkOffset int // Stream offset of the current chunk's first byte
+	line        int // Line of the current byte, starting at 1
+	lineStart   int // Stream offset of the start of that line
+
+	// Value being read
+	inValue    bool
+	valueStart int
+	numTokens  int
+	builder    *Parser // Builds the value onto a tape, only used with OnValue
+}
+
+type pushState uint8
+
+const (
+	pushValue      pushState = iota // Expecting a value
+	pushFirstItem                   // After "[", expecting a value or "]"
+	pushFirstKey                    // After "{", expecting a key or "}"
+	pushKey                         // After "," in an object, expecting a key
+	pushColon                       // After a key, expecting ":"
+	pushAfterValue                  // After a value in a container, expecting "," or the close
+)
+
+type pushTokenKind uint8
+
+const (
+	pushNoToken pushTokenKind = iota
+	pushString
+	pushNumber
+	pushLiteral
+)
+
+type pushContainer struct {
+	isObject  bool
+	tapeIndex int // Index of the container's node, when building
+	numItems  int
+}
+
+// Creates a push parser that parses each value with the given options.
+func NewPushParser(options ParseOptions) *PushParser {
+	p := &PushParser{
+		options: options,
+		line:    1,
+	}
+	if options.Relaxed {
+		p.err = errors.New("PushParser doesn't support relaxed mode")
+	}
+	return p
+}
+
+// Parses the next chunk of the stream, calling OnValue & OnEvent for whatever it completes.
+// Returns the number of bytes parsed, which is len(chunk) unless there's an error.
+func (p *PushParser) Write(chunk []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	if p.closed {
+		return 0, errors.New("PushParser written to after Close")
+	}
+
+	// Read as a string without copying, so the string helpers can be used. Anything kept past
+	// this call is copied out first.
+	data := unsafe.String(unsafe.SliceData(chunk), len(chunk))
+	for i := 0; i < len(data); {
+		var err error
+		if i, err = p.step(data, i); err != nil {
+			p.err = err
+			return i, err
+		}
+	}
+	p.chunkOffset += len(data)
+	return len(data), nil
+}
+
+// Ends the stream, finishing a number at the end of it. Returns an error if a value isn't
+// complete.
+func (p *PushParser) Close() error {
+	if p.err != nil {
+		return p.err
+	}
+	p.closed = true
+
+	if p.tokenKind == pushNumber {
+		if err := p.finishNumber(); err != nil {
+			p.err = err
+			return err
+		}
+	}
+
+	end := p.chunkOffset
+	switch {
+	case p.tokenKind == pushString:
+		p.err = p.errorAt(p.tokenStart, errors.New("End quote for string not found"))
+	case p.tokenKind == pushLiteral:
+		msg := fmt.Sprintf("Expected \"%s\", found end of data instead", p.literal)
+		p.err = p.errorAt(p.tokenStart, errors.New(msg))
+	case p.inValue:
+		p.err = p.errorAt(end, errors.New("Value isn't closed before end of data"))
+	}
+	return p.err
+}
+
+// Parses from data[i], returning the index to carry on from.
+func (p *PushParser) step(data string, i int) (int, error) {
+	// Carry on with a token started in an earlier chunk, or earlier in this 1
+	switch p.tokenKind {
+	case pushString:
+		return p.readString(data, i)
+	case pushNumber:
+		return p.readNumber(data, i)
+	case pushLiteral:
+		return p.readLiteral(data, i)
+	}
+
+	c := data[i]
+	pos := p.chunkOffset + i
+	switch c {
+	case '\n':
+		p.line += 1
+		p.lineStart = pos + 1
+		fallthrough
+	case ' ', '\t', '\r':
+		return i + 1, p.checkDocumentSize(pos + 1)
+	}
+
+	if !p.inValue {
+		p.startValue(pos)
+	}
+	if err := p.checkDocumentSize(pos + 1); err != nil {
+		return i, err
+	}
+
+	switch p.state {
+	case pushColon:
+		if c != JSON_SYNTAX_COLON[0] {
+			msg := fmt.Sprintf("Expected field assignment \"%s\", found \"%c\" instead", JSON_SYNTAX_COLON, c)
+			return i, p.errorAt(pos, errors.New(msg))
+		}
+		p.state = pushValue
+		return i + 1, p.countToken(pos)
+
+	case pushAfterValue:
+		top := &p.stack[len(p.stack)-1]
+		if c == JSON_SYNTAX_COMMA[0] {
+			p.state = pushValue
+			if top.isObject {
+				p.state = pushKey
+			}
+			return i + 1, p.countToken(pos)
+		}
+		if (top.isObject && c == JSON_SYNTAX_RIGHT_BRACE[0]) || (!top.isObject && c == JSON_SYNTAX_RIGHT_BRACKET[0]) {
+			return i + 1, p.closeContainer(pos)
+		}
+		msg := fmt.Sprintf("Expected item separator \"%s\" or close array \"%s\", found \"%c\" instead", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACKET, c)
+		if top.isObject {
+			msg = fmt.Sprintf("Expected field separator \"%s\" or close object \"%s\", found \"%c\" instead", JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACE, c)
+		}
+		return i, p.errorAt(pos, errors.New(msg))
+
+	case pushFirstKey, pushKey:
+		if p.state == pushFirstKey && c == JSON_SYNTAX_RIGHT_BRACE[0] {
+			return i + 1, p.closeContainer(pos)
+		}
+		if c != JSON_SYNTAX_QUOTE[0] {
+			return i, p.errorAt(pos, errors.New(fmt.Sprintf("Expected key string, found \"%c\" instead", c)))
+		}
+		if err := p.countItem(pos); err != nil {
+			return i, err
+		}
+		return p.startString(data, i, true)
+
+	case pushFirstItem:
+		if c == JSON_SYNTAX_RIGHT_BRACKET[0] {
+			return i + 1, p.closeContainer(pos)
+		}
+	}
+
+	// Expecting a value
+	if len(p.stack) > 0 && !p.stack[len(p.stack)-1].isObject {
+		if err := p.countItem(pos); err != nil {
+			return i, err
+		}
+	}
+	switch {
+	case c == JSON_SYNTAX_LEFT_BRACE[0] || c == JSON_SYNTAX_LEFT_BRACKET[0]:
+		return i + 1, p.openContainer(pos, c == JSON_SYNTAX_LEFT_BRACE[0])
+	case c == JSON_SYNTAX_QUOTE[0]:
+		return p.startString(data, i, false)
+	case c == '-' || (c >= '0' && c <= '9'):
+		p.startToken(pushNumber, pos)
+		return p.readNumber(data, i)
+	case c == JSON_SYNTAX_BOOL_TRUE[0] || c == JSON_SYNTAX_BOOL_FALSE[0] || c == JSON_SYNTAX_NULL[0]:
+		p.startToken(pushLiteral, pos)
+		switch c {
+		case JSON_SYNTAX_BOOL_TRUE[0]:
+			p.literal = JSON_SYNTAX_BOOL_TRUE
+		case JSON_SYNTAX_BOOL_FALSE[0]:
+			p.literal = JSON_SYNTAX_BOOL_FALSE
+		default:
+			p.literal = JSON_SYNTAX_NULL
+		}
+		return p.readLiteral(data, i)
+	}
+	return i, p.errorAt(pos, errors.New(fmt.Sprintf("Expected value, found \"%c\" instead", c)))
+}
+
+// Starts a new top level value at pos.
+func (p *PushParser) startValue(pos int) {
+	p.inValue = true
+	p.valueStart = pos
+	p.numTokens = 0
+	p.state = pushValue
+	if p.OnValue != nil {
+		p.builder = newParser(nil)
+		p.builder.options = p.options
+		p.builder.keys.seed(p.options.ExpectedKeys)
+	}
+}
+
+// Called when a value is complete. Hands top level values to OnValue, otherwise moves on to the
+// separator or close.
+func (p *PushParser) finishValue() error {
+	if len(p.stack) > 0 {
+		p.state = pushAfterValue
+		return nil
+	}
+
+	p.inValue = false
+	p.state = pushValue
+	if p.builder == nil {
+		return nil
+	}
+	p.builder.profileKeyInterning()
+	value := &JsonValue{tape: p.builder.tape}
+	p.builder = nil
+	return p.OnValue(value, p.valueStart)
+}
+
+func (p *PushParser) openContainer(pos int, isObject bool) error {
+	if err := p.countToken(pos); err != nil {
+		return err
+	}
+	if p.options.MaxDepth > 0 && len(p.stack)+1 > p.options.MaxDepth {
+		return p.errorAt(pos, ErrMaxDepthExceeded)
+	}
+
+	container := pushContainer{isObject: isObject}
+	event := PushEvent{Type: JsonArrayStart, Offset: pos, Depth: len(p.stack)}
+	kind := tapeArray
+	p.state = pushFirstItem
+	if isObject {
+		event.Type = JsonObjectStart
+		kind = tapeObject
+		p.state = pushFirstKey
+	}
+	if p.builder != nil {
+		container.tapeIndex = p.builder.tape.openContainer(kind)
+	}
+	p.stack = append(p.stack, container)
+	return p.emit(event)
+}
+
+func (p *PushParser) closeContainer(pos int) error {
+	if err := p.countToken(pos); err != nil {
+		return err
+	}
+
+	container := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	if p.builder != nil {
+		p.builder.tape.closeContainer(container.tapeIndex)
+	}
+	event := PushEvent{Type: JsonArrayEnd, Offset: pos, Depth: len(p.stack)}
+	if container.isObject {
+		event.Type = JsonObjectEnd
+	}
+	if err := p.emit(event); err != nil {
+		return err
+	}
+	return p.finishValue()
+}
+
+func (p *PushParser) startToken(kind pushTokenKind, pos int) {
+	p.tokenKind = kind
+	p.tokenStart = pos
+	p.token = p.token[:0]
+}
+
+// Starts the string or key whose opening quote is at data[i].
+func (p *PushParser) startString(data string, i int, isKey bool) (int, error) {
+	if err := p.countToken(p.chunkOffset + i); err != nil {
+		return i, err
+	}
+	p.startToken(pushString, p.chunkOffset+i)
+	p.isKey = isKey
+	p.escaped = false
+	p.token = append(p.token, data[i])
+	return p.readString(data, i+1)
+}
+
+// Reads string bytes from data[i] up to the end quote, which finishes the string, or the end of
+// the chunk. Only escapes are tracked here, so a backslash-quote isn't taken for the end.
+// Everything else is checked when the string is decoded.
+func (p *PushParser) readString(data string, i int) (int, error) {
+	start := i
+	for i < len(data) {
+		if p.escaped {
+			p.escaped = false
+			i += 1
+			continue
+		}
+		i = skipPlainStringBytes(data, i, JSON_SYNTAX_QUOTE[0])
+		if i >= len(data) {
+			break
+		}
+		switch data[i] {
+		case JSON_SYNTAX_QUOTE[0]:
+			p.token = append(p.token, data[start:i+1]...)
+			if err := p.checkDocumentSize(p.chunkOffset + i + 1); err != nil {
+				return i, err
+			}
+			return i + 1, p.finishString()
+		case '\\':
+			p.escaped = true
+		}
+		i += 1
+	}
+
+	p.token = append(p.token, data[start:i]...)
+	return i, p.checkDocumentSize(p.chunkOffset + i)
+}
+
+// Decodes the complete string in token with the lexer, so escapes, UTF-8 & limits are handled
+// like ParseJson().
+func (p *PushParser) finishString() error {
+	p.tokenKind = pushNoToken
+	lexer := newLexer(string(p.token))
+	lexer.options = p.options
+	token, _, err := lexer.lexString()
+	if err != nil {
+		return relocateParseError(err, p.tokenStart, p.line, p.lineStart)
+	}
+
+	if p.isKey {
+		if p.builder != nil {
+			p.builder.keys.appendKey(p.builder.tape, token.Value)
+		}
+		p.state = pushColon
+		return p.emit(PushEvent{Type: JsonString, Value: token.Value, Key: true, Offset: p.tokenStart, Depth: len(p.stack)})
+	}
+
+	if p.builder != nil {
+		p.builder.tape.appendString(tapeString, token.Value)
+	}
+	if err := p.emit(PushEvent{Type: JsonString, Value: token.Value, Offset: p.tokenStart, Depth: len(p.stack)}); err != nil {
+		return err
+	}
+	return p.finishValue()
+}
+
+// Reads number characters from data[i]. The first other character finishes the number, & is
+// left for the grammar.
+func (p *PushParser) readNumber(data string, i int) (int, error) {
+	start := i
+	for ; i < len(data); i++ {
+		c := data[i]
+		isDigit := c >= '0' && c <= '9'
+		isSymbol := c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+		if !isDigit && !isSymbol {
+			p.token = append(p.token, data[start:i]...)
+			if err := p.checkDocumentSize(p.chunkOffset + i); err != nil {
+				return i, err
+			}
+			return i, p.finishNumber()
+		}
+	}
+
+	p.token = append(p.token, data[start:i]...)
+	return i, p.checkDocumentSize(p.chunkOffset + i)
+}
+
+// Converts the complete number in token like ParseJson(), depending on options.
+func (p *PushParser) finishNumber() error {
+	p.tokenKind = pushNoToken
+	literal := string(p.token)
+	if err := p.countToken(p.tokenStart); err != nil {
+		return err
+	}
+	if !isValidJsonNumber(literal) {
+		msg := fmt.Sprintf("Invalid number \"%s\"", literal)
+		return p.errorAt(p.tokenStart, errors.New(msg))
+	}
+	if p.builder != nil {
+		if err := p.builder.parseNumber(literal); err != nil {
+			return p.errorAt(p.tokenStart, err)
+		}
+	}
+
+	if err := p.emit(PushEvent{Type: JsonNumber, Value: literal, Offset: p.tokenStart, Depth: len(p.stack)}); err != nil {
+		return err
+	}
+	return p.finishValue()
+}
+
+// Matches the literal being read against data[i:], finishing it once it's all matched.
+func (p *PushParser) readLiteral(data string, i int) (int, error) {
+	for ; i < len(data) && len(p.token) < len(p.literal); i++ {
+		p.token = append(p.token, data[i])
+		if data[i] != p.literal[len(p.token)-1] {
+			msg := fmt.Sprintf("Expected \"%s\", found \"%s\" instead", p.literal, p.token)
+			return i, p.errorAt(p.tokenStart, errors.New(msg))
+		}
+	}
+	if err := p.checkDocumentSize(p.chunkOffset + i); err != nil {
+		return i, err
+	}
+	if len(p.token) < len(p.literal) {
+		return i, nil
+	}
+
+	p.tokenKind = pushNoToken
+	if err := p.countToken(p.tokenStart); err != nil {
+		return i, err
+	}
+	eventType := JsonBool
+	if p.builder != nil {
+		if p.literal == JSON_SYNTAX_NULL {
+			p.builder.tape.appendNode(tapeNull, 0)
+		} else {
+			p.builder.tape.appendBool(p.literal == JSON_SYNTAX_BOOL_TRUE)
+		}
+	}
+	if p.literal == JSON_SYNTAX_NULL {
+		eventType = JsonNull
+	}
+	if err := p.emit(PushEvent{Type: eventType, Value: p.literal, Offset: p.tokenStart, Depth: len(p.stack)}); err != nil {
+		return i, err
+	}
+	return i, p.finishValue()
+}
+
+func (p *PushParser) emit(event PushEvent) error {
+	if p.OnEvent == nil {
+		return nil
+	}
+	return p.OnEvent(event)
+}
+
+// Counts a token in the current value, returning an error if it goes over the MaxTokens limit.
+func (p *PushParser) countToken(pos int) error {
+	if p.options.MaxTokens > 0 && p.numTokens >= p.options.MaxTokens {
+		return p.errorAt(pos, ErrMaxTokensExceeded)
+	}
+	p.numTokens += 1
+	return nil
+}
+
+// Counts an item or member of the innermost container, returning an error if it goes over the
+// MaxContainerSize limit.
+func (p *PushParser) countItem(pos int) error {
+	top := &p.stack[len(p.stack)-1]
+	top.numItems += 1
+	if p.options.MaxContainerSize > 0 && top.numItems > p.options.MaxContainerSize {
+		return p.errorAt(pos, ErrMaxContainerSizeExceeded)
+	}
+	return nil
+}
+
+// Returns an error if the current value runs up to end, a stream offset, & that's over the
+// MaxDocumentSize limit.
+func (p *PushParser) checkDocumentSize(end int) error {
+	if p.inValue && p.options.MaxDocumentSize > 0 && end-p.valueStart > p.options.MaxDocumentSize {
+		return p.errorAt(p.valueStart+p.options.MaxDocumentSize, ErrMaxDocumentSizeExceeded)
+	}
+	return nil
+}
+
+// Returns a ParseError at the given stream offset, which must be on the current line.
+func (p *PushParser) errorAt(offset int, err error) *ParseError {
+	return &ParseError{
+		Err:    err,
+		Offset: offset,
+		Line:   p.line,
+		Column: offset - p.lineStart + 1,
+	}
+}
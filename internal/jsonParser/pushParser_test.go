@@ -0,0 +1,248 @@
+package jsonParser
+
+/*
+	Tests the push parser, checking values split into chunks at every point parse the same as
+	ParseJson().
+*/
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+type pushResult struct {
+	values  []string // Serialized
+	offsets []int
+	err     error
+}
+
+// Pushes data in chunks of the given sizes (the last size repeats), then closes.
+func pushChunks(t *testing.T, data string, options ParseOptions, sizes ...int) pushResult {
+	var result pushResult
+	parser := NewPushParser(options)
+	parser.OnValue = func(value *JsonValue, offset int) error {
+		result.values = append(result.values, serializeToStr(t, value))
+		result.offsets = append(result.offsets, offset)
+		return nil
+	}
+
+	for i := 0; i < len(data); {
+		size := sizes[0]
+		if len(sizes) > 1 {
+			sizes = sizes[1:]
+		}
+		end := min(i+size, len(data))
+		if _, result.err = parser.Write([]byte(data[i:end])); result.err != nil {
+			return result
+		}
+		i = end
+	}
+	result.err = parser.Close()
+	return result
+}
+
+func TestPushParserSplitsAnywhere(t *testing.T) {
+	inputs := []string{
+		`{"pairs": [{"x0": 1.5, "y0": -2.25e+2, "x1": 30, "y1": 0}], "ok": true, "none": null}`,
+		`"esc\"apes \\ \/ \b\f\n\r\t é 😀 é"`,
+		`[[], {}, [[1, [2]]], {"a": {"b": {}}}, "", 0, -0.5, false]`,
+		`{"dup": 1, "dup": 2}`,
+		`12345`,
+	}
+	for _, input := range inputs {
+		expected, err := ParseJson(input)
+		assert.Nil(t, err, "Expected ParseJson to succeed")
+		expectedStr := serializeToStr(t, expected)
+
+		for split := 0; split <= len(input); split++ {
+			result := pushChunks(t, input, DefaultParseOptions(), split, len(input))
+			if split == 0 {
+				// Byte at a time
+				result = pushChunks(t, input, DefaultParseOptions(), 1)
+			}
+			assert.Nil(t, result.err, fmt.Sprintf("Expected %s split at %d to parse", input, split))
+			assert.Equal(t, len(result.values), 1, fmt.Sprintf("Expected 1 value for %s split at %d", input, split))
+			if len(result.values) == 1 {
+				assert.Equal(t, result.values[0], expectedStr, fmt.Sprintf("Unexpected value for %s split at %d", input, split))
+			}
+		}
+	}
+
+	assert.Finished()
+}
+
+func TestPushParserMultipleValues(t *testing.T) {
+	input := "{\"a\": 1}[2]\n \"three\" 4 true null 5"
+	for _, size := range []int{1, 2, 3, 7, len(input)} {
+		result := pushChunks(t, input, DefaultParseOptions(), size)
+		assert.Nil(t, result.err, fmt.Sprintf("Expected stream to parse in chunks of %d", size))
+		assert.Equal(t, strings.Join(result.values, " "), `{"a":1} [2] "three" 4 true null 5`, fmt.Sprintf("Unexpected values in chunks of %d", size))
+		assert.Equal(t, fmt.Sprint(result.offsets), "[0 8 13 21 23 28 33]", fmt.Sprintf("Unexpected offsets in chunks of %d", size))
+	}
+
+	// Nothing but whitespace is fine
+	result := pushChunks(t, " \n ", DefaultParseOptions(), 1)
+	assert.Nil(t, result.err, "Expected whitespace stream to parse")
+	assert.Equal(t, len(result.values), 0, "Expected no values in whitespace stream")
+
+	// io.Copy() works, since it's a Writer
+	parser := NewPushParser(DefaultParseOptions())
+	count := 0
+	parser.OnValue = func(value *JsonValue, offset int) error {
+		count += 1
+		return nil
+	}
+	_, err := io.Copy(parser, strings.NewReader(`[1] [2] [3]`))
+	assert.Nil(t, err, "Expected io.Copy to succeed")
+	assert.Nil(t, parser.Close(), "Expected Close to succeed")
+	assert.Equal(t, count, 3, "Expected 3 values from io.Copy")
+
+	assert.Finished()
+}
+
+func TestPushParserEvents(t *testing.T) {
+	var events []string
+	parser := NewPushParser(DefaultParseOptions())
+	parser.OnEvent = func(event PushEvent) error {
+		key := ""
+		if event.Key {
+			key = "key "
+		}
+		events = append(events, fmt.Sprintf("%s %s%s @%d d%d", event.Type, key, event.Value, event.Offset, event.Depth))
+		return nil
+	}
+	for _, chunk := range []string{`{"k": [tr`, `ue, "a\`, `"b", -1`, `2]}`} {
+		_, err := parser.Write([]byte(chunk))
+		assert.Nil(t, err, "Expected chunk to parse")
+	}
+	assert.Nil(t, parser.Close(), "Expected Close to succeed")
+
+	expected := []string{
+		"ObjectStart  @0 d0",
+		"String key k @1 d1",
+		"ArrayStart  @6 d1",
+		"Bool true @7 d2",
+		`String a"b @13 d2`,
+		"Number -12 @21 d2",
+		"ArrayEnd  @24 d1",
+		"ObjectEnd  @25 d0",
+	}
+	assert.Equal(t, strings.Join(events, "\n"), strings.Join(expected, "\n"), "Unexpected events")
+
+	// A callback error stops parsing, & sticks
+	parser = NewPushParser(DefaultParseOptions())
+	stop := errors.New("stop")
+	parser.OnEvent = func(event PushEvent) error {
+		if event.Type == JsonNumber {
+			return stop
+		}
+		return nil
+	}
+	n, err := parser.Write([]byte(`["a", 1, 2]`))
+	assert.Equal(t, errors.Is(err, stop), true, "Expected callback error from Write")
+	assert.Equal(t, n, 7, "Expected Write to stop at the number's end")
+	assert.Equal(t, errors.Is(parser.Close(), stop), true, "Expected callback error from Close")
+
+	assert.Finished()
+}
+
+func TestPushParserErrors(t *testing.T) {
+	cases := []struct {
+		input  string
+		offset int
+		line   int
+	}{
+		{`[1, 2`, 5, 1},
+		{`{"a" 1}`, 5, 1},
+		{`[1 2]`, 3, 1},
+		{`{"a": 1]`, 7, 1},
+		{"[\n  tru", 4, 2},
+		{"[\n  trux]", 4, 2},
+		{`"abc`, 0, 1},
+		{"\n\n  \"a\\qb\"", 6, 3},
+		{`[01]`, 1, 1},
+		{`[-]`, 1, 1},
+		{`{,}`, 1, 1},
+		{`[1,]`, 3, 1},
+		{"[\"a\x01\"]", 3, 1},
+		{`}`, 0, 1},
+	}
+	for _, c := range cases {
+		_, parseErr := ParseJson(c.input)
+		assert.NotNil(t, parseErr, fmt.Sprintf("Expected ParseJson to reject %q", c.input))
+
+		for _, size := range []int{1, 2, len(c.input)} {
+			result := pushChunks(t, c.input, DefaultParseOptions(), size)
+			var err *ParseError
+			assert.Equal(t, errors.As(result.err, &err), true, fmt.Sprintf("Expected ParseError for %q in chunks of %d", c.input, size))
+			if err != nil {
+				assert.Equal(t, err.Offset, c.offset, fmt.Sprintf("Unexpected offset for %q in chunks of %d: %s", c.input, size, err))
+				assert.Equal(t, err.Line, c.line, fmt.Sprintf("Unexpected line for %q in chunks of %d: %s", c.input, size, err))
+			}
+		}
+	}
+
+	// Errors stick
+	parser := NewPushParser(DefaultParseOptions())
+	_, err := parser.Write([]byte(`[}`))
+	assert.NotNil(t, err, "Expected syntax error")
+	_, again := parser.Write([]byte(`]`))
+	assert.Equal(t, again, err, "Expected Write to repeat the error")
+	assert.Equal(t, parser.Close(), err, "Expected Close to repeat the error")
+
+	// Relaxed mode isn't supported
+	options := DefaultParseOptions()
+	options.Relaxed = true
+	_, err = NewPushParser(options).Write([]byte(`[]`))
+	assert.NotNil(t, err, "Expected relaxed mode to be rejected")
+
+	assert.Finished()
+}
+
+func TestPushParserOptions(t *testing.T) {
+	limits := []struct {
+		input    string
+		options  ParseOptions
+		expected error
+	}{
+		{`[[[1]]]`, ParseOptions{MaxDepth: 2}, ErrMaxDepthExceeded},
+		{`["abcdef"]`, ParseOptions{MaxStringLength: 5}, ErrMaxStringLengthExceeded},
+		{`{"a": 1, "b": 2, "c": 3}`, ParseOptions{MaxContainerSize: 2}, ErrMaxContainerSizeExceeded},
+		{`[1, 2, 3]`, ParseOptions{MaxTokens: 4}, ErrMaxTokensExceeded},
+		{`["a long unfinished string`, ParseOptions{MaxDocumentSize: 8}, ErrMaxDocumentSizeExceeded},
+		{`"bad ` + "\xff" + `"`, ParseOptions{}, ErrInvalidUTF8},
+	}
+	for _, limit := range limits {
+		result := pushChunks(t, limit.input, limit.options, 3)
+		assert.Equal(t, errors.Is(result.err, limit.expected), true, fmt.Sprintf("Expected %v for %s, got %v", limit.expected, limit.input, result.err))
+	}
+
+	// Limits are per value
+	result := pushChunks(t, `[1, 2] [3, 4]`, ParseOptions{MaxDocumentSize: 6, MaxContainerSize: 2}, 2)
+	assert.Nil(t, result.err, "Expected limits to apply per value")
+
+	// Number mode, UTF-8 replacement & seeded keys
+	options := ParseOptions{UseNumber: true, InvalidUTF8: InvalidUTF8Replace, ExpectedKeys: []string{"n"}}
+	var value *JsonValue
+	parser := NewPushParser(options)
+	parser.OnValue = func(v *JsonValue, offset int) error {
+		value = v
+		return nil
+	}
+	for _, chunk := range []string{`{"n": 12345678901234567890`, `1, "s": "a`, "\xff", `"}`} {
+		_, err := parser.Write([]byte(chunk))
+		assert.Nil(t, err, "Expected chunk to parse")
+	}
+	assert.Nil(t, parser.Close(), "Expected Close to succeed")
+	n, _ := value.GetNumber("n")
+	assert.Equal(t, n, Number("123456789012345678901"), "Expected number mode literal")
+	s, _ := value.GetString("s")
+	assert.Equal(t, s, "a�", "Expected bad byte to be replaced")
+
+	assert.Finished()
+}
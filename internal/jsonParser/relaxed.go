@@ -0,0 +1,242 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+/*
+	Relaxed mode accepts JSON5 & JSONC (JSON with comments), for hand-edited config files. Turn it
+	on with `ParseOptions{Relaxed: true}`. Strict JSON stays the default.
+
+	Supported on top of strict JSON:
+	- Comments: `// line comments` & block comments (slash star ... star slash)
+	- Trailing commas in objects & arrays: `[1, 2,]`
+	- Single quoted strings: `'it\'s'`
+	- Extra escapes: `\'`, `\v`, `\0`, `\xFF`, & backslash-newline line continuations
+	- Unquoted object keys made of ASCII letters, digits, `_` & `$`: `{key: 1}`
+	- Numbers: hex (`0x1F`), leading `+`, leading or trailing decimal point (`.5`, `5.`),
+	  `Infinity` & `NaN`, all optionally signed
+	- Extra whitespace: vertical tab, form feed, non-breaking space, line & paragraph separators
+	  & byte order mark
+
+	Not supported: Unicode letters & \u escapes in unquoted keys.
+*/
+
+const JSON5_SYNTAX_SINGLE_QUOTE = "'"
+const JSON5_SYNTAX_INFINITY = "Infinity"
+const JSON5_SYNTAX_NAN = "NaN"
+
+// Extra whitespace allowed in relaxed mode, on top of JSON_SYNTAX_WHITESPACE.
+const JSON5_SYNTAX_WHITESPACE = "\v\f\u00a0\u2028\u2029\ufeff"
+
+// Scans for comments & relaxed whitespace & returns number of characters consumed. Comments are
+// thrown away, same as whitespace.
+func (l *Lexer) lexRelaxedWhitespace() (int, error) {
+	s := l.getUnlexedData()
+
+	// Extra whitespace
+	for _, ws := range JSON5_SYNTAX_WHITESPACE {
+		if strings.HasPrefix(s, string(ws)) {
+			return len(string(ws)), nil
+		}
+	}
+
+	// Line comment, runs to end of line or end of data
+	if strings.HasPrefix(s, "//") {
+		end := strings.IndexAny(s, "\n\r")
+		if end < 0 {
+			return len(s), nil
+		}
+		return end, nil
+	}
+
+	// Block comment
+	if strings.HasPrefix(s, "/*") {
+		end := strings.Index(s[2:], "*/")
+		if end < 0 {
+			return 0, newParseError(l.data, l.pos, errors.New("End of block comment not found"))
+		}
+		return end + 4, nil
+	}
+
+	return 0, nil
+}
+
+// Scans for unquoted identifiers (like object keys) & returns it with number of characters
+// consumed. Identifiers that are bool or null literals are returned as bool or null tokens.
+func (l *Lexer) lexIdentifier() (*Token, int) {
+	s := l.getUnlexedData()
+
+	numCharsRead := 0
+	for numCharsRead < len(s) && isIdentifierChar(s[numCharsRead], numCharsRead == 0) {
+		numCharsRead += 1
+	}
+	if numCharsRead == 0 {
+		return nil, 0
+	}
+
+	value := s[:numCharsRead]
+	if value == JSON_SYNTAX_BOOL_TRUE || value == JSON_SYNTAX_BOOL_FALSE {
+		return &Token{Type: JsonBool, Value: value}, numCharsRead
+	}
+	if value == JSON_SYNTAX_NULL {
+		return &Token{Type: JsonNull, Value: value}, numCharsRead
+	}
+	return &Token{Type: JsonIdentifier, Value: value}, numCharsRead
+}
+
+// Returns true if c can be part of an identifier. The first character can't be a digit.
+func isIdentifierChar(c byte, first bool) bool {
+	isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '$'
+	isDigit := c >= '0' && c <= '9'
+	return isLetter || (isDigit && !first)
+}
+
+// Scans for numbers only relaxed mode allows, hex (like "0x1F") & signed Infinity or NaN (like
+// "-Infinity"), & returns it with number of characters consumed. Other relaxed numbers are
+// handled by lexNumber().
+func (l *Lexer) lexRelaxedNumber() (*Token, int) {
+	s := l.getUnlexedData()
+
+	i := 0
+	if s[0] == '+' || s[0] == '-' {
+		i += 1
+	}
+	rest := s[i:]
+
+	// Hex
+	if len(rest) > 2 && rest[0] == '0' && (rest[1] == 'x' || rest[1] == 'X') {
+		numCharsRead := i + 2
+		for numCharsRead < len(s) && isHexDigit(s[numCharsRead]) {
+			numCharsRead += 1
+		}
+		if numCharsRead > i+2 {
+			return &Token{Type: JsonNumber, Value: s[:numCharsRead]}, numCharsRead
+		}
+		return nil, 0
+	}
+
+	// Signed Infinity & NaN. Unsigned ones are lexed as identifiers since they can also be keys.
+	if i > 0 {
+		for _, special := range []string{JSON5_SYNTAX_INFINITY, JSON5_SYNTAX_NAN} {
+			if strings.HasPrefix(rest, special) {
+				numCharsRead := i + len(special)
+				return &Token{Type: JsonNumber, Value: s[:numCharsRead]}, numCharsRead
+			}
+		}
+	}
+
+	return nil, 0
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// Returns the value of a hex digit, which must be valid.
+func hexDigitValue(c byte) rune {
+	switch {
+	case c >= 'a':
+		return rune(c-'a') + 10
+	case c >= 'A':
+		return rune(c-'A') + 10
+	}
+	return rune(c - '0')
+}
+
+// Returns true if the identifier is a special number, Infinity or NaN.
+func isRelaxedSpecialNumber(s string) bool {
+	return s == JSON5_SYNTAX_INFINITY || s == JSON5_SYNTAX_NAN
+}
+
+// Rewrites a relaxed number literal into a strict one. Infinity & NaN have no strict literal, so
+// they're returned as special (a float64, or Number in number mode) with isSpecial = true.
+func normalizeRelaxedNumber(literal string, useNumber bool) (normalized string, special any, isSpecial bool, err error) {
+	sign := ""
+	unsigned := literal
+	if len(literal) > 0 && (literal[0] == '+' || literal[0] == '-') {
+		if literal[0] == '-' {
+			sign = "-"
+		}
+		unsigned = literal[1:]
+	}
+
+	// Infinity & NaN
+	if isRelaxedSpecialNumber(unsigned) {
+		if useNumber {
+			return literal, Number(sign + unsigned), true, nil
+		}
+		if unsigned == JSON5_SYNTAX_NAN {
+			return literal, math.NaN(), true, nil
+		}
+		if sign == "-" {
+			return literal, math.Inf(-1), true, nil
+		}
+		return literal, math.Inf(1), true, nil
+	}
+
+	// Hex, converted to decimal. big.Int so it works for any size in number mode.
+	if len(unsigned) > 2 && unsigned[0] == '0' && (unsigned[1] == 'x' || unsigned[1] == 'X') {
+		value, ok := new(big.Int).SetString(unsigned[2:], 16)
+		if !ok {
+			msg := fmt.Sprintf("Invalid hex number \"%s\"", literal)
+			return literal, nil, false, errors.New(msg)
+		}
+		return sign + value.String(), nil, false, nil
+	}
+
+	// Leading & trailing decimal points, like ".5" & "5."
+	if strings.HasPrefix(unsigned, ".") {
+		unsigned = "0" + unsigned
+	}
+	if strings.HasSuffix(unsigned, ".") {
+		unsigned = unsigned[:len(unsigned)-1]
+	} else if i := strings.Index(unsigned, ".e"); i >= 0 {
+		unsigned = unsigned[:i] + unsigned[i+1:]
+	} else if i := strings.Index(unsigned, ".E"); i >= 0 {
+		unsigned = unsigned[:i] + unsigned[i+1:]
+	}
+
+	return sign + unsigned, nil, false, nil
+}
+
+// Decodes the JSON5-only escape sequence at the start of s, writes the result to sb, & returns
+// the length of the escape sequence. Returns ok = false if it's not a JSON5-only escape.
+func writeRelaxedEscape(sb *strings.Builder, s string) (int, bool) {
+	switch s[1] {
+	case '\'':
+		sb.WriteByte('\'')
+	case 'v':
+		sb.WriteByte('\v')
+	case '0':
+		// Only a null if it's not followed by another digit, JSON5 has no octal escapes
+		if len(s) > 2 && s[2] >= '0' && s[2] <= '9' {
+			return 0, false
+		}
+		sb.WriteByte(0)
+	case 'x':
+		if len(s) < 4 || !isHexDigit(s[2]) || !isHexDigit(s[3]) {
+			return 0, false
+		}
+		sb.WriteRune(hexDigitValue(s[2])<<4 | hexDigitValue(s[3]))
+		return 4, true
+	// Line continuations are removed
+	case '\n':
+	case '\r':
+		if len(s) > 2 && s[2] == '\n' {
+			return 3, true
+		}
+	default:
+		// Line & paragraph separators, U+2028 & U+2029
+		if strings.HasPrefix(s[1:], "\u2028") || strings.HasPrefix(s[1:], "\u2029") {
+			return 1 + len("\u2028"), true
+		}
+		return 0, false
+	}
+
+	return 2, true
+}
@@ -0,0 +1,167 @@
+package jsonParser
+
+/*
+	Tests relaxed (JSON5 & JSONC) mode.
+*/
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func runParserRelaxed(s string) (*JsonValue, error) {
+	options := DefaultParseOptions()
+	options.Relaxed = true
+	return ParseJsonWithOptions(s, options)
+}
+
+func TestRelaxedConfigFile(t *testing.T) {
+	result, err := runParserRelaxed(`
+		// Hand-edited config
+		{
+			/* Unquoted keys
+			   & single quotes */
+			name: 'it\'s "quoted"',
+			$special_key1: "ok",
+			trueish: true,
+			'single': 'line \
+continued',
+			list: [1, 2, 3,], // Trailing comma
+			nested: { a: 1, },
+		}
+	`)
+	assert.Nil(t, err, "Expected relaxed config to parse")
+
+	name, _ := result.GetString("name")
+	assert.Equal(t, name, `it's "quoted"`, "Expected single quoted string")
+	special, _ := result.GetString("$special_key1")
+	assert.Equal(t, special, "ok", "Expected identifier key with $ & digits")
+	trueish, _ := result.GetBool("trueish")
+	assert.Equal(t, trueish, true, "Expected identifier key starting with a bool literal")
+	single, _ := result.GetString("single")
+	assert.Equal(t, single, "line continued", "Expected line continuation to be removed")
+
+	list, _ := result.GetArray("list")
+	assert.Equal(t, len(list), 3, "Expected trailing comma to be ignored in array")
+	nested, _ := result.GetObject("nested")
+	a, _ := nested.GetInt("a")
+	assert.Equal(t, a, 1, "Expected trailing comma to be ignored in object")
+}
+
+func TestRelaxedNumbers(t *testing.T) {
+	result, err := runParserRelaxed(`{
+		hex: 0x1F, negHex: -0XfF, plus: +1, lead: .5, trail: 5., trailExp: 5.e2,
+		inf: Infinity, negInf: -Infinity, posInf: +Infinity, nan: NaN
+	}`)
+	assert.Nil(t, err, "Expected relaxed numbers to parse")
+
+	hex, _ := result.GetInt("hex")
+	assert.Equal(t, hex, 31, "Expected hex")
+	negHex, _ := result.GetInt("negHex")
+	assert.Equal(t, negHex, -255, "Expected negative hex")
+	plus, _ := result.GetInt("plus")
+	assert.Equal(t, plus, 1, "Expected leading plus")
+	lead, _ := result.GetFloat("lead")
+	assert.Equal(t, lead, 0.5, "Expected leading decimal point")
+	trail, _ := result.GetInt("trail")
+	assert.Equal(t, trail, 5, "Expected trailing decimal point")
+	trailExp, _ := result.GetFloat("trailExp")
+	assert.Equal(t, trailExp, 500.0, "Expected trailing decimal point with exponent")
+
+	inf, _ := result.GetFloat("inf")
+	assert.Equal(t, math.IsInf(inf, 1), true, "Expected Infinity")
+	negInf, _ := result.GetFloat("negInf")
+	assert.Equal(t, math.IsInf(negInf, -1), true, "Expected -Infinity")
+	posInf, _ := result.GetFloat("posInf")
+	assert.Equal(t, math.IsInf(posInf, 1), true, "Expected +Infinity")
+	nan, _ := result.GetFloat("nan")
+	assert.Equal(t, math.IsNaN(nan), true, "Expected NaN")
+
+	// Number mode keeps hex as decimal text, any size
+	options := DefaultParseOptions()
+	options.Relaxed = true
+	options.UseNumber = true
+	result, err = ParseJsonWithOptions(`{ big: 0xFFFFFFFFFFFFFFFFFF, inf: -Infinity }`, options)
+	assert.Nil(t, err, "Expected relaxed numbers to parse in number mode")
+	big, _ := result.GetNumber("big")
+	assert.Equal(t, big.String(), "4722366482869645213695", "Expected big hex as decimal")
+	infNumber, _ := result.GetFloat("inf")
+	assert.Equal(t, math.IsInf(infNumber, -1), true, "Expected -Infinity from Number")
+}
+
+func TestRelaxedRoundTrip(t *testing.T) {
+	// Relaxed numbers serialize as strict JSON, in both modes
+	for _, useNumber := range []bool{false, true} {
+		options := ParseOptions{Relaxed: true, UseNumber: useNumber}
+		result, err := ParseJsonWithOptions(`[0x1F, -0XfF, +1, .5, 5., 5.e2]`, options)
+		assert.Nil(t, err, "Expected relaxed numbers to parse")
+		data, err := result.Serialize()
+		assert.Nil(t, err, fmt.Sprintf("Expected relaxed numbers to serialize with UseNumber %v", useNumber))
+		strict, err := ParseJson(string(data))
+		assert.Nil(t, err, fmt.Sprintf("Expected %s to parse as strict JSON", data))
+		assert.Equal(t, Equal(strict, result), true, fmt.Sprintf("Expected %s to round trip", data))
+	}
+
+	// Infinity & NaN have no JSON literal, whether they're floats or Numbers
+	for _, useNumber := range []bool{false, true} {
+		for _, special := range []string{"Infinity", "-Infinity", "+Infinity", "NaN"} {
+			options := ParseOptions{Relaxed: true, UseNumber: useNumber}
+			result, err := ParseJsonWithOptions("["+special+"]", options)
+			assert.Nil(t, err, "Expected "+special+" to parse")
+			_, err = result.Serialize()
+			assert.NotNil(t, err, fmt.Sprintf("Expected error serializing %s with UseNumber %v", special, useNumber))
+		}
+	}
+
+	// Numbers that aren't strict JSON can't be serialized however they were made
+	_, err := NewJsonValue([]any{Number("0x1F")}).Serialize()
+	assert.NotNil(t, err, "Expected error serializing a Number that isn't strict JSON")
+}
+
+func TestRelaxedEscapes(t *testing.T) {
+	options := ParseOptions{Relaxed: true}
+	cases := map[string]string{
+		`'a\'b'`:     "a'b",
+		`"a\'b"`:     "a'b",
+		`'a"b'`:      `a"b`,
+		`'\v\0'`:     "\v\x00",
+		`'\x41\xe9'`: "Aé",
+		"'a\\\r\nb'": "ab",
+	}
+	for input, expected := range cases {
+		value, err := lexStringWithOptions(input, options)
+		assert.Nil(t, err, "Expected "+input+" to lex")
+		assert.Equal(t, value, expected, "Unexpected value for "+input)
+	}
+
+	// No octal escapes
+	_, err := lexStringWithOptions(`'\01'`, options)
+	assert.NotNil(t, err, "Expected error on octal escape, did not error")
+}
+
+func TestRelaxedInvalid(t *testing.T) {
+	// Unquoted identifiers are only allowed as keys
+	_, err := runParserRelaxed(`{ a: b }`)
+	assert.NotNil(t, err, "Expected error on identifier value, did not error")
+
+	// Unterminated block comment
+	_, err = runParserRelaxed(`{ "a": 1 } /* oops`)
+	assert.NotNil(t, err, "Expected error on unterminated comment, did not error")
+
+	// Only 1 trailing comma
+	_, err = runParserRelaxed(`{ "a": [1,,] }`)
+	assert.NotNil(t, err, "Expected error on double trailing comma, did not error")
+	_, err = runParserRelaxed(`{ "a": 1,, }`)
+	assert.NotNil(t, err, "Expected error on double trailing object comma, did not error")
+
+	// Strict mode is still the default
+	_, err = runParserWithStr(`{ a: 1 }`)
+	assert.NotNil(t, err, "Expected error on unquoted key in strict mode, did not error")
+	_, err = runParserWithStr(`{ "a": 1 } // comment`)
+	assert.NotNil(t, err, "Expected error on comment in strict mode, did not error")
+	_, err = runParserWithStr(`{ "a": 0x10 }`)
+	assert.NotNil(t, err, "Expected error on hex in strict mode, did not error")
+}
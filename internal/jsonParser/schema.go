@@ -0,0 +1,426 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+/*
+	JSON Schema validates parsed documents against a schema, which is itself JSON. Supports a
+	subset of draft 2020-12:
+	- type: "null", "boolean", "object", "array", "number", "integer", "string", or a list of them
+	- properties, required, additionalProperties
+	- items (applies to every item)
+	- enum
+	- minimum, maximum
+	- pattern (Go regexp syntax, which is close to ECMA 262 for common patterns)
+	- $ref to a JSON pointer in the same schema, like "#/$defs/pair"
+	- true & false schemas
+
+	Other keywords (like $schema, $defs, title or description) are ignored.
+
+	```
+	schemaJson, _ := jsonParser.ParseJson(`{
+		"type": "object",
+		"required": ["pairs"],
+		"properties": {"pairs": {"type": "array", "items": {"$ref": "#/$defs/pair"}}},
+		"$defs": {"pair": {"type": "object", "required": ["x0"], "properties": {"x0": {"type": "number"}}}}
+	}`)
+	schema, err := jsonParser.CompileSchema(schemaJson)
+	...
+	for _, schemaErr := range schema.Validate(jsonResult) {
+		fmt.Println(schemaErr) // "Expected type "number", found "string" at "/pairs/3/x0""
+	}
+	```
+
+	Validation carries on after a violation, so every violation in the document is returned.
+	Paths are JSON pointers (see jsonPointer.go). Numbers are compared as float64, so minimum &
+	maximum are approximate for big numbers kept with UseNumber.
+*/
+
+type Schema struct {
+	root *schemaNode
+}
+
+type SchemaError struct {
+	Path       string // JSON pointer to the value in the document
+	SchemaPath string // JSON pointer to the keyword in the schema that failed
+	Message    string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf(`%s at "%s"`, e.Message, e.Path)
+}
+
+// Compiled schema or subschema.
+type schemaNode struct {
+	path string // JSON pointer to this subschema in the schema
+
+	// For true & false schemas
+	isBool    bool
+	boolValue bool
+
+	types                []string
+	properties           map[string]*schemaNode
+	required             []string
+	additionalProperties *schemaNode
+	items                *schemaNode
+	enum                 []any
+	hasEnum              bool
+	minimum              *float64
+	maximum              *float64
+	pattern              *regexp.Regexp
+
+	ref     string
+	refNode *schemaNode
+}
+
+type schemaCompiler struct {
+	root  any
+	nodes map[string]*schemaNode // By schema path, so refs to the same subschema share a node
+	refs  []*schemaNode          // Nodes with a $ref to resolve
+}
+
+var SCHEMA_TYPES = []string{"null", "boolean", "object", "array", "number", "integer", "string"}
+
+// Compiles the given schema, checking it's valid & resolving its refs.
+func CompileSchema(schema *JsonValue) (*Schema, error) {
+	schemaTree := schema.tree()
+	compiler := &schemaCompiler{
+		root:  schemaTree,
+		nodes: map[string]*schemaNode{},
+	}
+	root, err := compiler.compile(schemaTree, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolving a ref can compile new subschemas with refs of their own, so loop until done
+	for i := 0; i < len(compiler.refs); i++ {
+		if err := compiler.resolveRef(compiler.refs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Refs are applied to the same value, so a loop of them would never end
+	for _, node := range compiler.refs {
+		seen := map[*schemaNode]bool{}
+		for next := node; next != nil; next = next.refNode {
+			if seen[next] {
+				msg := fmt.Sprintf(`Circular $ref at "%s"`, node.path)
+				return nil, errors.New(msg)
+			}
+			seen[next] = true
+		}
+	}
+
+	return &Schema{root: root}, nil
+}
+
+// Returns every violation of the schema in the given value, or none if it's valid.
+func (s *Schema) Validate(value *JsonValue) []*SchemaError {
+	var schemaErrors []*SchemaError
+	s.root.validate(value.tree(), "", &schemaErrors)
+	return schemaErrors
+}
+
+func schemaKeywordError(path string, keyword string, expected string) error {
+	msg := fmt.Sprintf(`Schema keyword "%s" at "%s" must be %s`, keyword, path, expected)
+	return errors.New(msg)
+}
+
+// Compiles the subschema val, found at path in the schema.
+func (c *schemaCompiler) compile(val any, path string) (*schemaNode, error) {
+	if node, ok := c.nodes[path]; ok {
+		return node, nil
+	}
+
+	node := &schemaNode{path: path}
+	c.nodes[path] = node
+
+	if boolVal, ok := val.(bool); ok {
+		node.isBool = true
+		node.boolValue = boolVal
+		return node, nil
+	}
+
+	keywords, ok := val.(map[string]any)
+	if !ok {
+		msg := fmt.Sprintf(`Schema at "%s" must be an object or boolean`, path)
+		return nil, errors.New(msg)
+	}
+
+	if typeVal, ok := keywords["type"]; ok {
+		types, err := compileSchemaTypes(typeVal, path)
+		if err != nil {
+			return nil, err
+		}
+		node.types = types
+	}
+
+	if propertiesVal, ok := keywords["properties"]; ok {
+		properties, ok := propertiesVal.(map[string]any)
+		if !ok {
+			return nil, schemaKeywordError(path, "properties", "an object")
+		}
+		node.properties = make(map[string]*schemaNode, len(properties))
+		for key, propertyVal := range properties {
+			propertyPath := appendPointerToken(appendPointerToken(path, "properties"), key)
+			propertyNode, err := c.compile(propertyVal, propertyPath)
+			if err != nil {
+				return nil, err
+			}
+			node.properties[key] = propertyNode
+		}
+	}
+
+	if requiredVal, ok := keywords["required"]; ok {
+		required, ok := requiredVal.([]any)
+		if !ok {
+			return nil, schemaKeywordError(path, "required", "an array of strings")
+		}
+		for _, keyVal := range required {
+			key, ok := keyVal.(string)
+			if !ok {
+				return nil, schemaKeywordError(path, "required", "an array of strings")
+			}
+			node.required = append(node.required, key)
+		}
+	}
+
+	if additionalVal, ok := keywords["additionalProperties"]; ok {
+		additionalNode, err := c.compile(additionalVal, appendPointerToken(path, "additionalProperties"))
+		if err != nil {
+			return nil, err
+		}
+		node.additionalProperties = additionalNode
+	}
+
+	if itemsVal, ok := keywords["items"]; ok {
+		itemsNode, err := c.compile(itemsVal, appendPointerToken(path, "items"))
+		if err != nil {
+			return nil, err
+		}
+		node.items = itemsNode
+	}
+
+	if enumVal, ok := keywords["enum"]; ok {
+		enum, ok := enumVal.([]any)
+		if !ok {
+			return nil, schemaKeywordError(path, "enum", "an array")
+		}
+		node.enum = enum
+		node.hasEnum = true
+	}
+
+	if minimumVal, ok := keywords["minimum"]; ok {
+		minimum, ok := jsonNumberValue(minimumVal)
+		if !ok {
+			return nil, schemaKeywordError(path, "minimum", "a number")
+		}
+		node.minimum = &minimum
+	}
+
+	if maximumVal, ok := keywords["maximum"]; ok {
+		maximum, ok := jsonNumberValue(maximumVal)
+		if !ok {
+			return nil, schemaKeywordError(path, "maximum", "a number")
+		}
+		node.maximum = &maximum
+	}
+
+	if patternVal, ok := keywords["pattern"]; ok {
+		pattern, ok := patternVal.(string)
+		if !ok {
+			return nil, schemaKeywordError(path, "pattern", "a string")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			msg := fmt.Sprintf(`Invalid pattern at "%s": %s`, path, err)
+			return nil, errors.New(msg)
+		}
+		node.pattern = re
+	}
+
+	if refVal, ok := keywords["$ref"]; ok {
+		ref, ok := refVal.(string)
+		if !ok {
+			return nil, schemaKeywordError(path, "$ref", "a string")
+		}
+		node.ref = ref
+		c.refs = append(c.refs, node)
+	}
+
+	return node, nil
+}
+
+// Returns the type names from a "type" keyword, which is 1 name or a list of them.
+func compileSchemaTypes(typeVal any, path string) ([]string, error) {
+	var types []string
+	switch typedVal := typeVal.(type) {
+	case string:
+		types = []string{typedVal}
+	case []any:
+		for _, item := range typedVal {
+			typeName, ok := item.(string)
+			if !ok {
+				return nil, schemaKeywordError(path, "type", "a string or array of strings")
+			}
+			types = append(types, typeName)
+		}
+	default:
+		return nil, schemaKeywordError(path, "type", "a string or array of strings")
+	}
+
+	for _, typeName := range types {
+		if !slices.Contains(SCHEMA_TYPES, typeName) {
+			msg := fmt.Sprintf(`Unknown type "%s" at "%s"`, typeName, path)
+			return nil, errors.New(msg)
+		}
+	}
+	return types, nil
+}
+
+// Points node at the subschema its $ref names. Only refs within the same schema are supported.
+func (c *schemaCompiler) resolveRef(node *schemaNode) error {
+	if !strings.HasPrefix(node.ref, "#") {
+		msg := fmt.Sprintf(`Unsupported $ref "%s" at "%s", only refs within the schema (starting with "#") are supported`, node.ref, node.path)
+		return errors.New(msg)
+	}
+
+	// The fragment is URI encoded, so may have escapes like "%25"
+	pointer, err := url.PathUnescape(node.ref[1:])
+	if err != nil {
+		msg := fmt.Sprintf(`Invalid $ref "%s" at "%s"`, node.ref, node.path)
+		return errors.New(msg)
+	}
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		msg := fmt.Sprintf(`Invalid $ref "%s" at "%s": %s`, node.ref, node.path, err)
+		return errors.New(msg)
+	}
+	target, ok := lookupPointer(c.root, tokens)
+	if !ok {
+		msg := fmt.Sprintf(`$ref "%s" at "%s" not found`, node.ref, node.path)
+		return errors.New(msg)
+	}
+
+	// Recompose the pointer so differently escaped refs to the same place share a node
+	targetPath := ""
+	for _, token := range tokens {
+		targetPath = appendPointerToken(targetPath, token)
+	}
+	node.refNode, err = c.compile(target, targetPath)
+	return err
+}
+
+// Checks val, found at path in the document, adding any violations to schemaErrors.
+func (n *schemaNode) validate(val any, path string, schemaErrors *[]*SchemaError) {
+	addError := func(keyword string, errorPath string, msg string) {
+		*schemaErrors = append(*schemaErrors, &SchemaError{
+			Path:       errorPath,
+			SchemaPath: appendPointerToken(n.path, keyword),
+			Message:    msg,
+		})
+	}
+
+	if n.isBool {
+		if !n.boolValue {
+			*schemaErrors = append(*schemaErrors, &SchemaError{
+				Path:       path,
+				SchemaPath: n.path,
+				Message:    "Value not allowed by false schema",
+			})
+		}
+		return
+	}
+
+	if n.refNode != nil {
+		n.refNode.validate(val, path, schemaErrors)
+	}
+
+	if n.types != nil && !slices.ContainsFunc(n.types, func(typeName string) bool {
+		return schemaTypeMatches(typeName, val)
+	}) {
+		msg := fmt.Sprintf(`Expected type "%s", found "%s"`, strings.Join(n.types, `" or "`), jsonTypeName(val))
+		addError("type", path, msg)
+	}
+
+	if n.hasEnum && !slices.ContainsFunc(n.enum, func(enumVal any) bool {
+		return jsonValuesEqual(val, enumVal)
+	}) {
+		addError("enum", path, "Value not in enum")
+	}
+
+	if number, ok := jsonNumberValue(val); ok {
+		if n.minimum != nil && number < *n.minimum {
+			addError("minimum", path, fmt.Sprintf("Value %v is less than minimum %v", number, *n.minimum))
+		}
+		if n.maximum != nil && number > *n.maximum {
+			addError("maximum", path, fmt.Sprintf("Value %v is greater than maximum %v", number, *n.maximum))
+		}
+	}
+
+	switch typedVal := val.(type) {
+	case string:
+		if n.pattern != nil && !n.pattern.MatchString(typedVal) {
+			addError("pattern", path, fmt.Sprintf(`String "%s" doesn't match pattern "%s"`, typedVal, n.pattern))
+		}
+
+	case map[string]any:
+		for _, key := range n.required {
+			if _, ok := typedVal[key]; !ok {
+				addError("required", path, fmt.Sprintf(`Missing required property "%s"`, key))
+			}
+		}
+
+		// Sorted so errors come out in the same order every time
+		keys := make([]string, 0, len(typedVal))
+		for key := range typedVal {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			keyPath := appendPointerToken(path, key)
+			if propertyNode, ok := n.properties[key]; ok {
+				propertyNode.validate(typedVal[key], keyPath, schemaErrors)
+			} else if n.additionalProperties != nil {
+				n.additionalProperties.validate(typedVal[key], keyPath, schemaErrors)
+			}
+		}
+
+	case []any:
+		if n.items != nil {
+			for i, item := range typedVal {
+				n.items.validate(item, appendPointerIndex(path, i), schemaErrors)
+			}
+		}
+	}
+}
+
+// Returns true if val is of the given schema type.
+func schemaTypeMatches(typeName string, val any) bool {
+	switch typeName {
+	case "integer":
+		return isIntegerValue(val)
+	case "number":
+		_, ok := jsonNumberValue(val)
+		return ok
+	}
+	return jsonTypeName(val) == typeName
+}
+
+// Returns true if val is a number with no fraction, like 1 or 1.0.
+func isIntegerValue(val any) bool {
+	if _, ok := val.(int); ok {
+		return true
+	}
+	floatVal, ok := jsonNumberValue(val)
+	return ok && !math.IsInf(floatVal, 0) && floatVal == math.Trunc(floatVal)
+}
@@ -0,0 +1,140 @@
+package jsonParser
+
+/*
+	Tests JSON Schema compiling & validation.
+*/
+
+import (
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func compileSchemaWithStr(t *testing.T, schemaStr string) *Schema {
+	schemaJson, err := ParseJson(schemaStr)
+	assert.Nil(t, err, "Expected schema JSON to parse")
+	schema, err := CompileSchema(schemaJson)
+	assert.Nil(t, err, "Expected schema to compile")
+	return schema
+}
+
+func validateWithStr(t *testing.T, schema *Schema, valueStr string) []*SchemaError {
+	value, err := ParseJson(valueStr)
+	assert.Nil(t, err, "Expected value JSON to parse")
+	return schema.Validate(value)
+}
+
+const PAIRS_TEST_SCHEMA = `{
+	"type": "object",
+	"required": ["pairs"],
+	"properties": {
+		"pairs": {"type": "array", "items": {"$ref": "#/$defs/pair"}}
+	},
+	"$defs": {
+		"pair": {
+			"type": "object",
+			"required": ["x0", "y0"],
+			"additionalProperties": false,
+			"properties": {
+				"x0": {"type": "number", "minimum": -180, "maximum": 180},
+				"y0": {"type": "number", "minimum": -90, "maximum": 90}
+			}
+		}
+	}
+}`
+
+func TestSchemaValid(t *testing.T) {
+	schema := compileSchemaWithStr(t, PAIRS_TEST_SCHEMA)
+	schemaErrors := validateWithStr(t, schema, `{"pairs": [{"x0": 1.5, "y0": 2}, {"x0": -180, "y0": 90}]}`)
+	assert.Equal(t, len(schemaErrors), 0, "Expected valid document")
+}
+
+func TestSchemaAllViolations(t *testing.T) {
+	schema := compileSchemaWithStr(t, PAIRS_TEST_SCHEMA)
+	schemaErrors := validateWithStr(t, schema, `{"pairs": [{"x0": "a", "y0": 2}, {"x0": 200}, {"x0": 1, "y0": 2, "z": 3}]}`)
+
+	expected := []SchemaError{
+		{Path: "/pairs/0/x0", SchemaPath: "/$defs/pair/properties/x0/type", Message: `Expected type "number", found "string"`},
+		{Path: "/pairs/1", SchemaPath: "/$defs/pair/required", Message: `Missing required property "y0"`},
+		{Path: "/pairs/1/x0", SchemaPath: "/$defs/pair/properties/x0/maximum", Message: "Value 200 is greater than maximum 180"},
+		{Path: "/pairs/2/z", SchemaPath: "/$defs/pair/additionalProperties", Message: "Value not allowed by false schema"},
+	}
+	assert.Equal(t, len(schemaErrors), len(expected), "Unexpected number of violations")
+	for i := range expected {
+		assert.Equal(t, *schemaErrors[i], expected[i], "Unexpected violation")
+	}
+	assert.Equal(t, schemaErrors[0].Error(), `Expected type "number", found "string" at "/pairs/0/x0"`, "Unexpected error message")
+
+	schemaErrors = validateWithStr(t, schema, `[]`)
+	assert.Equal(t, len(schemaErrors), 1, "Expected 1 violation for root")
+	assert.Equal(t, schemaErrors[0].Path, "", "Expected root path")
+}
+
+func TestSchemaKeywords(t *testing.T) {
+	tests := []struct {
+		schema string
+		value  string
+		valid  bool
+	}{
+		{`{"type": "integer"}`, `1`, true},
+		{`{"type": "integer"}`, `1.0`, true},
+		{`{"type": "integer"}`, `1.5`, false},
+		{`{"type": ["string", "boolean"]}`, `true`, true},
+		{`{"type": ["string", "boolean"]}`, `1`, false},
+		{`{"enum": ["a", 1, [1, 2]]}`, `1.0`, true},
+		{`{"enum": ["a", 1, [1, 2]]}`, `[1, 2]`, true},
+		{`{"enum": ["a", 1, [1, 2]]}`, `"b"`, false},
+		{`{"pattern": "^[a-z]+$"}`, `"abc"`, true},
+		{`{"pattern": "^[a-z]+$"}`, `"ab1"`, false},
+		{`{"pattern": "b"}`, `"abc"`, true},
+		{`{"pattern": "^[a-z]+$"}`, `1`, true},
+		{`{"minimum": 1}`, `"0"`, true},
+		{`{"items": {"type": "string"}}`, `["a", "b"]`, true},
+		{`{"items": {"type": "string"}}`, `["a", 1]`, false},
+		{`{"additionalProperties": {"type": "string"}, "properties": {"a": {"type": "integer"}}}`, `{"a": 1, "b": "c"}`, true},
+		{`{"additionalProperties": {"type": "string"}, "properties": {"a": {"type": "integer"}}}`, `{"a": 1, "b": 2}`, false},
+		{`true`, `{"a": 1}`, true},
+		{`false`, `{"a": 1}`, false},
+		{`{"$defs": {"a/b": {"type": "string"}}, "$ref": "#/$defs/a~1b"}`, `"x"`, true},
+		{`{"$defs": {"a/b": {"type": "string"}}, "$ref": "#/$defs/a~1b"}`, `1`, false},
+	}
+
+	for _, test := range tests {
+		schema := compileSchemaWithStr(t, test.schema)
+		schemaErrors := validateWithStr(t, schema, test.value)
+		assert.Equal(t, len(schemaErrors) == 0, test.valid, "Unexpected result for "+test.schema+" with "+test.value)
+	}
+}
+
+func TestSchemaRecursiveRef(t *testing.T) {
+	schema := compileSchemaWithStr(t, `{
+		"type": "object",
+		"properties": {"value": {"type": "integer"}, "next": {"$ref": "#"}}
+	}`)
+	schemaErrors := validateWithStr(t, schema, `{"value": 1, "next": {"value": 2, "next": {"value": "3"}}}`)
+	assert.Equal(t, len(schemaErrors), 1, "Expected 1 violation")
+	assert.Equal(t, schemaErrors[0].Path, "/next/next/value", "Unexpected violation path")
+}
+
+func TestSchemaCompileErrors(t *testing.T) {
+	tests := []string{
+		`1`,
+		`{"type": "float"}`,
+		`{"type": 1}`,
+		`{"properties": []}`,
+		`{"required": [1]}`,
+		`{"minimum": "1"}`,
+		`{"pattern": "("}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "other.json#/a"}`,
+		`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`,
+		`{"items": {"properties": {"a": 1}}}`,
+	}
+
+	for _, test := range tests {
+		schemaJson, err := ParseJson(test)
+		assert.Nil(t, err, "Expected schema JSON to parse")
+		_, err = CompileSchema(schemaJson)
+		assert.NotNil(t, err, "Expected compile error for "+test)
+	}
+}
@@ -0,0 +1,245 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+	Serializer turns a JsonValue back into compact JSON text, with no whitespace.
+
+	```
+	data, err := jsonResult.Serialize()
+	```
+
+	- Object keys are written in sorted order, so output is stable for the same data.
+	- Floats are written in the shortest form that parses back to the same float64. A float that
+	  happens to be whole keeps a ".0" so it's parsed back as a float, not an int.
+	- Numbers (see number.go) are written as their original literal.
+	- NaN & Infinity have no JSON literal, so they're an error, as floats or as Numbers (which
+	  relaxed mode makes for them).
+	- Strings only escape what JSON requires: quotes, backslashes & control characters. Invalid
+	  UTF-8 (see InvalidUTF8PassThrough) is written as U+FFFD.
+*/
+
+const SERIALIZER_HEX_DIGITS = "0123456789abcdef"
+
+// Objects with up to this many members have their keys sorted on the stack.
+const SERIALIZER_STACK_KEYS = 16
+
+// Returns the value as compact JSON.
+func (j *JsonValue) Serialize() ([]byte, error) {
+	return j.AppendJson(nil)
+}
+
+// Appends the value as compact JSON to dst & returns the extended buffer. Reuse the buffer to
+// avoid allocating for every value.
+func (j *JsonValue) AppendJson(dst []byte) ([]byte, error) {
+	return appendTapeValue(dst, j.tape, j.index)
+}
+
+// Appends the value at index on the tape, the same way appendJsonValue() does for Go data.
+func appendTapeValue(dst []byte, t *tape, index int) ([]byte, error) {
+	var err error
+
+	n := t.nodes[index]
+	switch n.kind {
+	case tapeNull:
+		dst = append(dst, "null"...)
+	case tapeFalse, tapeTrue:
+		dst = strconv.AppendBool(dst, n.kind == tapeTrue)
+	case tapeInt:
+		dst = strconv.AppendInt(dst, int64(n.int()), 10)
+	case tapeFloat:
+		dst, err = appendJsonFloat(dst, n.float())
+	case tapeNumber:
+		dst, err = appendJsonNumber(dst, Number(t.str(n)))
+	case tapeString:
+		dst = appendJsonString(dst, t.str(n))
+	case tapeArray:
+		dst = append(dst, '[')
+		for item := index + 1; item < t.next(index); item = t.next(item) {
+			if item > index+1 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendTapeValue(dst, t, item); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, ']')
+	case tapeObject:
+		var keysBuf [SERIALIZER_STACK_KEYS]int
+		keys := t.sortedKeys(index, keysBuf[:0], strings.Compare)
+
+		dst = append(dst, '{')
+		for i, key := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendJsonString(dst, t.str(t.nodes[key]))
+			dst = append(dst, ':')
+			if dst, err = appendTapeValue(dst, t, key+1); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, '}')
+	case tapeInvalid:
+		return appendJsonValue(dst, t.invalid[n.payload])
+	}
+
+	return dst, err
+}
+
+func appendJsonValue(dst []byte, val any) ([]byte, error) {
+	var err error
+
+	switch typedVal := val.(type) {
+	case nil:
+		dst = append(dst, "null"...)
+	case bool:
+		dst = strconv.AppendBool(dst, typedVal)
+	case int:
+		dst = strconv.AppendInt(dst, int64(typedVal), 10)
+	case float64:
+		dst, err = appendJsonFloat(dst, typedVal)
+	case Number:
+		dst, err = appendJsonNumber(dst, typedVal)
+	case string:
+		dst = appendJsonString(dst, typedVal)
+	case []any:
+		dst = append(dst, '[')
+		for i, item := range typedVal {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendJsonValue(dst, item); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, ']')
+	case map[string]any:
+		keys := make([]string, 0, len(typedVal))
+		for key := range typedVal {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		dst = append(dst, '{')
+		for i, key := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendJsonString(dst, key)
+			dst = append(dst, ':')
+			if dst, err = appendJsonValue(dst, typedVal[key]); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, '}')
+	default:
+		msg := fmt.Sprintf("Cannot serialize value of type %T", val)
+		err = errors.New(msg)
+	}
+
+	return dst, err
+}
+
+// Appends a float in the shortest form that round trips. Same format choice as encoding/json:
+// plain decimal for everyday magnitudes, exponent for very big or very small ones.
+// Appends a Number's literal, which must be strict JSON.
+func appendJsonNumber(dst []byte, n Number) ([]byte, error) {
+	if !isValidJsonNumber(string(n)) {
+		msg := fmt.Sprintf("Cannot serialize number %s, JSON has no literal for it", n)
+		return dst, errors.New(msg)
+	}
+	return append(dst, n...), nil
+}
+
+func appendJsonFloat(dst []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		msg := fmt.Sprintf("Cannot serialize float %v, JSON has no literal for it", f)
+		return dst, errors.New(msg)
+	}
+
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		n := len(dst)
+		if n-start >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+		return dst, nil
+	}
+
+	// Keep it a float when parsed back
+	for _, c := range dst[start:] {
+		if c == '.' {
+			return dst, nil
+		}
+	}
+	return append(dst, ".0"...), nil
+}
+
+// Appends s as a quoted JSON string.
+func appendJsonString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+
+	// Copy runs of plain bytes in 1 go
+	plainStart := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c < utf8.RuneSelf && c != '"' && c != '\\' {
+			i += 1
+			continue
+		}
+
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r != utf8.RuneError || size != 1 {
+				i += size
+				continue
+			}
+		}
+
+		dst = append(dst, s[plainStart:i]...)
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\b':
+			dst = append(dst, '\\', 'b')
+		case '\f':
+			dst = append(dst, '\\', 'f')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			if c < 0x20 {
+				dst = append(dst, '\\', 'u', '0', '0', SERIALIZER_HEX_DIGITS[c>>4], SERIALIZER_HEX_DIGITS[c&0xf])
+			} else {
+				// Invalid UTF-8 byte
+				dst = append(dst, "�"...)
+			}
+		}
+		i += 1
+		plainStart = i
+	}
+
+	dst = append(dst, s[plainStart:]...)
+	return append(dst, '"')
+}
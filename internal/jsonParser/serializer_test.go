@@ -0,0 +1,69 @@
+package jsonParser
+
+/*
+	Tests serializing JsonValues back to JSON.
+*/
+
+import (
+	"math"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func serializeToStr(t *testing.T, value *JsonValue) string {
+	data, err := value.Serialize()
+	assert.Nil(t, err, "Expected value to serialize")
+	return string(data)
+}
+
+func TestSerializerValues(t *testing.T) {
+	cases := map[string]any{
+		`null`:                           nil,
+		`true`:                           true,
+		`-12`:                            -12,
+		`1.5`:                            1.5,
+		`2.0`:                            2.0,
+		`-0.0`:                           math.Copysign(0, -1),
+		`1e+21`:                          1e21,
+		`1e-7`:                           1e-7,
+		`0.000001`:                       1e-6,
+		`12345678.9`:                     12345678.9,
+		`123456789012345678901234567890`: Number("123456789012345678901234567890"),
+		`"a\"b\\c\n\u0001é😀"`:            "a\"b\\c\n\x01é😀",
+		`"bad�byte"`:                     "bad\xffbyte",
+		`[]`:                             []any{},
+		`[1,"a",[true]]`:                 []any{1, "a", []any{true}},
+		`{}`:                             map[string]any{},
+		`{"a":1,"b":{"c":null},"d":[]}`:  map[string]any{"d": []any{}, "b": map[string]any{"c": nil}, "a": 1},
+	}
+	for expected, data := range cases {
+		assert.Equal(t, serializeToStr(t, NewJsonValue(data)), expected, "Unexpected JSON for "+expected)
+	}
+
+	// No literal for these
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err := NewJsonValue([]any{f}).Serialize()
+		assert.NotNil(t, err, "Expected error serializing NaN or Infinity, did not error")
+	}
+	_, err := NewJsonValue(map[string]any{"a": struct{}{}}).Serialize()
+	assert.NotNil(t, err, "Expected error serializing unknown type, did not error")
+}
+
+func TestSerializerRoundTrip(t *testing.T) {
+	input := `{"a":[1,2.5,-3e-9,"x\ty"],"b":{"c":false,"d":""},"e":0.1}`
+	value, err := runParserWithStr(input)
+	assert.Nil(t, err, "Expected input to parse")
+	assert.Equal(t, serializeToStr(t, value), input, "Expected round trip to match input")
+
+	// Floats parse back to the same bits
+	for _, f := range []float64{0.1, 1.0 / 3, 102.1633205722960440, -24.9977499718717624, 5e-324, math.MaxFloat64} {
+		data, err := NewJsonValue(map[string]any{"f": f}).Serialize()
+		assert.Nil(t, err, "Expected float to serialize")
+		value, err := runParserWithStr(string(data))
+		assert.Nil(t, err, "Expected serialized float to parse")
+		parsed, err := value.GetFloat("f")
+		assert.Nil(t, err, "Expected serialized float to parse as a float")
+		assert.Equal(t, parsed, f, "Expected float to round trip")
+	}
+}
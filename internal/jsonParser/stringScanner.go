@@ -0,0 +1,146 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+/*
+	Helpers for scanning the inside of JSON strings.
+
+	The string scan is the lexer's hottest loop, so skipPlainStringBytes() checks 8 bytes at a
+	time using SWAR ("SIMD within a register") bit tricks on a uint64, rather than 1 byte per
+	iteration. A byte is "plain" if it's printable ASCII & not a quote or backslash, which is
+	nearly every byte in most documents. Anything else drops back to the lexer to handle.
+
+	Ref: https://graphics.stanford.edu/~seander/bithacks.html#ZeroInWord
+*/
+
+const SWAR_ONES = 0x0101010101010101
+const SWAR_HIGH_BITS = 0x8080808080808080
+
+// Returns the index of the first byte at or after i that's not plain string content, meaning a
+// quote, backslash, control character or non-ASCII byte. Returns len(s) if there isn't one.
+//
+// quote is the string's quote character. It's always " in strict mode, but relaxed mode also
+// allows ', in which case " is returned too & the caller treats it as plain.
+func skipPlainStringBytes(s string, i int, quote byte) int {
+	quoteMask := SWAR_ONES * uint64(quote)
+
+	for i+8 <= len(s) {
+		// Little endian load. The compiler combines this into a single 8 byte load.
+		w := uint64(s[i]) | uint64(s[i+1])<<8 | uint64(s[i+2])<<16 | uint64(s[i+3])<<24 |
+			uint64(s[i+4])<<32 | uint64(s[i+5])<<40 | uint64(s[i+6])<<48 | uint64(s[i+7])<<56
+
+		// Each of these sets a byte's high bit if the byte is special. They can give false
+		// positives in bytes after a real match, but never when there's no match, which is all
+		// we need to know before falling back to the byte loop.
+		nonASCII := w & SWAR_HIGH_BITS
+		control := (w - SWAR_ONES*0x20) & ^w & SWAR_HIGH_BITS
+		quoteFound := swarHasZeroByte(w^quoteMask) | swarHasZeroByte(w^(SWAR_ONES*'"'))
+		backslash := swarHasZeroByte(w ^ (SWAR_ONES * '\\'))
+		if nonASCII|control|quoteFound|backslash != 0 {
+			break
+		}
+		i += 8
+	}
+
+	for i < len(s) {
+		c := s[i]
+		if c < 0x20 || c >= 0x80 || c == '"' || c == '\\' || c == quote {
+			return i
+		}
+		i += 1
+	}
+	return i
+}
+
+// Returns non-zero if any byte of w is zero.
+func swarHasZeroByte(w uint64) uint64 {
+	return (w - SWAR_ONES) & ^w & SWAR_HIGH_BITS
+}
+
+// Decodes the escape sequence at the start of s (which starts with the backslash), writes the
+// result to sb, & returns the length of the escape sequence.
+//
+// Lone UTF-16 surrogates (like "\ud800" with no low surrogate after it) are valid JSON but not
+// valid Unicode, so they're written as U+FFFD, same as encoding/json.
+//
+// Relaxed mode also allows the extra JSON5 escapes.
+func writeEscape(sb *strings.Builder, s string, relaxed bool) (int, error) {
+	if len(s) < 2 {
+		return 0, errors.New("Unfinished escape sequence in string")
+	}
+
+	if relaxed {
+		if escapeLen, ok := writeRelaxedEscape(sb, s); ok {
+			return escapeLen, nil
+		}
+	}
+
+	switch s[1] {
+	case '"', '\\', '/':
+		sb.WriteByte(s[1])
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case 'u':
+		r, ok := readHex4(s[2:])
+		if !ok {
+			return 0, errors.New(`Invalid \u escape in string, expected 4 hex digits`)
+		}
+
+		// Combine surrogate pairs into 1 rune
+		if utf16.IsSurrogate(r) {
+			if len(s) >= 12 && s[6] == '\\' && s[7] == 'u' {
+				if r2, ok := readHex4(s[8:]); ok {
+					if combined := utf16.DecodeRune(r, r2); combined != utf8.RuneError {
+						sb.WriteRune(combined)
+						return 12, nil
+					}
+				}
+			}
+			r = utf8.RuneError
+		}
+		sb.WriteRune(r)
+		return 6, nil
+	default:
+		msg := fmt.Sprintf(`Invalid escape "\%c" in string`, s[1])
+		return 0, errors.New(msg)
+	}
+
+	return 2, nil
+}
+
+// Reads 4 hex digits from the start of s.
+func readHex4(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+
+	var r rune
+	for i := 0; i < 4; i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			r = r*16 + rune(c-'0')
+		case c >= 'a' && c <= 'f':
+			r = r*16 + rune(c-'a'+10)
+		case c >= 'A' && c <= 'F':
+			r = r*16 + rune(c-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+	return r, true
+}
@@ -0,0 +1,143 @@
+package jsonParser
+
+/*
+	Tests string lexing: escapes, control characters & UTF-8 handling.
+*/
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func lexStringWithOptions(s string, options ParseOptions) (string, error) {
+	lexer := newLexer(s)
+	lexer.options = options
+	tokens, err := lexer.lex()
+	if err != nil || len(tokens) == 0 {
+		return "", err
+	}
+	return tokens[0].Value, nil
+}
+
+func TestStringEscapes(t *testing.T) {
+	cases := map[string]string{
+		`"plain"`:                 "plain",
+		`""`:                      "",
+		`"a\"b"`:                  `a"b`,
+		`"a\\b"`:                  `a\b`,
+		`"a\/b"`:                  "a/b",
+		`"\b\f\n\r\t"`:            "\b\f\n\r\t",
+		`"Aé€"`:                   "Aé€",
+		`"😀"`:                     "😀",
+		`"lone \ud800 surrogate"`: "lone � surrogate",
+		`"\udc00\ud800"`:          "��",
+		`"long string without anything special in it at all"`: "long string without anything special in it at all",
+		`"héllo wörld, valid UTF-8 stays as a slice"`:         "héllo wörld, valid UTF-8 stays as a slice",
+	}
+
+	for input, expected := range cases {
+		value, err := lexStringWithOptions(input, ParseOptions{})
+		assert.Nil(t, err, "Expected "+input+" to lex")
+		assert.Equal(t, value, expected, "Unexpected value for "+input)
+	}
+
+	// Escaped quote doesn't end the string
+	result, err := runLexerWithStr(`{"a\"": "b"}`)
+	assert.Nil(t, err, "Expected escaped quote to lex")
+	assert.Equal(t, len(result), 5, "Expected to lex 5 tokens")
+}
+
+func TestStringInvalidEscapes(t *testing.T) {
+	invalid := []string{`"\x"`, `"\u12"`, `"\u12G4"`, `"\`, `"abc\`, `"\'"`}
+	for _, s := range invalid {
+		_, err := lexStringWithOptions(s, ParseOptions{})
+		assert.NotNil(t, err, "Expected error for "+s+", did not error")
+	}
+}
+
+func TestStringControlCharacters(t *testing.T) {
+	_, err := lexStringWithOptions("\"a\nb\"", ParseOptions{})
+	assert.NotNil(t, err, "Expected error on unescaped newline, did not error")
+
+	_, err = lexStringWithOptions("\"abcdefghijkl\x00\"", ParseOptions{})
+	assert.NotNil(t, err, "Expected error on unescaped null, did not error")
+	assert.Equal(t, getParseError(err).Offset, 13, "Expected error offset at control character")
+
+	// DEL isn't a control character in JSON
+	value, err := lexStringWithOptions("\"a\x7fb\"", ParseOptions{})
+	assert.Nil(t, err, "Expected DEL to lex")
+	assert.Equal(t, value, "a\x7fb", "Expected DEL to be kept")
+}
+
+func TestStringInvalidUTF8(t *testing.T) {
+	// Lone continuation byte, truncated 2 byte sequence, overlong "/", UTF-8 encoded surrogate
+	invalid := []string{"\x80", "\xc3", "\xc0\xaf", "\xed\xa0\x80"}
+	for _, bad := range invalid {
+		input := `{"key": "abcdefghij` + bad + `xyz"}`
+
+		// Reject is the default
+		_, err := ParseJson(input)
+		assert.Equal(t, errors.Is(err, ErrInvalidUTF8), true, "Expected invalid UTF-8 error")
+		assert.Equal(t, getParseError(err).Offset, 19, "Expected error offset at first bad byte")
+
+		// Replace swaps each bad byte for U+FFFD
+		options := DefaultParseOptions()
+		options.InvalidUTF8 = InvalidUTF8Replace
+		result, err := ParseJsonWithOptions(input, options)
+		assert.Nil(t, err, "Expected replace policy to parse")
+		value, _ := result.GetString("key")
+		expected := "abcdefghij"
+		for range bad {
+			expected += "�"
+		}
+		assert.Equal(t, value, expected+"xyz", "Expected bad bytes to be replaced")
+
+		// Pass through keeps the bytes
+		options.InvalidUTF8 = InvalidUTF8PassThrough
+		result, err = ParseJsonWithOptions(input, options)
+		assert.Nil(t, err, "Expected pass through policy to parse")
+		value, _ = result.GetString("key")
+		assert.Equal(t, value, "abcdefghij"+bad+"xyz", "Expected bad bytes to be kept")
+	}
+
+	// Token positions stay right after multi-byte characters
+	result, err := runLexerWithStr(`{"é": 1}`)
+	assert.Nil(t, err, "Expected multi-byte key to lex")
+	assert.Equal(t, result[3].Pos, 7, "Expected number token after 2 byte character")
+}
+
+func TestStringSkipPlainBytes(t *testing.T) {
+	// Compare the SWAR scan against a simple byte loop, with special bytes at every position
+	naive := func(s string, i int) int {
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c < 0x20 || c >= 0x80 || c == '"' || c == '\\' {
+				return i
+			}
+		}
+		return i
+	}
+
+	special := []byte{'"', '\\', 0x00, 0x1f, 0x80, 0xff}
+	plain := []byte("abc XYZ 019 !#$%&'()*+,-./:;<=>?@[]^_`{|}~\x7f")
+	r := rand.New(rand.NewPCG(7, 8))
+	for n := 0; n < 40; n++ {
+		for trial := 0; trial < 50; trial++ {
+			buf := make([]byte, n)
+			for i := range buf {
+				buf[i] = plain[r.IntN(len(plain))]
+			}
+			if n > 0 && r.IntN(3) > 0 {
+				buf[r.IntN(n)] = special[r.IntN(len(special))]
+			}
+
+			s := string(buf)
+			for start := 0; start <= n; start++ {
+				assert.Equal(t, skipPlainStringBytes(s, start, '"'), naive(s, start), "SWAR scan disagrees with byte loop")
+			}
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package jsonParser
+
+import (
+	"math"
+	"slices"
+	"strconv"
+)
+
+/*
+	Tape is how a JsonValue stores its data: a flat slice of nodes, 1 per value, in document
+	order. Each node is a tagged union of a kind byte, a payload & a subtree size:
+	- Null, false & true have no payload.
+	- Ints & floats keep their bits in the payload, so numbers are never boxed in an interface.
+	- Strings, object keys & Numbers keep an index into the tape's string table.
+	- Objects & arrays keep their number of members or items. Their children follow them on the
+	  tape, & each child's size says where the next 1 starts, so containers need no pointers or
+	  maps. An object member is a key node followed by the value's nodes.
+
+	```
+	{"a": [1, 2.5], "b": "x"}
+
+	index  kind    size  payload
+	0      object  7     2 members
+	1      key     1     0 ("a")
+	2      array   3     2 items
+	3      int     1     1
+	4      float   1     2.5
+	5      key     1     1 ("b")
+	6      string  1     2 ("x")
+	```
+
+	A parse fills 1 growing slice of nodes & 1 of strings, rather than allocating a map, slice or
+	interface box per value. A JsonValue is the tape & its node index, so getting a child is index
+	math.
+
+	Sizes are relative, so a subtree can be moved or a tape appended to another without fixing up
+	indexes. Parallel parsing relies on this to join chunks (see parallel.go).
+
+	Trade offs
+	- Object lookups scan the keys, which beats hashing for the small objects JSON usually holds,
+	  but is slow for objects with thousands of members.
+	- Index(i) steps over the items before i, so iterate arrays with Items() or GetArray().
+	- Duplicate keys are removed when an object is closed, keeping the last value like a map would.
+
+	Go data from NewJsonValue() is copied onto a tape, with anything that isn't JSON kept as is in
+	an invalid node. Serialize, Equal, Hash & Canonicalize walk the tape. Features still written
+	against Go values (patch, diff & schema validation) get them from tree(), which builds the
+	map[string]any & []any values back out of the tape, boxing every number again.
+*/
+
+type tapeKind uint8
+
+const (
+	tapeNull tapeKind = iota
+	tapeFalse
+	tapeTrue
+	tapeInt
+	tapeFloat
+	tapeNumber
+	tapeString
+	tapeKey
+	tapeObject
+	tapeArray
+	tapeInvalid
+)
+
+// Max members of an object checked for duplicate keys by comparing every pair. Bigger objects
+// use a map.
+const TAPE_DUPLICATE_SCAN_MAX = 16
+
+type tapeNode struct {
+	kind    tapeKind
+	size    uint32 // Nodes in this subtree, including this 1
+	payload uint64 // Int or float bits, index into strings or invalid, or number of children
+}
+
+type tape struct {
+	nodes   []tapeNode
+	strings []string // Strings, keys & Number literals
+	invalid []any    // Data that isn't JSON, from NewJsonValue()
+}
+
+func (n tapeNode) int() int {
+	return int(int64(n.payload))
+}
+
+func (n tapeNode) float() float64 {
+	return math.Float64frombits(n.payload)
+}
+
+func (t *tape) appendNode(kind tapeKind, payload uint64) {
+	t.nodes = append(t.nodes, tapeNode{kind: kind, size: 1, payload: payload})
+}
+
+func (t *tape) appendString(kind tapeKind, s string) {
+	t.appendNode(kind, uint64(len(t.strings)))
+	t.strings = append(t.strings, s)
+}
+
+func (t *tape) appendInt(i int) {
+	t.appendNode(tapeInt, uint64(int64(i)))
+}
+
+func (t *tape) appendFloat(f float64) {
+	t.appendNode(tapeFloat, math.Float64bits(f))
+}
+
+func (t *tape) appendBool(b bool) {
+	if b {
+		t.appendNode(tapeTrue, 0)
+	} else {
+		t.appendNode(tapeFalse, 0)
+	}
+}
+
+// Starts an object or array, returning its index. Its children are appended after it, then it
+// must be closed with closeContainer().
+func (t *tape) openContainer(kind tapeKind) int {
+	t.nodes = append(t.nodes, tapeNode{kind: kind})
+	return len(t.nodes) - 1
+}
+
+// Finishes the container at index, which must be the innermost open 1, once all its children
+// are on the tape.
+func (t *tape) closeContainer(index int) {
+	isObject := t.nodes[index].kind == tapeObject
+	if isObject {
+		t.removeDuplicateKeys(index)
+	}
+
+	numChildren := 0
+	for child := index + 1; child < len(t.nodes); child = t.next(child) {
+		numChildren += 1
+	}
+	if isObject {
+		numChildren /= 2 // Key & value nodes
+	}
+	t.nodes[index].size = uint32(len(t.nodes) - index)
+	t.nodes[index].payload = uint64(numChildren)
+}
+
+// Returns the index of the node after the subtree at index.
+func (t *tape) next(index int) int {
+	return index + int(t.nodes[index].size)
+}
+
+// Returns the string a string, key or Number node holds.
+func (t *tape) str(n tapeNode) string {
+	return t.strings[n.payload]
+}
+
+// Removes all but the last member with each key from the open object at index, whose members
+// are the rest of the tape. Duplicates are rare, so finding them is made cheap.
+func (t *tape) removeDuplicateKeys(index int) {
+	if !t.hasDuplicateKeys(index) {
+		return
+	}
+
+	lastMember := map[string]int{}
+	for key := index + 1; key < len(t.nodes); key = t.next(key + 1) {
+		lastMember[t.str(t.nodes[key])] = key
+	}
+	end := index + 1
+	for key := index + 1; key < len(t.nodes); {
+		memberEnd := t.next(key + 1)
+		if lastMember[t.str(t.nodes[key])] == key {
+			end += copy(t.nodes[end:], t.nodes[key:memberEnd])
+		}
+		key = memberEnd
+	}
+	t.nodes = t.nodes[:end]
+}
+
+func (t *tape) hasDuplicateKeys(index int) bool {
+	numMembers := 0
+	for key := index + 1; key < len(t.nodes); key = t.next(key + 1) {
+		numMembers += 1
+	}
+
+	if numMembers <= TAPE_DUPLICATE_SCAN_MAX {
+		for a := index + 1; a < len(t.nodes); a = t.next(a + 1) {
+			for b := t.next(a + 1); b < len(t.nodes); b = t.next(b + 1) {
+				if t.str(t.nodes[a]) == t.str(t.nodes[b]) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	seen := make(map[string]struct{}, numMembers)
+	for key := index + 1; key < len(t.nodes); key = t.next(key + 1) {
+		keyStr := t.str(t.nodes[key])
+		if _, ok := seen[keyStr]; ok {
+			return true
+		}
+		seen[keyStr] = struct{}{}
+	}
+	return false
+}
+
+// Returns the index of the value for key in the object at index, or -1 if there isn't 1.
+func (t *tape) objectLookup(index int, key string) int {
+	end := t.next(index)
+	for keyIndex := index + 1; keyIndex < end; keyIndex = t.next(keyIndex + 1) {
+		if t.str(t.nodes[keyIndex]) == key {
+			return keyIndex + 1
+		}
+	}
+	return -1
+}
+
+// Returns the index of item i of the array at index, or -1 if it's out of range.
+func (t *tape) arrayItem(index int, i int) int {
+	n := t.nodes[index]
+	if i < 0 || i >= int(n.payload) {
+		return -1
+	}
+	// All scalars, so items are 1 node each
+	if int(n.size) == int(n.payload)+1 {
+		return index + 1 + i
+	}
+	item := index + 1
+	for ; i > 0; i-- {
+		item = t.next(item)
+	}
+	return item
+}
+
+// Returns the index of the value the reference tokens name inside the value at index, or false
+// if there isn't 1. Like lookupPointer() for Go values.
+func (t *tape) lookupPointer(index int, tokens []string) (int, bool) {
+	for _, token := range tokens {
+		switch t.nodes[index].kind {
+		case tapeObject:
+			index = t.objectLookup(index, token)
+		case tapeArray:
+			i, ok := parsePointerIndex(token)
+			if !ok {
+				return 0, false
+			}
+			index = t.arrayItem(index, i)
+		default:
+			return 0, false
+		}
+		if index < 0 {
+			return 0, false
+		}
+	}
+	return index, true
+}
+
+// Returns the JSON text of a number node, or the literal of a string holding 1.
+func (t *tape) numberLiteral(n tapeNode) Number {
+	switch n.kind {
+	case tapeInt:
+		return Number(strconv.Itoa(n.int()))
+	case tapeFloat:
+		return Number(strconv.FormatFloat(n.float(), 'g', -1, 64))
+	}
+	return Number(t.str(n))
+}
+
+// Returns the value of a number node as a float64, or false if it's a Number too big for 1.
+func (t *tape) numberValue(n tapeNode) (float64, bool) {
+	return t.numberRef(n).float64()
+}
+
+// Appends the key node indexes of the object at index to keys, sorted by compare, & returns
+// them. Pass a stack buffer as keys to sort small objects without allocating.
+func (t *tape) sortedKeys(index int, keys []int, compare func(a, b string) int) []int {
+	for key := index + 1; key < t.next(index); key = t.next(key + 1) {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b int) int {
+		return compare(t.str(t.nodes[a]), t.str(t.nodes[b]))
+	})
+	return keys
+}
+
+// Appends Go data, as returned by tree(), to the tape.
+func (t *tape) appendTree(val any) {
+	switch typedVal := val.(type) {
+	case nil:
+		t.appendNode(tapeNull, 0)
+	case bool:
+		t.appendBool(typedVal)
+	case int:
+		t.appendInt(typedVal)
+	case float64:
+		t.appendFloat(typedVal)
+	case Number:
+		t.appendString(tapeNumber, string(typedVal))
+	case string:
+		t.appendString(tapeString, typedVal)
+	case map[string]any:
+		index := t.openContainer(tapeObject)
+		for key, member := range typedVal {
+			t.appendString(tapeKey, key)
+			t.appendTree(member)
+		}
+		t.closeContainer(index)
+	case []any:
+		index := t.openContainer(tapeArray)
+		for _, item := range typedVal {
+			t.appendTree(item)
+		}
+		t.closeContainer(index)
+	default:
+		t.appendNode(tapeInvalid, uint64(len(t.invalid)))
+		t.invalid = append(t.invalid, val)
+	}
+}
+
+// Returns the value at index as Go data: map[string]any, []any, string, int, float64, Number,
+// bool or nil.
+func (t *tape) tree(index int) any {
+	n := t.nodes[index]
+	switch n.kind {
+	case tapeFalse:
+		return false
+	case tapeTrue:
+		return true
+	case tapeInt:
+		return n.int()
+	case tapeFloat:
+		return n.float()
+	case tapeNumber:
+		return Number(t.str(n))
+	case tapeString:
+		return t.str(n)
+	case tapeObject:
+		object := make(map[string]any, n.payload)
+		end := t.next(index)
+		for key := index + 1; key < end; key = t.next(key + 1) {
+			object[t.str(t.nodes[key])] = t.tree(key + 1)
+		}
+		return object
+	case tapeArray:
+		array := make([]any, 0, n.payload)
+		for item := index + 1; item < t.next(index); item = t.next(item) {
+			array = append(array, t.tree(item))
+		}
+		return array
+	case tapeInvalid:
+		return t.invalid[n.payload]
+	}
+	return nil
+}
+
+// Returns a stand in for the value at index for error messages. They only quote scalars, so
+// containers are left empty rather than built just to be described.
+func (t *tape) errorValue(index int) any {
+	switch t.nodes[index].kind {
+	case tapeObject:
+		return map[string]any(nil)
+	case tapeArray:
+		return []any(nil)
+	}
+	return t.tree(index)
+}
+
+// Returns an error for the value at index not being the requested type, which matches
+// ErrTypeMismatch.
+func (t *tape) typeMismatchError(index int, typeName string) error {
+	return typeMismatchError(t.errorValue(index), typeName)
+}
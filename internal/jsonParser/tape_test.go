@@ -0,0 +1,209 @@
+package jsonParser
+
+/*
+	Tests the tape layout that JsonValues are stored in, & that reading it doesn't allocate.
+*/
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func TestTapeLayout(t *testing.T) {
+	value, err := runParserWithStr(`{"a": [1, 2.5], "b": "x"}`)
+	assert.Nil(t, err, "Expected JSON to parse")
+
+	expected := []tapeNode{
+		{kind: tapeObject, size: 7, payload: 2},
+		{kind: tapeKey, size: 1, payload: 0},
+		{kind: tapeArray, size: 3, payload: 2},
+		{kind: tapeInt, size: 1, payload: 1},
+		{kind: tapeFloat, size: 1, payload: 0x4004000000000000},
+		{kind: tapeKey, size: 1, payload: 1},
+		{kind: tapeString, size: 1, payload: 2},
+	}
+	assert.Equal(t, len(value.tape.nodes), len(expected), "Unexpected number of nodes")
+	for i, node := range expected {
+		assert.Equal(t, value.tape.nodes[i], node, fmt.Sprintf("Unexpected node %d", i))
+	}
+	assert.Equal(t, strings.Join(value.tape.strings, ","), "a,b,x", "Unexpected strings")
+
+	assert.Finished()
+}
+
+func TestTapeDuplicateKeys(t *testing.T) {
+	// Last value wins, like a map
+	value, err := runParserWithStr(`{"a": [1, 2], "b": 2, "a": {"c": 3}, "d": 4}`)
+	assert.Nil(t, err, "Expected duplicate keys to parse")
+	assert.Equal(t, value.Len(), 3, "Expected duplicates to be removed")
+	assert.Equal(t, strings.Join(value.Keys(), ","), "a,b,d", "Unexpected keys")
+	c, err := Get[int](value, "/a/c")
+	assert.Nil(t, err, "Expected last duplicate to be kept")
+	assert.Equal(t, c, 3, "Unexpected value for last duplicate")
+	d, _ := value.GetInt("d")
+	assert.Equal(t, d, 4, "Expected members after duplicates to be kept")
+	assert.Equal(t, int(value.tape.nodes[0].size), len(value.tape.nodes), "Expected removed nodes to be dropped")
+
+	// Big objects find duplicates with a map
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i := 0; i <= TAPE_DUPLICATE_SCAN_MAX; i++ {
+		fmt.Fprintf(&sb, `"k%d": %d, `, i, i)
+	}
+	sb.WriteString(`"k0": "last"}`)
+	value, err = runParserWithStr(sb.String())
+	assert.Nil(t, err, "Expected big object to parse")
+	assert.Equal(t, value.Len(), TAPE_DUPLICATE_SCAN_MAX+1, "Expected big object duplicates to be removed")
+	k0, _ := value.GetString("k0")
+	assert.Equal(t, k0, "last", "Expected last duplicate to be kept in big object")
+
+	assert.Finished()
+}
+
+func TestTapeNewJsonValue(t *testing.T) {
+	data := map[string]any{
+		"a": []any{1, 2.5, Number("1e400"), "x", true, nil},
+		"b": map[string]any{"c": false},
+	}
+	value := NewJsonValue(data)
+	assert.Equal(t, jsonValuesEqual(value.tree(), data), true, "Expected tree() to give back the data")
+
+	// The data is copied
+	data["b"] = 1
+	kind := value.Kind()
+	b, _ := value.GetObject("b")
+	assert.Equal(t, kind, KindObject, "Unexpected root kind")
+	assert.Equal(t, b.Kind(), KindObject, "Expected changes to the data not to change the value")
+
+	// Anything else is kept as is
+	invalid := NewJsonValue([]any{struct{}{}})
+	item, _ := invalid.Index(0)
+	assert.Equal(t, item.Kind(), KindInvalid, "Expected non-JSON item to be KindInvalid")
+	assert.Equal(t, item.tree(), any(struct{}{}), "Expected non-JSON item to be kept")
+
+	assert.Finished()
+}
+
+func TestTapeAccessorsDontAllocate(t *testing.T) {
+	value, _ := runParserWithStr(`{"pairs": [{"x0": 1.5, "y0": 2, "name": "a", "ok": true}]}`)
+	pairs, _ := value.GetArray("pairs")
+	pair := pairs[0]
+
+	allocs := testing.AllocsPerRun(100, func() {
+		pair.GetFloat("x0")
+		pair.GetInt("y0")
+		pair.GetString("name")
+		pair.GetBool("ok")
+		pair.Kind()
+		pair.Len()
+	})
+	assert.Equal(t, allocs, 0.0, "Expected scalar accessors not to allocate")
+
+	// 1 slice of values & 1 of pointers, however many items
+	allocs = testing.AllocsPerRun(100, func() {
+		value.GetArray("pairs")
+	})
+	assert.Equal(t, allocs, 2.0, "Expected GetArray() to allocate 2 slices")
+
+	// Comparing walks the tapes, rather than building Go values
+	other, _ := runParserWithStr(`{"pairs": [{"ok": true, "name": "a", "y0": 2.0, "x0": 1.5}]}`)
+	allocs = testing.AllocsPerRun(100, func() {
+		Equal(value, other)
+	})
+	assert.Equal(t, allocs, 0.0, "Expected Equal() not to allocate")
+	assert.Equal(t, Equal(value, other), true, "Expected values with keys in another order to be equal")
+
+	assert.Finished()
+}
+
+/*
+	Benchmarks for the tape, on a haversine pairs document like cmd/generateJson makes. The
+	"JsonValue Tape Results" in the readme come from these:
+
+	go test ./internal/jsonParser -run '^$' -bench . -benchmem
+*/
+
+const BENCHMARK_PAIRS = 200000
+
+var benchmarkPairsJson string
+
+// Returns {"pairs": [...]} with BENCHMARK_PAIRS pairs of coordinates, made once.
+func pairsBenchmarkJson() string {
+	if benchmarkPairsJson == "" {
+		var sb strings.Builder
+		sb.WriteString("{\"pairs\":[\n")
+		for i := 0; i < BENCHMARK_PAIRS; i++ {
+			if i > 0 {
+				sb.WriteString(",\n")
+			}
+			x := float64(i%36000)/100.0 - 180.0
+			y := float64(i%18000)/100.0 - 90.0
+			fmt.Fprintf(&sb, `    {"x0":%.16f, "y0":%.16f, "x1":%.16f, "y1":%.16f}`, x, y, -x/3.0, -y/7.0)
+		}
+		sb.WriteString("\n]}")
+		benchmarkPairsJson = sb.String()
+	}
+	return benchmarkPairsJson
+}
+
+func parsePairsBenchmark(b *testing.B) *JsonValue {
+	value, err := ParseJson(pairsBenchmarkJson())
+	if err != nil {
+		b.Fatal(err)
+	}
+	return value
+}
+
+func BenchmarkParsePairs(b *testing.B) {
+	input := pairsBenchmarkJson()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseJson(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetArray(b *testing.B) {
+	value := parsePairsBenchmark(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := value.GetArray("pairs"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWalkPairs(b *testing.B) {
+	value := parsePairsBenchmark(b)
+	pairs := MustGet[*JsonValue](value, "/pairs")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sum := 0.0
+		items := pairs.Items()
+		for items.Next() {
+			pair := items.Value()
+			x0, _ := pair.GetFloat("x0")
+			y0, _ := pair.GetFloat("y0")
+			x1, _ := pair.GetFloat("x1")
+			y1, _ := pair.GetFloat("y1")
+			sum += x0 + y0 + x1 + y1
+		}
+	}
+}
+
+func BenchmarkSerialize(b *testing.B) {
+	value := parsePairsBenchmark(b)
+	data, _ := value.Serialize()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := value.Serialize(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package jsonParser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
+
+/*
+	Valid() & Validate() check that data is a single valid JSON value, without lexing it into
+	tokens or building a JsonValue. It's a single pass state machine that doesn't allocate, so
+	it's much faster than a parse when all you need to know is whether an upload is valid.
+
+	```
+	if !jsonParser.Valid(upload) {
+		...
+	}
+
+	// Or, for an error positioned like ParseJson()'s
+	if err := jsonParser.Validate(upload); err != nil {
+		fmt.Println(err) // "Expected value, found "]" instead at line 1, column 5 (offset 4)"
+	}
+	```
+
+	Checks the RFC 8259 grammar with the default ParseOptions: strict mode, strings must be valid
+	UTF-8, & nesting depth is limited to DEFAULT_MAX_DEPTH. Numbers are only checked for syntax,
+	so ones too big for a float64 (like 1e400) are valid, like in number mode.
+*/
+
+// Returns true if data is a single valid JSON value.
+func Valid(data []byte) bool {
+	return Validate(data) == nil
+}
+
+// Returns nil if data is a single valid JSON value, or a *ParseError for the first problem.
+func Validate(data []byte) error {
+	// Scan as a string without copying, so the string helpers can be used. Nothing keeps it
+	// past this call: error messages copy what they quote.
+	v := validator{data: unsafe.String(unsafe.SliceData(data), len(data))}
+	offset, err := v.validate()
+	if err != nil {
+		return newParseError(v.data, offset, err)
+	}
+	return nil
+}
+
+type validator struct {
+	data  string
+	pos   int
+	depth int
+
+	// 1 bit per open container, set for objects & clear for arrays. A fixed size array, so
+	// tracking nesting doesn't allocate.
+	containers [DEFAULT_MAX_DEPTH/64 + 1]uint64
+}
+
+// Validates the data, returning the offset of the error if there is 1.
+func (v *validator) validate() (int, error) {
+	for {
+		// Expecting a value
+		v.skipWhitespace()
+		if v.pos >= len(v.data) {
+			return v.pos, errors.New("Expected value, found end of data instead")
+		}
+
+		switch c := v.data[v.pos]; {
+		case c == JSON_SYNTAX_LEFT_BRACE[0] || c == JSON_SYNTAX_LEFT_BRACKET[0]:
+			isObject := c == JSON_SYNTAX_LEFT_BRACE[0]
+			if err := v.enterContainer(isObject); err != nil {
+				return v.pos, err
+			}
+			v.pos += 1
+
+			// Empty container, or the first key. Otherwise back round for the first value.
+			v.skipWhitespace()
+			if v.pos < len(v.data) && (v.data[v.pos] == JSON_SYNTAX_RIGHT_BRACE[0] || v.data[v.pos] == JSON_SYNTAX_RIGHT_BRACKET[0]) {
+				if offset, err := v.exitContainer(); err != nil {
+					return offset, err
+				}
+			} else if isObject {
+				if offset, err := v.validateKey(); err != nil {
+					return offset, err
+				}
+				continue
+			} else {
+				continue
+			}
+		case c == JSON_SYNTAX_QUOTE[0]:
+			if offset, err := v.validateString(); err != nil {
+				return offset, err
+			}
+		case c == '-' || (c >= '0' && c <= '9'):
+			if err := v.validateNumber(); err != nil {
+				return v.pos, err
+			}
+		case strings.HasPrefix(v.data[v.pos:], JSON_SYNTAX_BOOL_TRUE):
+			v.pos += len(JSON_SYNTAX_BOOL_TRUE)
+		case strings.HasPrefix(v.data[v.pos:], JSON_SYNTAX_BOOL_FALSE):
+			v.pos += len(JSON_SYNTAX_BOOL_FALSE)
+		case strings.HasPrefix(v.data[v.pos:], JSON_SYNTAX_NULL):
+			v.pos += len(JSON_SYNTAX_NULL)
+		default:
+			return v.pos, v.unexpectedError("Expected value")
+		}
+
+		// After a value: close containers until there's another value to read, or the end
+		for {
+			v.skipWhitespace()
+			if v.depth == 0 {
+				if v.pos < len(v.data) {
+					r, _ := utf8.DecodeRuneInString(v.data[v.pos:])
+					return v.pos, errors.New(fmt.Sprintf(`Unexpected "%c" after end of JSON value`, r))
+				}
+				return 0, nil
+			}
+			if v.pos >= len(v.data) {
+				return v.pos, v.unexpectedError("Expected end of JSON container")
+			}
+
+			c := v.data[v.pos]
+			isObject := v.inObject()
+			if c == JSON_SYNTAX_COMMA[0] {
+				v.pos += 1
+				if isObject {
+					v.skipWhitespace()
+					if offset, err := v.validateKey(); err != nil {
+						return offset, err
+					}
+				}
+				break
+			}
+
+			if (isObject && c == JSON_SYNTAX_RIGHT_BRACE[0]) || (!isObject && c == JSON_SYNTAX_RIGHT_BRACKET[0]) {
+				if offset, err := v.exitContainer(); err != nil {
+					return offset, err
+				}
+				continue
+			}
+
+			if isObject {
+				return v.pos, v.unexpectedError(fmt.Sprintf(`Expected field separator "%s" or close object "%s"`, JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACE))
+			}
+			return v.pos, v.unexpectedError(fmt.Sprintf(`Expected item separator "%s" or close array "%s"`, JSON_SYNTAX_COMMA, JSON_SYNTAX_RIGHT_BRACKET))
+		}
+	}
+}
+
+func (v *validator) skipWhitespace() {
+	// Usually there's none, so check before calling out
+	if v.pos < len(v.data) && v.data[v.pos] > ' ' {
+		return
+	}
+	v.pos, _ = skipValueWhitespace(v.data, v.pos, false, true)
+}
+
+func (v *validator) enterContainer(isObject bool) error {
+	if v.depth >= DEFAULT_MAX_DEPTH {
+		return ErrMaxDepthExceeded
+	}
+	word, bit := v.depth/64, uint64(1)<<(v.depth%64)
+	if isObject {
+		v.containers[word] |= bit
+	} else {
+		v.containers[word] &^= bit
+	}
+	v.depth += 1
+	return nil
+}
+
+// Consumes the close brace or bracket at pos, which must match the open container.
+func (v *validator) exitContainer() (int, error) {
+	c := v.data[v.pos]
+	if v.inObject() != (c == JSON_SYNTAX_RIGHT_BRACE[0]) {
+		return v.pos, v.unexpectedError("Mismatched close of container")
+	}
+	v.depth -= 1
+	v.pos += 1
+	return 0, nil
+}
+
+func (v *validator) inObject() bool {
+	depth := v.depth - 1
+	return v.containers[depth/64]&(uint64(1)<<(depth%64)) != 0
+}
+
+// Validates a "key": at pos, leaving pos at the value.
+func (v *validator) validateKey() (int, error) {
+	if v.pos >= len(v.data) || v.data[v.pos] != JSON_SYNTAX_QUOTE[0] {
+		return v.pos, v.unexpectedError("Expected key string")
+	}
+	if offset, err := v.validateString(); err != nil {
+		return offset, err
+	}
+	v.skipWhitespace()
+	if v.pos >= len(v.data) || v.data[v.pos] != JSON_SYNTAX_COLON[0] {
+		return v.pos, v.unexpectedError(fmt.Sprintf(`Expected field assignment "%s"`, JSON_SYNTAX_COLON))
+	}
+	v.pos += 1
+	return 0, nil
+}
+
+// Validates the string starting with the quote at pos, leaving pos past its end quote.
+func (v *validator) validateString() (int, error) {
+	start := v.pos
+	i := v.pos + 1
+	for {
+		i = skipPlainStringBytes(v.data, i, JSON_SYNTAX_QUOTE[0])
+		if i >= len(v.data) {
+			return start, errors.New("End quote for string not found")
+		}
+
+		c := v.data[i]
+		switch {
+		case c == JSON_SYNTAX_QUOTE[0]:
+			v.pos = i + 1
+			return 0, nil
+		case c == '\\':
+			escapeLen, err := escapeLength(v.data[i:])
+			if err != nil {
+				return i, err
+			}
+			i += escapeLen
+		case c < 0x20:
+			return i, errors.New(fmt.Sprintf("Unescaped control character 0x%02x in string", c))
+		default:
+			r, size := utf8.DecodeRuneInString(v.data[i:])
+			if r == utf8.RuneError && size == 1 {
+				return i, ErrInvalidUTF8
+			}
+			i += size
+		}
+	}
+}
+
+// Returns the length of the escape sequence at the start of s (which starts with the backslash),
+// like writeEscape() without decoding it.
+func escapeLength(s string) (int, error) {
+	if len(s) < 2 {
+		return 0, errors.New("Unfinished escape sequence in string")
+	}
+	switch s[1] {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		return 2, nil
+	case 'u':
+		if _, ok := readHex4(s[2:]); !ok {
+			return 0, errors.New(`Invalid \u escape in string, expected 4 hex digits`)
+		}
+		// Lone surrogates are valid JSON, so pairs don't need checking
+		return 6, nil
+	}
+	return 0, errors.New(fmt.Sprintf(`Invalid escape "\%c" in string`, s[1]))
+}
+
+// Validates the number starting at pos, leaving pos past its end. Same grammar as
+// isValidJsonNumber(), but finds the end of the number as it goes, in 1 pass.
+func (v *validator) validateNumber() error {
+	data := v.data
+	start := v.pos
+	i := start
+
+	// Optional minus, then a single 0 or a non-zero digit followed by any digits
+	if data[i] == '-' {
+		i += 1
+	}
+	if i < len(data) && data[i] == '0' {
+		i += 1
+	} else if i < len(data) && data[i] >= '1' && data[i] <= '9' {
+		i = skipDigits(data, i+1)
+	} else {
+		return v.invalidNumberError(i)
+	}
+
+	// Fraction: "." followed by at least 1 digit
+	if i < len(data) && data[i] == '.' {
+		fractionStart := i + 1
+		i = skipDigits(data, fractionStart)
+		if i == fractionStart {
+			return v.invalidNumberError(i)
+		}
+	}
+
+	// Exponent: "e" or "E", optional sign, at least 1 digit
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i += 1
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i += 1
+		}
+		exponentStart := i
+		i = skipDigits(data, exponentStart)
+		if i == exponentStart {
+			return v.invalidNumberError(i)
+		}
+	}
+
+	// More number characters (like "01" or "1.2.3") make the whole literal invalid
+	if i < len(data) && isNumberChar(data[i]) {
+		return v.invalidNumberError(i)
+	}
+
+	v.pos = i
+	return nil
+}
+
+func skipDigits(data string, i int) int {
+	for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+		i += 1
+	}
+	return i
+}
+
+func isNumberChar(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
+
+// Returns an error quoting the invalid number at pos, which is known to be invalid by i.
+func (v *validator) invalidNumberError(i int) error {
+	end := i
+	for end < len(v.data) && isNumberChar(v.data[end]) {
+		end += 1
+	}
+	return errors.New(fmt.Sprintf(`Invalid number "%s"`, v.data[v.pos:end]))
+}
+
+// Returns an error saying what was found at pos instead of what was expected.
+func (v *validator) unexpectedError(expected string) error {
+	if v.pos >= len(v.data) {
+		return errors.New(expected + ", found end of data instead")
+	}
+	r, _ := utf8.DecodeRuneInString(v.data[v.pos:])
+	return errors.New(fmt.Sprintf(`%s, found "%c" instead`, expected, r))
+}
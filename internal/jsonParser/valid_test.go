@@ -0,0 +1,68 @@
+package jsonParser
+
+/*
+	Tests Valid() & Validate().
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"tmelot.jsonparser/internal/assert"
+)
+
+func TestValid(t *testing.T) {
+	valid := []string{
+		`{}`, `[]`, ` [ ] `, `0`, `-0.5e+3`, `"a"`, `true`, `false`, `null`, `1e400`,
+		`{"a": [1, {"b": null}], "c": "\"\\\/\b\f\n\r\té\ud800"}`,
+		"[\"\xe2\x82\xac\"]\n",
+		strings.Repeat("[", DEFAULT_MAX_DEPTH) + strings.Repeat("]", DEFAULT_MAX_DEPTH),
+	}
+	for _, s := range valid {
+		assert.Nil(t, Validate([]byte(s)), "Expected "+s+" to be valid")
+	}
+
+	invalid := []string{
+		``, ` `, `{`, `[`, `]`, `[}`, `{]`, `{"a"}`, `{"a":}`, `{"a" 1}`, `{1: 2}`, `{"a": 1,}`,
+		`[1,]`, `[1 2]`, `[,]`, `[] []`, `[012]`, `[1.]`, `[tru]`, `[truex]`, `[nul]`, `'a'`,
+		`["\x"]`, `["\u12"]`, "[\"\t\"]", `["a`, `["\`, "[\"\xff\"]",
+		strings.Repeat("[", DEFAULT_MAX_DEPTH+1) + strings.Repeat("]", DEFAULT_MAX_DEPTH+1),
+	}
+	for _, s := range invalid {
+		assert.Equal(t, Valid([]byte(s)), false, "Expected "+s+" to be invalid")
+	}
+}
+
+func TestValidMatchesParser(t *testing.T) {
+	for _, s := range FUZZ_SEEDS {
+		options := DefaultParseOptions()
+		options.UseNumber = true
+		_, err := ParseJsonWithOptions(s, options)
+		assert.Equal(t, Valid([]byte(s)), err == nil, "Expected Valid() to match the parser for "+s)
+		assert.Equal(t, Valid([]byte(s)), json.Valid([]byte(s)), "Expected Valid() to match encoding/json for "+s)
+	}
+}
+
+func TestValidateError(t *testing.T) {
+	err := Validate([]byte("{\n  \"a\": [1, ]\n}"))
+	var parseErr *ParseError
+	assert.Equal(t, errors.As(err, &parseErr), true, "Expected a ParseError")
+	assert.Equal(t, parseErr.Line, 2, "Expected error on line 2")
+	assert.Equal(t, parseErr.Column, 12, "Expected error at the close bracket")
+
+	err = Validate([]byte(strings.Repeat("[", DEFAULT_MAX_DEPTH+1)))
+	assert.Equal(t, errors.Is(err, ErrMaxDepthExceeded), true, "Expected max depth error")
+
+	err = Validate([]byte("[\"\xff\"]"))
+	assert.Equal(t, errors.Is(err, ErrInvalidUTF8), true, "Expected invalid UTF-8 error")
+}
+
+func TestValidDoesNotAllocate(t *testing.T) {
+	data := []byte(`{"pairs": [{"x0": 1.5, "y0": -2.25e1, "name": "café ☕"}, [true, false, null]]}`)
+	allocs := testing.AllocsPerRun(100, func() {
+		Valid(data)
+	})
+	assert.Equal(t, allocs, 0.0, "Expected Valid() not to allocate")
+}
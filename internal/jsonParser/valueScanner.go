@@ -0,0 +1,174 @@
+package jsonParser
+
+/*
+	Value scanner finds where a JSON value ends without lexing or parsing it. It only tracks
+	string quotes (so brackets inside strings are ignored) & bracket depth, so it's much cheaper
+	than a parse. It doesn't validate anything: it trusts the parser to find errors in the value
+	once its bounds are known.
+
+	Works on both strings & byte slices, so readers can scan their buffer without copying it.
+*/
+
+// Returns the index of the first byte at or after i that's not whitespace (or a comment, in
+// relaxed mode). complete is false if data ends in the middle of a comment, unless atEOF is true
+// & it's a line comment, which may end at the end of the input.
+func skipValueWhitespace[T ~string | ~[]byte](data T, i int, relaxed, atEOF bool) (next int, complete bool) {
+	for i < len(data) {
+		c := data[i]
+		if c == ' ' || c == '\n' || c == '\r' || c == '\t' {
+			i += 1
+			continue
+		}
+		if !relaxed {
+			return i, true
+		}
+
+		commentEnd, commentComplete := skipComment(data, i)
+		if !commentComplete {
+			isLineComment := i+1 < len(data) && data[i+1] == '/'
+			if atEOF && isLineComment {
+				return len(data), true
+			}
+			return i, false
+		}
+		if commentEnd > i {
+			i = commentEnd
+			continue
+		}
+		if wsLen := relaxedWhitespaceLen(data, i); wsLen > 0 {
+			i += wsLen
+			continue
+		}
+		return i, true
+	}
+	return i, true
+}
+
+// Returns the index just past the comment starting at i, or i if there isn't a comment there.
+// complete is false if data ends in the middle of the comment. Line comments run to the end of
+// the line, so they're only complete once the newline is found.
+func skipComment[T ~string | ~[]byte](data T, i int) (end int, complete bool) {
+	if i+1 >= len(data) {
+		// Could be the start of a comment
+		return i, i >= len(data) || data[i] != '/'
+	}
+	if data[i] != '/' {
+		return i, true
+	}
+
+	switch data[i+1] {
+	case '/':
+		for j := i + 2; j < len(data); j++ {
+			if data[j] == '\n' || data[j] == '\r' {
+				return j, true
+			}
+		}
+	case '*':
+		for j := i + 2; j+1 < len(data); j++ {
+			if data[j] == '*' && data[j+1] == '/' {
+				return j + 2, true
+			}
+		}
+	default:
+		return i, true
+	}
+	return len(data), false
+}
+
+// Returns the length of the JSON5 whitespace character at i, or 0 if there isn't one.
+func relaxedWhitespaceLen[T ~string | ~[]byte](data T, i int) int {
+	for _, ws := range JSON5_SYNTAX_WHITESPACE {
+		wsStr := string(ws)
+		if i+len(wsStr) <= len(data) && string(data[i:i+len(wsStr)]) == wsStr {
+			return len(wsStr)
+		}
+	}
+	return 0
+}
+
+// Returns the index just past the value starting at start, which must not be whitespace.
+// complete is false if data ends before the value does. A number or literal that runs to the
+// end of data is also incomplete, since more digits or letters could follow.
+//
+// Bytes that can't start a value (like "}" or ",") are returned as a 1 byte value, so parsing it
+// reports the error.
+func scanValueEnd[T ~string | ~[]byte](data T, start int, relaxed bool) (end int, complete bool) {
+	c := data[start]
+	switch {
+	case c == '{' || c == '[':
+		return scanContainerEnd(data, start, relaxed)
+	case c == '"' || (relaxed && c == '\''):
+		return scanStringEnd(data, start)
+	case c == '}' || c == ']' || c == ',' || c == ':':
+		return start + 1, true
+	}
+
+	// Number or literal, ends at the next delimiter
+	for i := start; i < len(data); i++ {
+		if isValueDelimiter(data[i], relaxed) {
+			return i, true
+		}
+	}
+	return len(data), false
+}
+
+// Returns true if c ends a number or literal.
+func isValueDelimiter(c byte, relaxed bool) bool {
+	switch c {
+	case ' ', '\n', '\r', '\t', '{', '}', '[', ']', ',', ':', '"':
+		return true
+	case '\'', '/':
+		return relaxed
+	}
+	return false
+}
+
+// Returns the index just past the quoted string starting at start.
+func scanStringEnd[T ~string | ~[]byte](data T, start int) (end int, complete bool) {
+	quote := data[start]
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			// Skip the escaped character, so an escaped quote doesn't end the string
+			i += 1
+		case quote:
+			return i + 1, true
+		}
+	}
+	return len(data), false
+}
+
+// Returns the index just past the object or array starting at start.
+func scanContainerEnd[T ~string | ~[]byte](data T, start int, relaxed bool) (end int, complete bool) {
+	depth := 0
+	for i := start; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '"' || (relaxed && c == '\''):
+			stringEnd, stringComplete := scanStringEnd(data, i)
+			if !stringComplete {
+				return len(data), false
+			}
+			i = stringEnd
+			continue
+		case relaxed && c == '/':
+			commentEnd, commentComplete := skipComment(data, i)
+			if !commentComplete {
+				return len(data), false
+			}
+			if commentEnd > i {
+				i = commentEnd
+				continue
+			}
+		case c == '{' || c == '[':
+			depth += 1
+		case c == '}' || c == ']':
+			depth -= 1
+			if depth == 0 {
+				return i + 1, true
+			}
+		}
+		i += 1
+	}
+	return len(data), false
+}
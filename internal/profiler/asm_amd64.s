@@ -1,3 +1,5 @@
+// +build darwin windows
+
 // Original assembly by David Terei: https://github.com/dterei/gotsc
 // I unified the begin/end calls into 1 single call.
 
@@ -1,3 +1,5 @@
+// +build darwin windows
+
 #include "textflag.h"
 
 // Empty package before · is current package.
@@ -8,6 +8,7 @@ package profiler
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
@@ -28,6 +29,13 @@ type Block struct {
 	parentName    string // Tracks parent block name to handle nested blocks.
 }
 
+// Holds running data for a named Counter: hits out of total attempts, like cache hits out of
+// lookups.
+type Counter struct {
+	hits  uint64
+	total uint64
+}
+
 // Special bucket used to profile the profiler.
 var PROFILER_BLOCK_NAME = "__Profiler"
 
@@ -40,6 +48,11 @@ type Profiler struct {
 	order  []string
 	// Remembers when the profiler started so we can compute total time.
 	startTSC uint64
+	// Maps counter names to counters, & remembers their ordering.
+	counters     map[string]Counter
+	counterOrder []string
+	// Guards blocks, order & counters for concurrent blocks & counters, which run on other goroutines.
+	mutex sync.Mutex
 }
 
 func newProfiler() *Profiler {
@@ -48,6 +61,7 @@ func newProfiler() *Profiler {
 		profilerBlock: &Block{},
 		order:         make([]string, 0, MAX_BLOCKS),
 		startTSC:      0,
+		counters:      map[string]Counter{},
 	}
 }
 
@@ -141,6 +155,56 @@ func (p *Profiler) EndBandwidth(name string) {
 	p.endBlock(name)
 }
 
+// Start a block measurement on a worker goroutine & track byte count. Returns the start time
+// stamp counter, which must be passed to EndConcurrentBlock().
+//
+// Concurrent blocks are timed on their own: they don't nest under (or subtract from) the block
+// that started the workers, so overlapping workers can add up to more than 100%. Give each
+// worker its own block name to see how evenly work was split. Don't start or end regular blocks
+// while workers are running, those aren't goroutine safe.
+func (p *Profiler) StartConcurrentBlock(name string, byteCount uint64) uint64 {
+	p.mutex.Lock()
+	block, ok := p.blocks[name]
+	if !ok {
+		if len(p.order) > MAX_BLOCKS {
+			msg := fmt.Sprintf("Number of blocks has exceeded maximum of %d", MAX_BLOCKS)
+			panic(msg)
+		}
+		p.order = append(p.order, name)
+	}
+	block.hitCount += 1
+	block.byteCount += byteCount
+	p.blocks[name] = block
+	p.mutex.Unlock()
+
+	return ReadCPUTimer()
+}
+
+// End a concurrent block started at startTSC.
+func (p *Profiler) EndConcurrentBlock(name string, startTSC uint64) {
+	duration := ReadCPUTimer() - startTSC
+
+	p.mutex.Lock()
+	block := p.blocks[name]
+	block.total += duration
+	p.blocks[name] = block
+	p.mutex.Unlock()
+}
+
+// Adds hits out of total attempts to the given counter, like cache hits out of lookups. Counters
+// are printed after blocks with their hit rate. Safe to call from any goroutine.
+func (p *Profiler) AddCounter(name string, hits, total uint64) {
+	p.mutex.Lock()
+	counter, ok := p.counters[name]
+	if !ok {
+		p.counterOrder = append(p.counterOrder, name)
+	}
+	counter.hits += hits
+	counter.total += total
+	p.counters[name] = counter
+	p.mutex.Unlock()
+}
+
 func GetPrinter() *message.Printer {
 	return message.NewPrinter(language.English) // For printing large numbers with commas
 }
@@ -167,6 +231,24 @@ func (p *Profiler) EndAndPrintProfile() {
 	fmt.Println(strings.Repeat("=", 60))
 	p.printBlockTimeElapsed(PROFILER_BLOCK_NAME[2:], totalCycles, cpuFreq, p.profilerBlock)
 	p.printBlockTimeElapsed("Total", totalCycles, cpuFreq, &Block{total: totalCycles})
+
+	// Print counters
+	if len(p.counterOrder) > 0 {
+		fmt.Println("\n[Counters]")
+		for _, counterName := range p.counterOrder {
+			p.printCounter(counterName, p.counters[counterName])
+		}
+	}
+}
+
+// Prints a counter's hits, total & hit rate.
+func (p *Profiler) printCounter(label string, counter Counter) {
+	printer := GetPrinter()
+	percent := 0.0
+	if counter.total > 0 {
+		percent = 100.0 * float64(counter.hits) / float64(counter.total)
+	}
+	printer.Printf("  %s: %d / %d hits (%.2f%%)\n", label, counter.hits, counter.total, percent)
 }
 
 // Returns len of longest block name string. Used for print formatting.
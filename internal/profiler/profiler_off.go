@@ -17,4 +17,7 @@ func (p *Profiler) StartBlock(name string) {}
 func (p *Profiler) EndBlock(name string) {}
 func (p *Profiler) StartBandwidth(name string, byteCount uint64) {}
 func (p *Profiler) EndBandwidth(name string) {}
+func (p *Profiler) StartConcurrentBlock(name string, byteCount uint64) uint64 { return 0 }
+func (p *Profiler) EndConcurrentBlock(name string, startTSC uint64) {}
 func (p *Profiler) EndAndPrintProfile() {}
+func (p *Profiler) AddCounter(name string, hits, total uint64) {}